@@ -0,0 +1,179 @@
+package wug
+
+import (
+	"sort"
+	"time"
+
+	"github.com/wirepair/wug/meteo"
+	"github.com/wirepair/wug/model"
+	"github.com/wirepair/wug/units"
+)
+
+// anemometerHeight is the standard height wind speeds are reported at
+var anemometerHeight = units.Meters(10)
+
+// windAt2m converts a reported wind speed to the 2 m height the
+// evapotranspiration calculations expect.
+func windAt2m(v units.Speed, ok bool) *units.Speed {
+	return optional(meteo.WindAt2m(v, anemometerHeight), ok)
+}
+
+// DailyWeather returns the forecast days that have both a high and a low
+// temperature for degree day and evapotranspiration calculations.
+func (f *ForecastData) DailyWeather() meteo.Days {
+	days := make(meteo.Days, 0, len(f.Simpleforecast.Forecastday))
+	for i := range f.Simpleforecast.Forecastday {
+		day := &f.Simpleforecast.Forecastday[i]
+		forecast := day.DailyForecast()
+		if forecast.High == nil || forecast.Low == nil {
+			continue
+		}
+
+		days = append(days, meteo.DailyWeather{
+			Date:        forecast.Date,
+			High:        *forecast.High,
+			Low:         *forecast.Low,
+			Humidity:    forecast.Humidity,
+			HumidityMax: forecast.MaxHumidity,
+			HumidityMin: forecast.MinHumidity,
+			Wind:        windAt2m(day.AveWindSpeed()),
+		})
+	}
+	return days
+}
+
+// DailyWeather returns the forecast days for degree day calculations.
+func (f *Forecast) DailyWeather() meteo.Days {
+	return f.Forecast.DailyWeather()
+}
+
+// DailyWeather returns the ten day forecast days for degree day calculations.
+func (f *ForecastTenDay) DailyWeather() meteo.Days {
+	return f.Forecast.DailyWeather()
+}
+
+// HourlyWeather returns the forecast hour for chill hour and
+// evapotranspiration calculations, ok is false without a temperature and
+// humidity.
+func (h *HourlyForecast) HourlyWeather() (meteo.HourlyWeather, bool) {
+	temp, ok := h.Temperature()
+	if !ok || !h.Humidity.Valid {
+		return meteo.HourlyWeather{}, false
+	}
+
+	t, _ := h.Fcttime.Time()
+	return meteo.HourlyWeather{
+		Time:        t,
+		Temperature: temp,
+		Humidity:    h.Humidity.Value,
+		Wind:        windAt2m(h.WindSpeed()),
+		CloudCover:  optionalFloat(h.Sky),
+	}, true
+}
+
+// hourlyWeather returns the hours that have a temperature and humidity.
+func hourlyWeather(forecasts []HourlyForecast) meteo.Hours {
+	hours := make(meteo.Hours, 0, len(forecasts))
+	for i := range forecasts {
+		if hour, ok := forecasts[i].HourlyWeather(); ok {
+			hours = append(hours, hour)
+		}
+	}
+	return hours
+}
+
+// HourlyWeather returns the forecast hours for chill hour calculations.
+func (h *Hourly) HourlyWeather() meteo.Hours {
+	return hourlyWeather(h.Hourly)
+}
+
+// HourlyWeather returns the ten day forecast hours for chill hour calculations.
+func (h *HourlyTenDay) HourlyWeather() meteo.Hours {
+	return hourlyWeather(h.Hourly)
+}
+
+// ObservedDays summarizes historical observations into days in the local
+// time of each observation. Observations without a temperature are ignored.
+// Means of the humidity, wind and solar radiation assume the observations
+// are spread evenly over the day.
+func ObservedDays(observations []model.Observation) meteo.Days {
+	type summary struct {
+		day                meteo.DailyWeather
+		humidity, min, max float64
+		humidityCount      int
+		wind               float64
+		windCount          int
+		solar              float64
+		solarCount         int
+	}
+
+	summaries := make(map[time.Time]*summary)
+	for i := range observations {
+		obs := &observations[i]
+		if obs.Temperature == nil {
+			continue
+		}
+
+		t := obs.Time
+		date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		s, ok := summaries[date]
+		if !ok {
+			s = &summary{day: meteo.DailyWeather{Date: date, High: *obs.Temperature, Low: *obs.Temperature}}
+			summaries[date] = s
+		}
+
+		if *obs.Temperature > s.day.High {
+			s.day.High = *obs.Temperature
+		}
+
+		if *obs.Temperature < s.day.Low {
+			s.day.Low = *obs.Temperature
+		}
+
+		if obs.Humidity != nil {
+			if s.humidityCount == 0 || *obs.Humidity < s.min {
+				s.min = *obs.Humidity
+			}
+
+			if s.humidityCount == 0 || *obs.Humidity > s.max {
+				s.max = *obs.Humidity
+			}
+			s.humidity += *obs.Humidity
+			s.humidityCount++
+		}
+
+		if obs.WindSpeed != nil {
+			s.wind += obs.WindSpeed.MetersPerSecond()
+			s.windCount++
+		}
+
+		if obs.SolarRadiation != nil {
+			s.solar += *obs.SolarRadiation
+			s.solarCount++
+		}
+	}
+
+	days := make(meteo.Days, 0, len(summaries))
+	for _, s := range summaries {
+		if s.humidityCount > 0 {
+			mean := s.humidity / float64(s.humidityCount)
+			s.day.Humidity, s.day.HumidityMin, s.day.HumidityMax = &mean, &s.min, &s.max
+		}
+
+		if s.windCount > 0 {
+			s.day.Wind = windAt2m(units.MetersPerSecond(s.wind/float64(s.windCount)), true)
+		}
+
+		if s.solarCount > 0 {
+			// mean W/m² over the day into MJ/m²
+			radiation := s.solar / float64(s.solarCount) * 0.0864
+			s.day.SolarRadiation = &radiation
+		}
+		days = append(days, s.day)
+	}
+
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Date.Before(days[j].Date)
+	})
+	return days
+}
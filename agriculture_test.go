@@ -0,0 +1,93 @@
+package wug
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/wirepair/wug/model"
+	"github.com/wirepair/wug/units"
+)
+
+func TestForecastDailyWeather(t *testing.T) {
+	data := []byte(`{"forecast": {"simpleforecast": {"forecastday": [
+		{"high": {"fahrenheit": "50", "celsius": "10"}, "low": {"fahrenheit": "30", "celsius": "-1"}, "avehumidity": 70, "maxhumidity": 90, "minhumidity": 50, "avewind": {"mph": 10, "kph": 16}},
+		{"high": {"fahrenheit": "", "celsius": ""}, "low": {"fahrenheit": "40", "celsius": "4"}},
+		{"high": {"fahrenheit": "90", "celsius": "32"}, "low": {"fahrenheit": "70", "celsius": "21"}}]}}}`)
+	f := &ForecastTenDay{}
+	if err := json.Unmarshal(data, f); err != nil {
+		t.Fatalf("error decoding forecast: %s\n", err)
+	}
+
+	days := f.DailyWeather()
+	if len(days) != 2 {
+		t.Fatalf("expected 2 days with a high and low got %d\n", len(days))
+	}
+
+	if *days[0].Humidity != 70 || *days[0].HumidityMax != 90 || days[0].Wind == nil || days[1].Wind != nil {
+		t.Fatalf("unexpected day %#v\n", days[0])
+	}
+
+	// 65°F base: 64.4 - 4.5 = 59.9 heating and 26.5 - 18.3 = 8.2 cooling °C days
+	base := units.Fahrenheit(65)
+	if hdd := days.HeatingDegreeDays(base); math.Abs(hdd.Celsius()-(base.Celsius()-4.5)) > 1e-9 {
+		t.Fatalf("unexpected heating degree days %v\n", hdd.Celsius())
+	}
+
+	if cdd := days.CoolingDegreeDays(base); math.Abs(cdd.Celsius()-(26.5-base.Celsius())) > 1e-9 {
+		t.Fatalf("unexpected cooling degree days %v\n", cdd.Celsius())
+	}
+}
+
+func TestHourlyWeather(t *testing.T) {
+	data := []byte(`{"hourly_forecast": [
+		{"FCTTIME": {"epoch": "1493182800"}, "temp": {"english": "40", "metric": "4"}, "humidity": "80", "sky": "100", "wspd": {"english": "5", "metric": "8"}},
+		{"FCTTIME": {"epoch": "1493186400"}, "temp": {"english": "50", "metric": "10"}, "humidity": "70"},
+		{"FCTTIME": {"epoch": "1493190000"}, "temp": {"english": "", "metric": ""}, "humidity": "70"}]}`)
+	h := &HourlyTenDay{}
+	if err := json.Unmarshal(data, h); err != nil {
+		t.Fatalf("error decoding hourly: %s\n", err)
+	}
+
+	hours := h.HourlyWeather()
+	if len(hours) != 2 {
+		t.Fatalf("expected 2 hours got %d\n", len(hours))
+	}
+
+	if hours.ChillHours() != 1 {
+		t.Fatalf("expected 1 chill hour got %d\n", hours.ChillHours())
+	}
+
+	if hours[0].CloudCover == nil || *hours[0].CloudCover != 100 || hours[1].CloudCover != nil {
+		t.Fatalf("unexpected cloud cover %#v\n", hours)
+	}
+}
+
+func TestObservedDays(t *testing.T) {
+	loc := time.FixedZone("PDT", -7*3600)
+	observation := func(hour int, temp, humidity float64) model.Observation {
+		c := units.Celsius(temp)
+		return model.Observation{Time: time.Date(2017, 4, 25, hour, 0, 0, 0, loc), Temperature: &c, Humidity: &humidity}
+	}
+
+	days := ObservedDays([]model.Observation{
+		observation(23, 12, 90),
+		observation(6, 8, 95),
+		observation(15, 20, 40),
+		{Time: time.Date(2017, 4, 26, 1, 0, 0, 0, loc)},
+		observation(25, 10, 85),
+	})
+
+	if len(days) != 2 {
+		t.Fatalf("expected 2 days got %d\n", len(days))
+	}
+
+	if days[0].High.Celsius() != 20 || days[0].Low.Celsius() != 8 || *days[0].HumidityMin != 40 || *days[0].HumidityMax != 95 || *days[0].Humidity != 75 {
+		t.Fatalf("unexpected first day %#v\n", days[0])
+	}
+
+	if days[1].Date.Day() != 26 || days[1].High.Celsius() != 10 || days[1].Wind != nil {
+		t.Fatalf("unexpected second day %#v\n", days[1])
+	}
+}
@@ -0,0 +1,57 @@
+package wug
+
+import (
+	"time"
+)
+
+// AlertInfo is a severe weather alert
+type AlertInfo struct {
+	Type         string `json:"type"` // phenomena code, e.g. WIN, FLO
+	Description  string `json:"description"`
+	Date         string `json:"date"`
+	DateEpoch    string `json:"date_epoch"`
+	Expires      string `json:"expires"`
+	ExpiresEpoch string `json:"expires_epoch"`
+	TzShort      string `json:"tz_short"`
+	TzLong       string `json:"tz_long"`
+	Message      string `json:"message"`
+	Phenomena    string `json:"phenomena"`
+	Significance string `json:"significance"` // W warning, A watch, Y advisory, S statement
+}
+
+// Alerts active severe weather alerts
+type Alerts struct {
+	DecodeResult
+	Response struct {
+		Version        string `json:"version"`
+		TermsofService string `json:"termsofService"`
+		Features       struct {
+			Alerts int `json:"alerts"`
+		} `json:"features"`
+	} `json:"response"`
+	QueryZone string      `json:"query_zone"`
+	Alerts    []AlertInfo `json:"alerts"`
+}
+
+// alertTime returns the epoch in the zone of the alert.
+func (a *AlertInfo) alertTime(epoch string) (time.Time, error) {
+	t, err := parseEpoch(epoch)
+	if err != nil {
+		return t, err
+	}
+
+	if loc, ok := loadLocation(a.TzLong); ok {
+		return t.In(loc), nil
+	}
+	return t, nil
+}
+
+// Issued returns when the alert was issued.
+func (a *AlertInfo) Issued() (time.Time, error) {
+	return a.alertTime(a.DateEpoch)
+}
+
+// Expiry returns when the alert expires.
+func (a *AlertInfo) Expiry() (time.Time, error) {
+	return a.alertTime(a.ExpiresEpoch)
+}
@@ -0,0 +1,29 @@
+package wug
+
+import (
+	"encoding/json"
+	"testing"
+	_ "time/tzdata"
+)
+
+func TestAlerts(t *testing.T) {
+	data := []byte(`{"query_zone": "006", "alerts": [{"type": "WIN", "description": "Winter Weather Advisory", "date": "3:58 PM CST on January 4, 2017", "date_epoch": "1483567080", "expires": "6:00 AM CST on January 5, 2017", "expires_epoch": "1483617600", "tz_short": "CST", "tz_long": "America/Chicago", "message": "...snow...", "phenomena": "WW", "significance": "Y"}]}`)
+	a := &Alerts{}
+	if err := json.Unmarshal(data, a); err != nil {
+		t.Fatalf("error decoding alerts: %s\n", err)
+	}
+
+	if len(a.Alerts) != 1 {
+		t.Fatalf("expected 1 alert got %d\n", len(a.Alerts))
+	}
+
+	issued, err := a.Alerts[0].Issued()
+	if err != nil || issued.Hour() != 15 || issued.Minute() != 58 || issued.Location().String() != "America/Chicago" {
+		t.Fatalf("unexpected issue time %s %v\n", issued, err)
+	}
+
+	expires, err := a.Alerts[0].Expiry()
+	if err != nil || expires.Hour() != 6 {
+		t.Fatalf("unexpected expiry %s %v\n", expires, err)
+	}
+}
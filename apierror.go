@@ -0,0 +1,67 @@
+package wug
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// APIError is an error response of the API, which weather underground
+// answers with a 200 status code
+type APIError struct {
+	Type        string `json:"type"` // e.g. keynotfound, querynotfound
+	Description string `json:"description"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("wug: %s: %s", e.Type, e.Description)
+}
+
+// StatusError is a response with a status code other than 200
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return "wug: unexpected response status " + e.Status
+}
+
+// checkResponse returns responses with a status code other than 200 as a
+// *StatusError and error responses of the API as an *APIError. Only the
+// leading response object of the body is read to look for the error, the
+// returned reader puts it back in front of the rest of the body.
+func checkResponse(resp *http.Response, body io.Reader) (io.Reader, error) {
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+
+	var peeked bytes.Buffer
+	if apiErr := peekError(json.NewDecoder(io.TeeReader(body, &peeked))); apiErr != nil {
+		return nil, apiErr
+	}
+	return io.MultiReader(&peeked, body), nil
+}
+
+// peekError decodes the error of the response object, which the API sends
+// first. It returns nil when the body does not start with one.
+func peekError(dec *json.Decoder) *APIError {
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil
+	}
+
+	if t, err := dec.Token(); err != nil || t != "response" {
+		return nil
+	}
+
+	var response struct {
+		Error *APIError `json:"error"`
+	}
+
+	if dec.Decode(&response) != nil {
+		return nil
+	}
+	return response.Error
+}
@@ -0,0 +1,67 @@
+package wug
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// newResponseWug returns a Wug whose transport answers every request with the
+// status code and body.
+func newResponseWug(status int, body string) *Wug {
+	w := NewWug()
+	w.Client = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: status,
+			Status:     http.StatusText(status),
+			Body:       ioutil.NopCloser(strings.NewReader(body)),
+			Request:    r,
+		}, nil
+	})}
+	return w
+}
+
+func TestAPIErrors(t *testing.T) {
+	q := NewQueryByUsZip("apikey", "94101")
+	w := newResponseWug(http.StatusOK, `{"response": {"version": "0.1"}, "current_observation": {"weather": "Clear"}}`)
+	c, err := w.GetConditions(q)
+	if err != nil || c.CurrentObservation.Weather != "Clear" {
+		t.Fatalf("expected conditions got %v\n", err)
+	}
+
+	var apiErr *APIError
+	w = newResponseWug(http.StatusOK, `{"response": {"error": {"type": "keynotfound", "description": "this key does not exist"}}}`)
+	if _, err := w.GetConditions(q); !errors.As(err, &apiErr) || apiErr.Type != "keynotfound" {
+		t.Fatalf("expected a keynotfound APIError got %v\n", err)
+	}
+
+	if _, err := w.GetRawConditions(q); !errors.As(err, &apiErr) {
+		t.Fatalf("expected raw getters to return the APIError got %v\n", err)
+	}
+
+	var statusErr *StatusError
+	w = newResponseWug(http.StatusServiceUnavailable, "unavailable")
+	if _, err := w.GetConditions(q); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected a 503 StatusError got %v\n", err)
+	}
+}
+
+func TestAPIErrorsResponseSize(t *testing.T) {
+	q := NewQueryByUsZip("apikey", "94101")
+	padding := strings.Repeat("x", 4096)
+	w := newResponseWug(http.StatusOK, `{"response": {"version": "0.1"}, "padding": "`+padding+`"}`)
+	w.MaxResponseSize = 1024
+	if _, err := w.GetRawConditions(q); !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("expected ErrResponseTooLarge got %v\n", err)
+	}
+
+	// the error is found without reading the rest of the body
+	var apiErr *APIError
+	w = newResponseWug(http.StatusOK, `{"response": {"error": {"type": "querynotfound", "description": "no cities match"}}, "padding": "`+padding+`"}`)
+	w.MaxResponseSize = 1024
+	if _, err := w.GetConditions(q); !errors.As(err, &apiErr) || apiErr.Type != "querynotfound" {
+		t.Fatalf("expected a querynotfound APIError got %v\n", err)
+	}
+}
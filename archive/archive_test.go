@@ -0,0 +1,39 @@
+package archive
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wirepair/wug"
+	"github.com/wirepair/wug/wugtest"
+)
+
+var testNow = time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+
+// testData fetches conditions, hourly and forecast responses from a test server
+func testData(t *testing.T) (*wug.Conditions, *wug.Hourly, *wug.Forecast) {
+	s := wugtest.NewServer()
+	t.Cleanup(s.Close)
+	s.SetKey("testkey")
+	s.SetClock(func() time.Time { return testNow })
+	s.SetLocation("94101", wugtest.DefaultLocation)
+
+	w := s.Wug()
+	query := wug.NewQueryByUsZip("testkey", "94101")
+
+	conditions, err := w.GetConditions(query)
+	if err != nil {
+		t.Fatalf("error getting conditions: %s\n", err)
+	}
+
+	hourly, err := w.GetHourly(query)
+	if err != nil {
+		t.Fatalf("error getting hourly: %s\n", err)
+	}
+
+	forecast, err := w.GetForecast(query)
+	if err != nil {
+		t.Fatalf("error getting forecast: %s\n", err)
+	}
+	return conditions, hourly, forecast
+}
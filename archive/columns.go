@@ -0,0 +1,276 @@
+// Package archive encodes observations and forecasts for archiving, as
+// InfluxDB line protocol and as CSV. Encoders write every point or row as it
+// is given, nothing is buffered across writes.
+//
+// Both encoders share the layouts below. Measured columns are suffixed with
+// the unit of the selected unit system, e.g. temperature_c or temperature_f,
+// and are left empty (CSV) or out (line protocol) when missing. Integer
+// columns are rounded.
+//
+// Conditions (measurement wug_conditions):
+//
+//	time, station_id, location, condition (string), temperature, dewpoint,
+//	feels_like, heat_index, windchill, humidity_pct (integer),
+//	wind_speed, wind_gust, wind_direction_deg (integer), pressure,
+//	pressure_trend (string), visibility, uv_index, solar_radiation_wm2,
+//	precip_1hr, precip_today
+//
+// Hourly forecasts (measurement wug_hourly):
+//
+//	time, station_id, location, condition (string), temperature, dewpoint,
+//	feels_like, heat_index, windchill, humidity_pct (integer), wind_speed,
+//	wind_direction_deg (integer), pressure, cloud_cover_pct (integer),
+//	uv_index, pop_pct (integer), precip, snow
+//
+// Forecast days (measurement wug_daily):
+//
+//	time, station_id, location, condition (string), high, low,
+//	pop_pct (integer), precip, snow, max_wind, max_wind_direction_deg
+//	(integer), ave_wind, ave_wind_direction_deg (integer), humidity_pct
+//	(integer), min_humidity_pct (integer), max_humidity_pct (integer)
+//
+// time is the observation time, the start of the forecast hour or midnight
+// of the forecast day. station_id and location are tags in line protocol.
+package archive
+
+import (
+	"math"
+	"time"
+
+	"github.com/wirepair/wug/model"
+	"github.com/wirepair/wug/units"
+)
+
+// Kind of data a layout encodes
+type Kind int
+
+// Kind constants
+const (
+	KindConditions Kind = iota // current conditions
+	KindHourly                 // hourly forecasts
+	KindDaily                  // forecast days
+)
+
+var kindMap = map[Kind]string{
+	KindConditions: "wug_conditions",
+	KindHourly:     "wug_hourly",
+	KindDaily:      "wug_daily",
+}
+
+// String returns the measurement name of the kind.
+func (k Kind) String() string {
+	return kindMap[k]
+}
+
+// Tags identify the location of written data. Hourly forecasts and forecast
+// days do not carry one, conditions use the station and location of the
+// observation when present.
+type Tags struct {
+	StationID string
+	Location  string
+}
+
+// unitSuffixes are the column suffixes of units
+var unitSuffixes = map[string]string{
+	"°C":   "c",
+	"°F":   "f",
+	"km/h": "kph",
+	"mph":  "mph",
+	"m/s":  "ms",
+	"hPa":  "hpa",
+	"inHg": "inhg",
+	"km":   "km",
+	"mi":   "mi",
+	"mm":   "mm",
+	"in":   "in",
+}
+
+// column of a layout. value returns a float64, int64 or string, ok is false
+// when it is missing.
+type column[T any] struct {
+	name   string
+	suffix func(system units.System) string
+	value  func(x *T, system units.System) (v interface{}, ok bool)
+}
+
+// header returns the name of the column in the unit system.
+func (c column[T]) header(system units.System) string {
+	if suffix := c.suffix(system); suffix != "" {
+		return c.name + "_" + suffix
+	}
+	return c.name
+}
+
+func fixed(suffix string) func(units.System) string {
+	return func(units.System) string { return suffix }
+}
+
+// measure is a column of a measurement converted to the unit system, rounded
+// to four decimals.
+func measure[T any, M units.Measure](name string, get func(x *T) *M) column[T] {
+	return column[T]{
+		name: name,
+		suffix: func(system units.System) string {
+			var zero M
+			_, unit := zero.In(system)
+			return unitSuffixes[unit]
+		},
+		value: func(x *T, system units.System) (interface{}, bool) {
+			m := get(x)
+			if m == nil {
+				return nil, false
+			}
+			v, _ := (*m).In(system)
+			return math.Round(v*1e4) / 1e4, true // drops conversion noise
+		},
+	}
+}
+
+// integer is a column of a rounded number.
+func integer[T any](name, suffix string, get func(x *T) *float64) column[T] {
+	return column[T]{name: name, suffix: fixed(suffix), value: func(x *T, _ units.System) (interface{}, bool) {
+		v := get(x)
+		if v == nil {
+			return nil, false
+		}
+		return int64(math.Round(*v)), true
+	}}
+}
+
+// number is a column of a number.
+func number[T any](name, suffix string, get func(x *T) *float64) column[T] {
+	return column[T]{name: name, suffix: fixed(suffix), value: func(x *T, _ units.System) (interface{}, bool) {
+		v := get(x)
+		if v == nil {
+			return nil, false
+		}
+		return *v, true
+	}}
+}
+
+// text is a column of a string, empty strings are missing.
+func text[T any](name string, get func(x *T) string) column[T] {
+	return column[T]{name: name, suffix: fixed(""), value: func(x *T, _ units.System) (interface{}, bool) {
+		v := get(x)
+		return v, v != ""
+	}}
+}
+
+// layout of a kind of data
+type layout[T any] struct {
+	kind    Kind
+	time    func(x *T) time.Time
+	tags    func(x *T) Tags // tags carried by the data, nil if none
+	columns []column[T]
+}
+
+// headers returns the column names in the unit system, starting with time
+// and the tags.
+func (l layout[T]) headers(system units.System) []string {
+	headers := []string{"time", "station_id", "location"}
+	for _, c := range l.columns {
+		headers = append(headers, c.header(system))
+	}
+	return headers
+}
+
+// tagsOf returns the tags of the data, falling back to the given tags.
+func (l layout[T]) tagsOf(x *T, tags Tags) Tags {
+	if l.tags == nil {
+		return tags
+	}
+
+	own := l.tags(x)
+	if own.StationID == "" {
+		own.StationID = tags.StationID
+	}
+
+	if own.Location == "" {
+		own.Location = tags.Location
+	}
+	return own
+}
+
+type obs = model.Observation
+
+var observationLayout = layout[obs]{
+	kind: KindConditions,
+	time: func(o *obs) time.Time { return o.Time },
+	tags: func(o *obs) Tags { return Tags{StationID: o.Location.StationID, Location: o.Location.Name} },
+	columns: []column[obs]{
+		text("condition", func(o *obs) string { return o.Condition }),
+		measure("temperature", func(o *obs) *units.Temperature { return o.Temperature }),
+		measure("dewpoint", func(o *obs) *units.Temperature { return o.Dewpoint }),
+		measure("feels_like", func(o *obs) *units.Temperature { return o.FeelsLike }),
+		measure("heat_index", func(o *obs) *units.Temperature { return o.HeatIndex }),
+		measure("windchill", func(o *obs) *units.Temperature { return o.Windchill }),
+		integer("humidity", "pct", func(o *obs) *float64 { return o.Humidity }),
+		measure("wind_speed", func(o *obs) *units.Speed { return o.WindSpeed }),
+		measure("wind_gust", func(o *obs) *units.Speed { return o.WindGust }),
+		integer("wind_direction", "deg", func(o *obs) *float64 { return o.WindDirection }),
+		measure("pressure", func(o *obs) *units.Pressure { return o.Pressure }),
+		text("pressure_trend", func(o *obs) string { return o.PressureTrend }),
+		measure("visibility", func(o *obs) *units.Length { return o.Visibility }),
+		number("uv_index", "", func(o *obs) *float64 { return o.UVIndex }),
+		number("solar_radiation", "wm2", func(o *obs) *float64 { return o.SolarRadiation }),
+		measure("precip_1hr", func(o *obs) *units.Precipitation { return o.Precip1Hr }),
+		measure("precip_today", func(o *obs) *units.Precipitation { return o.PrecipToday }),
+	},
+}
+
+type hour = model.HourlyPoint
+
+var hourlyLayout = layout[hour]{
+	kind: KindHourly,
+	time: func(h *hour) time.Time { return h.Time },
+	columns: []column[hour]{
+		text("condition", func(h *hour) string { return h.Condition }),
+		measure("temperature", func(h *hour) *units.Temperature { return h.Temperature }),
+		measure("dewpoint", func(h *hour) *units.Temperature { return h.Dewpoint }),
+		measure("feels_like", func(h *hour) *units.Temperature { return h.FeelsLike }),
+		measure("heat_index", func(h *hour) *units.Temperature { return h.HeatIndex }),
+		measure("windchill", func(h *hour) *units.Temperature { return h.Windchill }),
+		integer("humidity", "pct", func(h *hour) *float64 { return h.Humidity }),
+		measure("wind_speed", func(h *hour) *units.Speed { return h.WindSpeed }),
+		integer("wind_direction", "deg", func(h *hour) *float64 { return h.WindDirection }),
+		measure("pressure", func(h *hour) *units.Pressure { return h.Pressure }),
+		integer("cloud_cover", "pct", func(h *hour) *float64 { return h.CloudCover }),
+		number("uv_index", "", func(h *hour) *float64 { return h.UVIndex }),
+		integer("pop", "pct", func(h *hour) *float64 { return h.PrecipProbability }),
+		measure("precip", func(h *hour) *units.Precipitation { return h.Precip }),
+		measure("snow", func(h *hour) *units.Precipitation { return h.Snow }),
+	},
+}
+
+type day = model.DailyForecast
+
+var dailyLayout = layout[day]{
+	kind: KindDaily,
+	time: func(d *day) time.Time { return d.Date },
+	columns: []column[day]{
+		text("condition", func(d *day) string { return d.Condition }),
+		measure("high", func(d *day) *units.Temperature { return d.High }),
+		measure("low", func(d *day) *units.Temperature { return d.Low }),
+		integer("pop", "pct", func(d *day) *float64 { return d.PrecipProbability }),
+		measure("precip", func(d *day) *units.Precipitation { return d.Precip }),
+		measure("snow", func(d *day) *units.Precipitation { return d.Snow }),
+		measure("max_wind", func(d *day) *units.Speed { return d.MaxWind }),
+		integer("max_wind_direction", "deg", func(d *day) *float64 { return d.MaxWindDirection }),
+		measure("ave_wind", func(d *day) *units.Speed { return d.AveWind }),
+		integer("ave_wind_direction", "deg", func(d *day) *float64 { return d.AveWindDirection }),
+		integer("humidity", "pct", func(d *day) *float64 { return d.Humidity }),
+		integer("min_humidity", "pct", func(d *day) *float64 { return d.MinHumidity }),
+		integer("max_humidity", "pct", func(d *day) *float64 { return d.MaxHumidity }),
+	},
+}
+
+// Columns returns the CSV header of a kind in the unit system.
+func Columns(kind Kind, system units.System) []string {
+	switch kind {
+	case KindHourly:
+		return hourlyLayout.headers(system)
+	case KindDaily:
+		return dailyLayout.headers(system)
+	}
+	return observationLayout.headers(system)
+}
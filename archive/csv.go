@@ -0,0 +1,100 @@
+package archive
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/wirepair/wug"
+	"github.com/wirepair/wug/units"
+)
+
+// ErrKind is returned when data of another kind is written to a CSVWriter.
+var ErrKind = errors.New("archive: data of another kind than the csv header")
+
+// CSVWriter writes data of one kind as CSV rows below a header of its
+// columns, see Columns. Each row is flushed as it is written.
+type CSVWriter struct {
+	Kind   Kind
+	System units.System // units of the columns
+	Tags   Tags         // tags of data that does not carry its own
+
+	w      *csv.Writer
+	header bool // the header was written
+}
+
+// NewCSVWriter returns a CSVWriter of a kind writing to w.
+func NewCSVWriter(w io.Writer, kind Kind, system units.System, tags Tags) *CSVWriter {
+	return &CSVWriter{Kind: kind, System: system, Tags: tags, w: csv.NewWriter(w)}
+}
+
+// WriteHeader writes the header, it is written before the first row if not
+// called.
+func (cw *CSVWriter) WriteHeader() error {
+	if cw.header {
+		return nil
+	}
+	cw.header = true
+	return cw.write(Columns(cw.Kind, cw.System))
+}
+
+// WriteConditions writes the observation of the conditions.
+func (cw *CSVWriter) WriteConditions(c *wug.Conditions) error {
+	o := c.Observation()
+	return writeRecord(cw, observationLayout, &o)
+}
+
+// WriteHourly writes an hourly forecast.
+func (cw *CSVWriter) WriteHourly(h *wug.HourlyForecast) error {
+	p := h.HourlyPoint()
+	return writeRecord(cw, hourlyLayout, &p)
+}
+
+// WriteForecastDay writes a forecast day.
+func (cw *CSVWriter) WriteForecastDay(f *wug.ForecastDay) error {
+	d := f.DailyForecast()
+	return writeRecord(cw, dailyLayout, &d)
+}
+
+func (cw *CSVWriter) write(record []string) error {
+	cw.w.Write(record)
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// writeRecord writes x as a row of the layout, times are RFC 3339 in the zone
+// of the data.
+func writeRecord[T any](cw *CSVWriter, l layout[T], x *T) error {
+	if l.kind != cw.Kind {
+		return ErrKind
+	}
+
+	if err := cw.WriteHeader(); err != nil {
+		return err
+	}
+
+	record := make([]string, 0, len(l.columns)+3)
+	t := ""
+	if at := l.time(x); !at.IsZero() {
+		t = at.Format(time.RFC3339)
+	}
+
+	tags := l.tagsOf(x, cw.Tags)
+	record = append(record, t, tags.StationID, tags.Location)
+	for _, c := range l.columns {
+		v, _ := c.value(x, cw.System)
+		switch v := v.(type) {
+		case float64:
+			record = append(record, strconv.FormatFloat(v, 'f', -1, 64))
+		case int64:
+			record = append(record, strconv.FormatInt(v, 10))
+		case string:
+			record = append(record, v)
+		default:
+			record = append(record, "")
+		}
+	}
+	return cw.write(record)
+}
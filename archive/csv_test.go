@@ -0,0 +1,126 @@
+package archive
+
+import (
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wirepair/wug"
+	"github.com/wirepair/wug/units"
+)
+
+func readCSV(t *testing.T, s string) [][]string {
+	records, err := csv.NewReader(strings.NewReader(s)).ReadAll()
+	if err != nil {
+		t.Fatalf("error reading csv: %s\n", err)
+	}
+	return records
+}
+
+func TestCSVHourly(t *testing.T) {
+	_, hourly, _ := testData(t)
+
+	var b strings.Builder
+	cw := NewCSVWriter(&b, KindHourly, units.Metric, Tags{StationID: "KCASANFR70", Location: "Home"})
+	for i := range hourly.Hourly {
+		if err := cw.WriteHourly(&hourly.Hourly[i]); err != nil {
+			t.Fatalf("error writing hour: %s\n", err)
+		}
+
+		// rows are written as they are given
+		if records := readCSV(t, b.String()); len(records) != i+2 {
+			t.Fatalf("expected %d records after writing hour %d got %d\n", i+2, i, len(records))
+		}
+	}
+
+	records := readCSV(t, b.String())
+	header := records[0]
+	if !reflect.DeepEqual(header, Columns(KindHourly, units.Metric)) {
+		t.Fatalf("expected the header to be the columns got %v\n", header)
+	}
+
+	row := map[string]string{}
+	for i, column := range header {
+		row[column] = records[1][i]
+	}
+
+	start, err := time.Parse(time.RFC3339, row["time"])
+	if err != nil {
+		t.Fatalf("error parsing time: %s\n", err)
+	}
+
+	if want := hourly.Hourly[0].HourlyPoint().Time; !start.Equal(want) {
+		t.Fatalf("expected time %s got %s\n", want, start)
+	}
+
+	if row["station_id"] != "KCASANFR70" || row["location"] != "Home" {
+		t.Fatalf("expected the writer tags got %v\n", row)
+	}
+
+	if row["temperature_c"] == "" || row["condition"] == "" || row["pop_pct"] == "" {
+		t.Fatalf("expected values got %v\n", row)
+	}
+
+	if row["heat_index_c"] != "" || row["windchill_c"] != "" {
+		t.Fatalf("expected missing values to be empty got %v\n", row)
+	}
+}
+
+func TestCSVConditions(t *testing.T) {
+	conditions, _, _ := testData(t)
+
+	var b strings.Builder
+	cw := NewCSVWriter(&b, KindConditions, units.Imperial, Tags{})
+	if err := cw.WriteConditions(conditions); err != nil {
+		t.Fatalf("error writing conditions: %s\n", err)
+	}
+
+	records := readCSV(t, b.String())
+	if len(records) != 2 || len(records[1]) != len(records[0]) {
+		t.Fatalf("expected a header and a row got %v\n", records)
+	}
+
+	if records[0][4] != "temperature_f" || records[1][1] != "WUGTEST" || records[1][2] != "San Francisco, CA" {
+		t.Fatalf("expected an imperial row of the observation got %v\n", records)
+	}
+}
+
+func TestCSVKind(t *testing.T) {
+	_, hourly, forecast := testData(t)
+
+	var b strings.Builder
+	cw := NewCSVWriter(&b, KindDaily, units.Metric, Tags{})
+	if err := cw.WriteHourly(&hourly.Hourly[0]); err != ErrKind {
+		t.Fatalf("expected ErrKind got %v\n", err)
+	}
+
+	if b.Len() != 0 {
+		t.Fatalf("expected nothing written got %q\n", b.String())
+	}
+
+	if err := cw.WriteForecastDay(&forecast.Forecast.Simpleforecast.Forecastday[0]); err != nil {
+		t.Fatalf("error writing forecast day: %s\n", err)
+	}
+
+	// an explicit header is not repeated
+	if err := cw.WriteHeader(); err != nil {
+		t.Fatalf("error writing header: %s\n", err)
+	}
+
+	if err := cw.WriteForecastDay(&wug.ForecastDay{}); err != nil {
+		t.Fatalf("error writing empty forecast day: %s\n", err)
+	}
+
+	records := readCSV(t, b.String())
+	if len(records) != 3 || records[1][0] == "" {
+		t.Fatalf("expected a header and two rows got %v\n", records)
+	}
+
+	for _, v := range records[2] {
+		if v != "" {
+			t.Fatalf("expected an empty row for an empty day got %v\n", records[2])
+		}
+	}
+}
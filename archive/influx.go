@@ -0,0 +1,109 @@
+package archive
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/wirepair/wug"
+	"github.com/wirepair/wug/units"
+)
+
+// LineWriter writes InfluxDB line protocol, one line per write with
+// nanosecond timestamps
+type LineWriter struct {
+	System units.System // units of the fields
+	Tags   Tags         // tags of data that does not carry its own
+
+	w   io.Writer
+	buf []byte
+}
+
+// NewLineWriter returns a LineWriter writing to w.
+func NewLineWriter(w io.Writer, system units.System, tags Tags) *LineWriter {
+	return &LineWriter{System: system, Tags: tags, w: w}
+}
+
+// WriteConditions writes the observation of the conditions.
+func (lw *LineWriter) WriteConditions(c *wug.Conditions) error {
+	o := c.Observation()
+	return writeLine(lw, observationLayout, &o)
+}
+
+// WriteHourly writes an hourly forecast.
+func (lw *LineWriter) WriteHourly(h *wug.HourlyForecast) error {
+	p := h.HourlyPoint()
+	return writeLine(lw, hourlyLayout, &p)
+}
+
+// WriteForecastDay writes a forecast day.
+func (lw *LineWriter) WriteForecastDay(f *wug.ForecastDay) error {
+	d := f.DailyForecast()
+	return writeLine(lw, dailyLayout, &d)
+}
+
+var (
+	measurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	tagEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+	stringEscaper      = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+)
+
+// writeLine writes x as a line of the layout. Data without any field is
+// skipped, since a line needs at least one.
+func writeLine[T any](lw *LineWriter, l layout[T], x *T) error {
+	b := append(lw.buf[:0], measurementEscaper.Replace(l.kind.String())...)
+
+	tags := l.tagsOf(x, lw.Tags)
+	for _, tag := range []struct{ key, value string }{{"location", tags.Location}, {"station_id", tags.StationID}} {
+		if tag.value != "" {
+			b = append(b, ',')
+			b = append(b, tag.key...)
+			b = append(b, '=')
+			b = append(b, tagEscaper.Replace(tag.value)...)
+		}
+	}
+
+	fields := 0
+	for _, c := range l.columns {
+		v, ok := c.value(x, lw.System)
+		if !ok {
+			continue
+		}
+
+		if fields == 0 {
+			b = append(b, ' ')
+		} else {
+			b = append(b, ',')
+		}
+		fields++
+
+		b = append(b, tagEscaper.Replace(c.header(lw.System))...)
+		b = append(b, '=')
+		switch v := v.(type) {
+		case float64:
+			b = strconv.AppendFloat(b, v, 'f', -1, 64)
+		case int64:
+			b = strconv.AppendInt(b, v, 10)
+			b = append(b, 'i')
+		case string:
+			b = append(b, '"')
+			b = append(b, stringEscaper.Replace(v)...)
+			b = append(b, '"')
+		}
+	}
+
+	lw.buf = b
+	if fields == 0 {
+		return nil
+	}
+
+	if t := l.time(x); !t.IsZero() {
+		b = append(b, ' ')
+		b = strconv.AppendInt(b, t.UnixNano(), 10)
+	}
+	b = append(b, '\n')
+	lw.buf = b
+
+	_, err := lw.w.Write(b)
+	return err
+}
@@ -0,0 +1,137 @@
+package archive
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/wirepair/wug"
+	"github.com/wirepair/wug/units"
+)
+
+func TestLineConditions(t *testing.T) {
+	conditions, _, _ := testData(t)
+
+	var b strings.Builder
+	lw := NewLineWriter(&b, units.Metric, Tags{StationID: "OTHER", Location: "Home"})
+	if err := lw.WriteConditions(conditions); err != nil {
+		t.Fatalf("error writing conditions: %s\n", err)
+	}
+
+	line := b.String()
+	if strings.Count(line, "\n") != 1 || !strings.HasSuffix(line, "\n") {
+		t.Fatalf("expected a single line got %q\n", line)
+	}
+
+	// the observation carries its own tags
+	if !strings.HasPrefix(line, `wug_conditions,location=San\ Francisco\,\ CA,station_id=WUGTEST `) {
+		t.Fatalf("expected the tags of the observation got %s\n", line)
+	}
+
+	for _, want := range []string{" condition=\"", ",temperature_c=", ",humidity_pct=", ",wind_speed_kph=", ",pressure_hpa=", ",precip_today_mm="} {
+		if !strings.Contains(line, want) {
+			t.Fatalf("expected %s in %s\n", want, line)
+		}
+	}
+
+	fields := strings.Fields(line)
+	timestamp, err := strconv.ParseInt(fields[len(fields)-1], 10, 64)
+	if err != nil {
+		t.Fatalf("error parsing timestamp: %s\n", err)
+	}
+
+	if observed := conditions.Observation().Time; timestamp != observed.UnixNano() {
+		t.Fatalf("expected nanosecond timestamp %d got %d\n", observed.UnixNano(), timestamp)
+	}
+
+	humidity := fieldValue(line, "humidity_pct")
+	if !strings.HasSuffix(humidity, "i") {
+		t.Fatalf("expected an integer humidity got %s\n", humidity)
+	}
+}
+
+func TestLineUnits(t *testing.T) {
+	_, hourly, _ := testData(t)
+
+	var b strings.Builder
+	lw := NewLineWriter(&b, units.Imperial, Tags{Location: "Home"})
+	for i := range hourly.Hourly {
+		if err := lw.WriteHourly(&hourly.Hourly[i]); err != nil {
+			t.Fatalf("error writing hour: %s\n", err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) != len(hourly.Hourly) {
+		t.Fatalf("expected %d lines got %d\n", len(hourly.Hourly), len(lines))
+	}
+
+	line := lines[0]
+	if !strings.HasPrefix(line, "wug_hourly,location=Home ") {
+		t.Fatalf("expected the writer tags got %s\n", line)
+	}
+
+	fahrenheit, err := strconv.ParseFloat(fieldValue(line, "temperature_f"), 64)
+	if err != nil {
+		t.Fatalf("error parsing temperature: %s\n", err)
+	}
+
+	if want, _ := hourly.Hourly[0].HourlyPoint().Temperature.In(units.Imperial); math.Abs(fahrenheit-want) > 1e-4 {
+		t.Fatalf("expected %v°F got %v\n", want, fahrenheit)
+	}
+
+	// -9999 sentinels are left out rather than written as values
+	if strings.Contains(line, "heat_index") || strings.Contains(line, "-9999") {
+		t.Fatalf("expected missing values to be skipped got %s\n", line)
+	}
+}
+
+func TestLineForecastDay(t *testing.T) {
+	_, _, forecast := testData(t)
+
+	var b strings.Builder
+	lw := NewLineWriter(&b, units.Metric, Tags{})
+	day := &forecast.Forecast.Simpleforecast.Forecastday[0]
+	if err := lw.WriteForecastDay(day); err != nil {
+		t.Fatalf("error writing forecast day: %s\n", err)
+	}
+
+	line := b.String()
+	if !strings.HasPrefix(line, "wug_daily ") || fieldValue(line, "high_c") == "" || fieldValue(line, "pop_pct") == "" {
+		t.Fatalf("expected an untagged forecast day got %s\n", line)
+	}
+
+	// a line needs a field, empty data writes nothing
+	b.Reset()
+	if err := lw.WriteForecastDay(&wug.ForecastDay{}); err != nil {
+		t.Fatalf("error writing empty forecast day: %s\n", err)
+	}
+
+	if b.Len() != 0 {
+		t.Fatalf("expected nothing written got %q\n", b.String())
+	}
+}
+
+func TestLineEscaping(t *testing.T) {
+	var b strings.Builder
+	lw := NewLineWriter(&b, units.Metric, Tags{StationID: "a=b", Location: "x, y"})
+	h := wug.HourlyForecast{Condition: `say "hi" \o/`}
+	if err := lw.WriteHourly(&h); err != nil {
+		t.Fatalf("error writing hour: %s\n", err)
+	}
+
+	if want := `wug_hourly,location=x\,\ y,station_id=a\=b condition="say \"hi\" \\o/"` + "\n"; b.String() != want {
+		t.Fatalf("expected %q got %q\n", want, b.String())
+	}
+}
+
+// fieldValue returns the raw value of a field in a line
+func fieldValue(line, key string) string {
+	for _, field := range strings.Split(line, ",") {
+		if k, v, ok := strings.Cut(field, "="); ok && (k == key || strings.HasSuffix(k, " "+key)) {
+			return strings.Fields(v)[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package wug
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ClockTime is a local time of day of the astronomy feature
+type ClockTime struct {
+	Hour   string `json:"hour"`
+	Minute string `json:"minute"`
+}
+
+// Clock returns the hour and minute, ok is false if either is missing.
+func (c ClockTime) Clock() (hour, minute int, ok bool) {
+	hour, errHour := strconv.Atoi(c.Hour)
+	minute, errMinute := strconv.Atoi(c.Minute)
+	if errHour != nil || errMinute != nil {
+		return 0, 0, false
+	}
+	return hour, minute, true
+}
+
+// String returns the time as HH:MM, or an empty string if it is missing.
+func (c ClockTime) String() string {
+	hour, minute, ok := c.Clock()
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%02d:%02d", hour, minute)
+}
+
+// Astronomy sun and moon information
+type Astronomy struct {
+	DecodeResult
+	Response struct {
+		Version        string `json:"version"`
+		TermsofService string `json:"termsofService"`
+		Features       struct {
+			Astronomy int `json:"astronomy"`
+		} `json:"features"`
+	} `json:"response"`
+	MoonPhase struct {
+		PercentIlluminated FlexFloat `json:"percentIlluminated"`
+		AgeOfMoon          FlexFloat `json:"ageOfMoon"`
+		PhaseofMoon        string    `json:"phaseofMoon"`
+		Hemisphere         string    `json:"hemisphere"`
+		CurrentTime        ClockTime `json:"current_time"`
+		Sunrise            ClockTime `json:"sunrise"`
+		Sunset             ClockTime `json:"sunset"`
+		Moonrise           ClockTime `json:"moonrise"`
+		Moonset            ClockTime `json:"moonset"`
+	} `json:"moon_phase"`
+	SunPhase struct {
+		Sunrise ClockTime `json:"sunrise"`
+		Sunset  ClockTime `json:"sunset"`
+	} `json:"sun_phase"`
+}
@@ -0,0 +1,28 @@
+package wug
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAstronomy(t *testing.T) {
+	data := []byte(`{"moon_phase": {"percentIlluminated": "81", "ageOfMoon": "10", "phaseofMoon": "Waxing Gibbous", "hemisphere": "North",
+		"current_time": {"hour": "9", "minute": "56"}, "sunrise": {"hour": "7", "minute": "1"}, "sunset": {"hour": "16", "minute": "56"},
+		"moonrise": {"hour": "", "minute": ""}}, "sun_phase": {"sunrise": {"hour": "7", "minute": "01"}, "sunset": {"hour": "16", "minute": "56"}}}`)
+	a := &Astronomy{}
+	if err := json.Unmarshal(data, a); err != nil {
+		t.Fatalf("error decoding astronomy: %s\n", err)
+	}
+
+	if a.SunPhase.Sunrise.String() != "07:01" || a.MoonPhase.Sunset.String() != "16:56" {
+		t.Fatalf("unexpected sun phase %#v\n", a.SunPhase)
+	}
+
+	if _, _, ok := a.MoonPhase.Moonrise.Clock(); ok || a.MoonPhase.Moonrise.String() != "" {
+		t.Fatalf("expected no moonrise got %s\n", a.MoonPhase.Moonrise)
+	}
+
+	if a.MoonPhase.PercentIlluminated.Value != 81 {
+		t.Fatalf("expected 81%% illuminated got %v\n", a.MoonPhase.PercentIlluminated)
+	}
+}
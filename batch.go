@@ -0,0 +1,101 @@
+package wug
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultBatchWorkers is the number of workers used by GetBatch when
+// BatchOptions.Workers is not set.
+const DefaultBatchWorkers = 4
+
+// BatchOptions for GetBatch
+type BatchOptions struct {
+	Workers  int                   // number of concurrent requests, defaults to DefaultBatchWorkers
+	Progress func(done, total int) // optional, called after each unique request completes
+}
+
+// BatchResult is the result of a single query of a batch
+type BatchResult struct {
+	Query *Query
+	Data  []byte // raw response, shared between identical queries
+	Err   error
+}
+
+// GetBatch requests the request type for every query using a bounded number
+// of workers. Identical queries (same feature, settings and query value) are
+// only requested once. A failing query does not stop the batch, every query
+// has its own result at the same index as the query. Requests wait on the
+// client Limiter, so a batch paces itself against the key quota. Queries that
+// were not started before ctx is done get the context error.
+func (w *Wug) GetBatch(ctx context.Context, requestType RequestType, queries []*Query, options BatchOptions) []BatchResult {
+	results := make([]BatchResult, len(queries))
+
+	// group identical queries by their request url
+	var order []string
+	groups := make(map[string][]int)
+	for i, query := range queries {
+		results[i].Query = query
+		target, err := requestURL(requestType, w.Settings.Merge(query.settings), query)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		key := target.String()
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+
+	var mu sync.Mutex
+	done := 0
+	finish := func(key string, data []byte, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, i := range groups[key] {
+			results[i].Data = data
+			results[i].Err = err
+		}
+		done++
+		if options.Progress != nil {
+			options.Progress(done, len(order))
+		}
+	}
+
+	workers := options.Workers
+	if workers <= 0 {
+		workers = DefaultBatchWorkers
+	}
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for n := 0; n < workers; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range jobs {
+				data, err := w.GetWithContext(ctx, requestType, queries[groups[key][0]])
+				finish(key, data, err)
+			}
+		}()
+	}
+
+	for i, key := range order {
+		select {
+		case jobs <- key:
+			continue
+		case <-ctx.Done():
+		}
+
+		for _, key := range order[i:] {
+			finish(key, nil, ctx.Err())
+		}
+		break
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
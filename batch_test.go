@@ -0,0 +1,103 @@
+package wug
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// newEchoWug returns a Wug whose transport responds with the request path
+// and counts the requests made.
+func newEchoWug(count *int64) *Wug {
+	w := NewWug()
+	w.Client = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		atomic.AddInt64(count, 1)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(r.URL.EscapedPath())),
+			Request:    r,
+		}, nil
+	})}
+	return w
+}
+
+func TestGetBatch(t *testing.T) {
+	var count int64
+	w := newEchoWug(&count)
+
+	queries := []*Query{
+		NewQueryByUsZip("apikey", "90210"),
+		NewQueryByAirportCode("apikey", "NRT"),
+		NewQueryByUsZip("apikey", "90210"),
+		NewQueryByAirportCode("apikey", "NRT").WithSettings(Settings{Language: LangJapanese}),
+		NewQueryByAutoIP("apikey").WithSettings(Settings{PWS: Toggle(5)}),
+	}
+
+	var mu sync.Mutex
+	var progress []int
+	results := w.GetBatch(context.Background(), Cond, queries, BatchOptions{
+		Workers: 2,
+		Progress: func(done, total int) {
+			mu.Lock()
+			defer mu.Unlock()
+			if total != 3 {
+				t.Errorf("expected 3 unique requests got %d\n", total)
+			}
+			progress = append(progress, done)
+		},
+	})
+
+	if count != 3 {
+		t.Fatalf("expected 3 requests got %d\n", count)
+	}
+
+	if len(progress) != 3 || progress[2] != 3 {
+		t.Fatalf("expected progress 1..3 got %v\n", progress)
+	}
+
+	expected := []string{
+		"/api/apikey/conditions/q/90210.json",
+		"/api/apikey/conditions/q/NRT.json",
+		"/api/apikey/conditions/q/90210.json",
+		"/api/apikey/conditions/lang:JP/q/NRT.json",
+	}
+	for i, want := range expected {
+		if results[i].Err != nil {
+			t.Fatalf("unexpected error for %d: %s\n", i, results[i].Err)
+		}
+
+		if results[i].Query != queries[i] || string(results[i].Data) != want {
+			t.Fatalf("expected %s got %s\n", want, results[i].Data)
+		}
+	}
+
+	if results[4].Err == nil {
+		t.Fatalf("expected invalid settings error")
+	}
+}
+
+func TestGetBatchCancelled(t *testing.T) {
+	var count int64
+	w := newEchoWug(&count)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	queries := []*Query{NewQueryByUsZip("apikey", "90210"), NewQueryByUsZip("apikey", "10001")}
+	results := w.GetBatch(ctx, Cond, queries, BatchOptions{})
+	for _, result := range results {
+		if result.Err != context.Canceled {
+			t.Fatalf("expected context.Canceled got %v\n", result.Err)
+		}
+	}
+}
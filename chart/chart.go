@@ -0,0 +1,196 @@
+// Package chart draws hourly forecasts as text charts for terminals:
+// sparklines, braille line charts, bars and wind arrows.
+package chart
+
+import (
+	"math"
+	"strings"
+)
+
+// sparks are the block elements of sparklines, lowest first
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// arrows point where the wind blows to, starting with a north wind
+var arrows = []rune("↓↙←↖↑↗→↘")
+
+// Sparkline returns one block element per value scaled between the minimum
+// and maximum of values. Missing values (NaN) are blank.
+func Sparkline(values []float64) string {
+	lo, hi := bounds(values)
+	return blocks(values, lo, hi)
+}
+
+// Bars returns one block element per value scaled between 0 and max, e.g. 100
+// for percentages. Zero and missing values are blank.
+func Bars(values []float64, max float64) string {
+	var b strings.Builder
+	for _, v := range values {
+		if math.IsNaN(v) || v <= 0 {
+			b.WriteRune(' ')
+			continue
+		}
+		b.WriteRune(sparks[level(v, 0, max, len(sparks))])
+	}
+	return b.String()
+}
+
+func blocks(values []float64, lo, hi float64) string {
+	var b strings.Builder
+	for _, v := range values {
+		if math.IsNaN(v) {
+			b.WriteRune(' ')
+			continue
+		}
+		b.WriteRune(sparks[level(v, lo, hi, len(sparks))])
+	}
+	return b.String()
+}
+
+// Arrow returns the arrow of a wind blowing from degrees, pointing where the
+// wind blows to.
+func Arrow(degrees float64) rune {
+	if math.IsNaN(degrees) {
+		return ' '
+	}
+	i := int(math.Round(math.Mod(math.Mod(degrees, 360)+360, 360)/45)) % len(arrows)
+	return arrows[i]
+}
+
+// Braille draws values as a line chart of height rows using braille
+// patterns, two values per character. Consecutive values are joined by
+// vertical strokes, missing values (NaN) break the line. The rows are
+// returned top first.
+func Braille(values []float64, height int) []string {
+	lo, hi := bounds(values)
+	return braille(values, height, lo, hi)
+}
+
+// dots are the braille dot bits of a cell, by column then row from the top
+var dots = [2][4]rune{
+	{0x01, 0x02, 0x04, 0x40},
+	{0x08, 0x10, 0x20, 0x80},
+}
+
+func braille(values []float64, height int, lo, hi float64) []string {
+	if height < 1 {
+		height = 1
+	}
+
+	width := (len(values) + 1) / 2
+	cells := make([][]rune, height)
+	for i := range cells {
+		cells[i] = make([]rune, width)
+	}
+
+	// y of each value, 0 is the bottom dot row
+	rows := height * 4
+	ys := make([]int, len(values))
+	for i, v := range values {
+		ys[i] = -1
+		if !math.IsNaN(v) {
+			ys[i] = level(v, lo, hi, rows)
+		}
+	}
+
+	set := func(x, y int) {
+		row := height - 1 - y/4
+		cells[row][x/2] |= dots[x%2][3-y%4]
+	}
+
+	for x, y := range ys {
+		if y < 0 {
+			continue
+		}
+		set(x, y)
+
+		// join with the previous value, splitting the stroke between both
+		if x > 0 && ys[x-1] >= 0 {
+			prev := ys[x-1]
+			mid := (prev + y) / 2
+			for fill := min(prev, y) + 1; fill < max(prev, y); fill++ {
+				if (fill <= mid) == (prev < y) {
+					set(x-1, fill)
+				} else {
+					set(x, fill)
+				}
+			}
+		}
+	}
+
+	lines := make([]string, height)
+	for i, row := range cells {
+		var b strings.Builder
+		for _, c := range row {
+			b.WriteRune(0x2800 + c)
+		}
+		lines[i] = b.String()
+	}
+	return lines
+}
+
+// bounds returns the minimum and maximum of the values that are not missing.
+func bounds(values []float64) (lo, hi float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+
+	if math.IsInf(lo, 1) {
+		return 0, 0
+	}
+	return lo, hi
+}
+
+// level scales v between lo and hi into one of n levels.
+func level(v, lo, hi float64, n int) int {
+	if hi <= lo {
+		return n / 2
+	}
+
+	l := int(math.Round((v - lo) / (hi - lo) * float64(n-1)))
+	return max(0, min(n-1, l))
+}
+
+// resample buckets values into n values, averaging each bucket. Fewer values
+// than n are repeated. Wind directions are averaged on the circle when
+// circular is set.
+func resample(values []float64, n int, circular bool) []float64 {
+	if n <= 0 || len(values) == 0 {
+		return nil
+	}
+
+	out := make([]float64, n)
+	for i := range out {
+		start := i * len(values) / n
+		end := max(start+1, (i+1)*len(values)/n)
+		out[i] = mean(values[start:end], circular)
+	}
+	return out
+}
+
+// mean of the values that are not missing, NaN if all are missing.
+func mean(values []float64, circular bool) float64 {
+	var sum, sin, cos float64
+	var count int
+	for _, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+
+		count++
+		sum += v
+		rad := v * math.Pi / 180
+		sin, cos = sin+math.Sin(rad), cos+math.Cos(rad)
+	}
+
+	switch {
+	case count == 0:
+		return math.NaN()
+	case circular:
+		return math.Mod(math.Atan2(sin, cos)*180/math.Pi+360, 360)
+	}
+	return sum / float64(count)
+}
@@ -0,0 +1,99 @@
+package chart
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSparkline(t *testing.T) {
+	if got := Sparkline([]float64{1, 2, 3, 4, 5, 6, 7, 8}); got != "▁▂▃▄▅▆▇█" {
+		t.Fatalf("expected ▁▂▃▄▅▆▇█ got %s\n", got)
+	}
+
+	if got := Sparkline([]float64{0, math.NaN(), 10}); got != "▁ █" {
+		t.Fatalf("expected a blank for the missing value got %s\n", got)
+	}
+
+	if got := Sparkline([]float64{5, 5}); got != "▅▅" {
+		t.Fatalf("expected flat values in the middle got %s\n", got)
+	}
+}
+
+func TestBars(t *testing.T) {
+	if got := Bars([]float64{0, 50, 100, math.NaN()}, 100); got != " ▅█ " {
+		t.Fatalf("expected \" ▅█ \" got %q\n", got)
+	}
+}
+
+func TestArrow(t *testing.T) {
+	var tests = []struct {
+		degrees float64
+		want    rune
+	}{
+		{0, '↓'}, {90, '←'}, {180, '↑'}, {270, '→'}, {225, '↗'}, {359, '↓'}, {-90, '→'}, {math.NaN(), ' '},
+	}
+
+	for _, tt := range tests {
+		if got := Arrow(tt.degrees); got != tt.want {
+			t.Fatalf("%v: expected %c got %c\n", tt.degrees, tt.want, got)
+		}
+	}
+}
+
+func TestBraille(t *testing.T) {
+	rows := Braille([]float64{0, 1, 2, 3, 4, 5, 6, 7}, 2)
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows got %d\n", len(rows))
+	}
+
+	// a rising line is the same two dot steps in each cell
+	want := []string{"⠀⠀⡠⠊", "⡠⠊⠀⠀"}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Fatalf("row %d: expected %s got %s\n", i, want[i], rows[i])
+		}
+	}
+
+	// a missing value breaks the line and odd lengths fill the last cell
+	rows = Braille([]float64{0, math.NaN(), 4}, 1)
+	if rows[0] != "⡀⠁" {
+		t.Fatalf("expected ⡀⠁ got %s\n", rows[0])
+	}
+}
+
+func TestResample(t *testing.T) {
+	got := resample([]float64{1, 3, 5, 7}, 2, false)
+	if got[0] != 2 || got[1] != 6 {
+		t.Fatalf("expected [2 6] got %v\n", got)
+	}
+
+	got = resample([]float64{1, 2}, 4, false)
+	if got[0] != 1 || got[1] != 1 || got[2] != 2 || got[3] != 2 {
+		t.Fatalf("expected [1 1 2 2] got %v\n", got)
+	}
+
+	got = resample([]float64{350, 10}, 1, true)
+	if math.Abs(got[0]) > 1e-9 && math.Abs(got[0]-360) > 1e-9 {
+		t.Fatalf("expected a north mean of 350 and 10 got %v\n", got)
+	}
+
+	if got := resample([]float64{math.NaN(), math.NaN()}, 1, false); !math.IsNaN(got[0]) {
+		t.Fatalf("expected NaN for missing values got %v\n", got)
+	}
+}
+
+func TestPaint(t *testing.T) {
+	if got := Red.Paint("hot"); got != "\x1b[31mhot\x1b[0m" {
+		t.Fatalf("expected red escape codes got %q\n", got)
+	}
+
+	if got := None.Paint("plain"); got != "plain" {
+		t.Fatalf("expected no escape codes got %q\n", got)
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if ColorEnabled(&strings.Builder{}) {
+		t.Fatalf("expected colours to be disabled")
+	}
+}
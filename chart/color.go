@@ -0,0 +1,89 @@
+package chart
+
+import (
+	"io"
+	"os"
+)
+
+// Color is an ANSI terminal colour
+type Color int
+
+// Color constants
+const (
+	None Color = iota
+	Blue
+	Cyan
+	Green
+	Yellow
+	Red
+	Magenta
+	Gray
+)
+
+var colorMap = map[Color]string{
+	Blue:    "\x1b[34m",
+	Cyan:    "\x1b[36m",
+	Green:   "\x1b[32m",
+	Yellow:  "\x1b[33m",
+	Red:     "\x1b[31m",
+	Magenta: "\x1b[35m",
+	Gray:    "\x1b[90m",
+}
+
+const reset = "\x1b[0m"
+
+// Paint wraps s in the escape codes of the colour, s is returned as is if
+// the colour is None.
+func (c Color) Paint(s string) string {
+	code, ok := colorMap[c]
+	if !ok || s == "" {
+		return s
+	}
+	return code + s + reset
+}
+
+// ColorEnabled reports whether w is a terminal that colours should be
+// written to. NO_COLOR and TERM=dumb disable colours.
+func ColorEnabled(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// temperatureColor returns the colour of a temperature in °C.
+func temperatureColor(celsius float64) Color {
+	switch {
+	case celsius <= 0:
+		return Magenta
+	case celsius < 10:
+		return Blue
+	case celsius < 18:
+		return Cyan
+	case celsius < 25:
+		return Green
+	case celsius < 30:
+		return Yellow
+	}
+	return Red
+}
+
+// windColor returns the colour of a wind speed in m/s.
+func windColor(speed float64) Color {
+	switch {
+	case speed < 3.4: // up to a gentle breeze
+		return Gray
+	case speed < 8: // moderate breeze
+		return Green
+	case speed < 13.9: // strong breeze
+		return Yellow
+	}
+	return Red
+}
@@ -0,0 +1,220 @@
+package chart
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/wirepair/wug"
+	"github.com/wirepair/wug/units"
+)
+
+// ErrNoHours is returned when there are no hours to draw.
+var ErrNoHours = errors.New("chart: no hours to draw")
+
+// Default sizes of a meteogram
+const (
+	DefaultWidth  = 80 // characters, including the labels
+	DefaultHeight = 4  // rows of the temperature chart
+)
+
+const (
+	gutter = 11 // width of the row labels
+	margin = 9  // width of the value labels right of the charts
+)
+
+// Hour is the charted values of a forecast hour in metric units, NaN when
+// missing
+type Hour struct {
+	Time          time.Time
+	Day           string  // weekday and day of the month, e.g. Wed 1
+	Temperature   float64 // °C
+	Pop           float64 // percent
+	WindSpeed     float64 // m/s
+	WindDirection float64 // degrees the wind blows from
+}
+
+// Options of a meteogram
+type Options struct {
+	Width  int          // total width in characters, DefaultWidth if 0
+	Height int          // rows of the temperature chart, DefaultHeight if 0
+	Color  bool         // colour the charts with ANSI escape codes
+	System units.System // units of the labels
+}
+
+// Hours returns the charted values of hourly forecasts, days are taken from
+// the FCTTIME of each hour.
+func Hours(forecasts []wug.HourlyForecast) []Hour {
+	hours := make([]Hour, 0, len(forecasts))
+	for i := range forecasts {
+		f := &forecasts[i]
+		hour := Hour{
+			Day:           strings.TrimSpace(f.Fcttime.WeekdayNameAbbrev + " " + f.Fcttime.Mday),
+			Temperature:   math.NaN(),
+			Pop:           math.NaN(),
+			WindSpeed:     math.NaN(),
+			WindDirection: math.NaN(),
+		}
+		hour.Time, _ = f.Fcttime.Time()
+
+		if t, ok := f.Temperature(); ok {
+			hour.Temperature = t.Celsius()
+		}
+
+		if f.Pop.Valid {
+			hour.Pop = f.Pop.Value
+		}
+
+		if s, ok := f.WindSpeed(); ok {
+			hour.WindSpeed = s.MetersPerSecond()
+		}
+
+		if f.Wdir.Degrees.Valid {
+			hour.WindDirection = f.Wdir.Degrees.Value
+		}
+		hours = append(hours, hour)
+	}
+	return hours
+}
+
+// Render writes a meteogram of the hours: a braille chart of the
+// temperature, bars of the probability of precipitation and wind arrows,
+// above an axis marking the start of each day. Hours are averaged into
+// columns when there are more than fit the width.
+func Render(w io.Writer, hours []Hour, opts Options) error {
+	if len(hours) == 0 {
+		return ErrNoHours
+	}
+
+	if opts.Width <= 0 {
+		opts.Width = DefaultWidth
+	}
+
+	if opts.Height <= 0 {
+		opts.Height = DefaultHeight
+	}
+
+	cols := min(max(opts.Width-gutter-margin, 1), len(hours))
+	column := func(get func(h Hour) float64) []float64 {
+		values := make([]float64, len(hours))
+		for i, h := range hours {
+			values[i] = get(h)
+		}
+		return values
+	}
+
+	temps := resample(column(func(h Hour) float64 { return h.Temperature }), cols*2, false)
+	pops := resample(column(func(h Hour) float64 { return h.Pop }), cols, false)
+	speeds := resample(column(func(h Hour) float64 { return h.WindSpeed }), cols, false)
+	dirs := resample(column(func(h Hour) float64 { return h.WindDirection }), cols, true)
+
+	var b strings.Builder
+	paint := func(c Color, s string) {
+		if opts.Color {
+			s = c.Paint(s)
+		}
+		b.WriteString(s)
+	}
+
+	// temperature, labelled with its maximum and minimum
+	lo, hi := bounds(temps)
+	_, tempUnit := units.Celsius(0).In(opts.System)
+	rows := braille(temps, opts.Height, lo, hi)
+	for i, row := range rows {
+		label := ""
+		if i == 0 {
+			label = "Temp " + tempUnit
+		}
+		fmt.Fprintf(&b, "%-*s", gutter, label)
+
+		for x, cell := range []rune(row) {
+			paint(temperatureColor(mean(temps[x*2:min(x*2+2, len(temps))], false)), string(cell))
+		}
+
+		switch {
+		case missing(temps):
+		case i == 0:
+			fmt.Fprintf(&b, " %s", formatValue(units.Celsius(hi), opts.System))
+		case i == len(rows)-1:
+			fmt.Fprintf(&b, " %s", formatValue(units.Celsius(lo), opts.System))
+		}
+		b.WriteString("\n")
+	}
+
+	// probability of precipitation
+	fmt.Fprintf(&b, "%-*s", gutter, "Rain %")
+	paint(Blue, Bars(pops, 100))
+	b.WriteString("\n")
+
+	// wind, labelled with its maximum
+	_, speedUnit := units.MetersPerSecond(0).In(opts.System)
+	fmt.Fprintf(&b, "%-*s", gutter, "Wind "+speedUnit)
+	for x, dir := range dirs {
+		paint(windColor(speeds[x]), string(Arrow(dir)))
+	}
+	if _, top := bounds(speeds); !missing(speeds) {
+		fmt.Fprintf(&b, " %s", formatValue(units.MetersPerSecond(top), opts.System))
+	}
+	b.WriteString("\n")
+
+	fmt.Fprintf(&b, "%-*s", gutter, "")
+	b.WriteString(axis(hours, cols))
+	b.WriteString("\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// axis returns the day separators of the columns, each day starts with a |
+// followed by its name, or only its weekday, where it fits.
+func axis(hours []Hour, cols int) string {
+	var starts []int // columns of the separators
+	var names []string
+	for x := 0; x < cols; x++ {
+		start := x * len(hours) / cols
+		end := max(start+1, (x+1)*len(hours)/cols)
+		for i := start; i < end; i++ {
+			if i == 0 || hours[i].Day != hours[i-1].Day {
+				starts = append(starts, x)
+				names = append(names, hours[i].Day)
+				break
+			}
+		}
+	}
+
+	line := []rune(strings.Repeat(" ", cols))
+	for i, x := range starts {
+		line[x] = '|'
+
+		next := cols
+		if i+1 < len(starts) {
+			next = starts[i+1]
+		}
+
+		weekday, _, _ := strings.Cut(names[i], " ")
+		for _, name := range []string{names[i], weekday} {
+			if name := []rune(name); x+1+len(name) <= next {
+				copy(line[x+1:], name)
+				break
+			}
+		}
+	}
+	return strings.TrimRight(string(line), " ")
+}
+
+// missing reports whether all values are missing.
+func missing(values []float64) bool {
+	return math.IsNaN(mean(values, false))
+}
+
+// formatValue formats a measurement in the system without decimals.
+func formatValue(m units.Measure, system units.System) string {
+	v, unit := m.In(system)
+	if strings.HasPrefix(unit, "°") {
+		return fmt.Sprintf("%.0f%s", v, unit)
+	}
+	return fmt.Sprintf("%.0f %s", v, unit)
+}
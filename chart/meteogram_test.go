@@ -0,0 +1,84 @@
+package chart
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/wirepair/wug/units"
+	"github.com/wirepair/wug/wugtest"
+)
+
+func TestHours(t *testing.T) {
+	now := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+	hours := Hours(wugtest.GenerateHourly(wugtest.DefaultLocation, now, 36))
+	if len(hours) != 36 {
+		t.Fatalf("expected 36 hours got %d\n", len(hours))
+	}
+
+	if hours[0].Day != "Wed 1" {
+		t.Fatalf("expected Wed 1 got %s\n", hours[0].Day)
+	}
+
+	if hours[0].Time.IsZero() || !hours[1].Time.After(hours[0].Time) {
+		t.Fatalf("expected increasing hour times got %v %v\n", hours[0].Time, hours[1].Time)
+	}
+}
+
+func TestRender(t *testing.T) {
+	now := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+	hours := Hours(wugtest.GenerateHourly(wugtest.DefaultLocation, now, 240))
+
+	var buf bytes.Buffer
+	if err := Render(&buf, hours, Options{Width: 60, System: units.Metric}); err != nil {
+		t.Fatalf("error rendering: %s\n", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != DefaultHeight+3 {
+		t.Fatalf("expected %d lines got %d:\n%s\n", DefaultHeight+3, len(lines), buf.String())
+	}
+
+	for _, line := range lines {
+		if utf8.RuneCountInString(line) > 60 {
+			t.Fatalf("expected lines within 60 characters got %d: %s\n", utf8.RuneCountInString(line), line)
+		}
+	}
+
+	if !strings.HasPrefix(lines[0], "Temp °C") || !strings.HasSuffix(lines[0], "°C") {
+		t.Fatalf("expected the temperature chart in °C got %s\n", lines[0])
+	}
+
+	if !strings.Contains(lines[len(lines)-2], "km/h") {
+		t.Fatalf("expected the wind in km/h got %s\n", lines[len(lines)-2])
+	}
+
+	days := map[string]bool{}
+	for _, h := range hours {
+		days[h.Day] = true
+	}
+
+	axis := lines[len(lines)-1]
+	if strings.Count(axis, "|") != len(days) || !strings.Contains(axis, "|Wed|Thu|") {
+		t.Fatalf("expected a separator and weekday for each of the %d days got %s\n", len(days), axis)
+	}
+
+	if strings.Contains(buf.String(), "\x1b[") {
+		t.Fatalf("expected no escape codes without colour")
+	}
+
+	buf.Reset()
+	if err := Render(&buf, hours, Options{Color: true}); err != nil {
+		t.Fatalf("error rendering: %s\n", err)
+	}
+
+	if !strings.Contains(buf.String(), "\x1b[") {
+		t.Fatalf("expected escape codes with colour")
+	}
+
+	if err := Render(&buf, nil, Options{}); err != ErrNoHours {
+		t.Fatalf("expected ErrNoHours got %v\n", err)
+	}
+}
@@ -0,0 +1,175 @@
+// Command wug-exporter serves the current conditions of weather underground
+// locations as Prometheus metrics on /metrics.
+//
+//	wug-exporter --location home=pws:KCASANFR70 --location office=zip:94105
+//
+// Locations are name=query, the query is one of zip:, pws:, airport:,
+// latlon:, city: (STATE/City or Country/City), ip: or autoip. The API key is
+// read from --key or the WUGKEY environment variable.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/wirepair/wug"
+	"github.com/wirepair/wug/exporter"
+	"golang.org/x/time/rate"
+)
+
+// keyEnv is the environment variable the API key is read from
+const keyEnv = "WUGKEY"
+
+// newClient returns the client of the exporter, replaced in tests
+var newClient = wug.NewWug
+
+// locations is a repeatable flag of name=query locations
+type locations []string
+
+func (l *locations) String() string { return strings.Join(*l, ",") }
+
+func (l *locations) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stderr))
+}
+
+// run serves the metrics until ctx is done and returns the exit code.
+func run(ctx context.Context, args []string, stderr io.Writer) int {
+	var (
+		locs     locations
+		key      string
+		listen   string
+		interval time.Duration
+		quota    int
+		perMin   int
+	)
+
+	fs := flag.NewFlagSet("wug-exporter", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Var(&locs, "location", "name=query of a location, may be repeated")
+	fs.StringVar(&key, "key", "", "API key, defaults to $"+keyEnv)
+	fs.StringVar(&listen, "listen", ":9120", "address to serve the metrics on")
+	fs.DurationVar(&interval, "interval", exporter.DefaultInterval, "interval between refreshes of the conditions")
+	fs.IntVar(&quota, "quota", 500, "requests per day the key allows, 0 if unlimited")
+	fs.IntVar(&perMin, "rate", 10, "requests per minute the key allows, 0 if unlimited")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if key == "" {
+		key = os.Getenv(keyEnv)
+	}
+
+	e, err := setup(key, locs, interval, quota, perMin)
+	if err != nil {
+		fmt.Fprintf(stderr, "wug-exporter: %s\n", err)
+		return 2
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", e)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintln(w, `<html><body><a href="/metrics">metrics</a></body></html>`)
+	})
+
+	server := &http.Server{Addr: listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go e.Run(ctx)
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdown)
+	}()
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(stderr, "wug-exporter: %s\n", err)
+		return 1
+	}
+	return 0
+}
+
+// setup returns the exporter of the locations.
+func setup(key string, locs locations, interval time.Duration, quota, perMin int) (*exporter.Exporter, error) {
+	if key == "" {
+		return nil, fmt.Errorf("no API key, use --key or $%s", keyEnv)
+	}
+
+	if len(locs) == 0 {
+		return nil, errors.New("no locations, use --location name=query")
+	}
+
+	var locations []exporter.Location
+	for _, l := range locs {
+		loc, err := parseLocation(key, l)
+		if err != nil {
+			return nil, err
+		}
+		locations = append(locations, loc)
+	}
+
+	w := newClient()
+	if perMin > 0 {
+		w.Limiter = rate.NewLimiter(rate.Every(time.Minute/time.Duration(perMin)), perMin)
+	}
+
+	e, err := exporter.New(w, locations)
+	if err != nil {
+		return nil, err
+	}
+	e.Interval = interval
+	e.Quota = quota
+	return e, nil
+}
+
+// queryTypes by the kind of a location query
+var queryTypes = map[string]wug.QueryType{
+	"zip":     wug.UsZip,
+	"pws":     wug.PwsID,
+	"airport": wug.AirportCode,
+	"ip":      wug.IPGeo,
+	"autoip":  wug.AutoIP,
+	"latlon":  wug.LatLong,
+	"city":    wug.UsStateCity,
+}
+
+// parseLocation parses a name=kind:query location, the name is the whole
+// value if no name is given.
+func parseLocation(key, value string) (exporter.Location, error) {
+	name, q, ok := strings.Cut(value, "=")
+	if !ok {
+		name, q = value, value
+	}
+
+	loc := exporter.Location{Name: strings.TrimSpace(name)}
+	kind, arg, _ := strings.Cut(q, ":")
+	queryType, ok := queryTypes[kind]
+	if !ok {
+		return loc, fmt.Errorf("location %q: unknown query %q", value, kind)
+	}
+
+	query, err := wug.ParseQuery(key, queryType, arg)
+	if err != nil {
+		return loc, fmt.Errorf("location %q: %w", value, err)
+	}
+	loc.Query = query
+	return loc, nil
+}
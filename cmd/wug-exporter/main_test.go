@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLocation(t *testing.T) {
+	var tests = []struct {
+		value, name, query string
+	}{
+		{"home=pws:KCASANFR70", "home", "/pws:KCASANFR70.json"},
+		{"zip:94101", "zip:94101", "/94101.json"},
+		{"sfo=airport:KSFO", "sfo", "/KSFO.json"},
+		{"park=latlon:37.77, -122.42", "park", "/37.77,-122.42.json"},
+		{"sf=city:CA/San Francisco", "sf", "/CA/San_Francisco.json"},
+		{"paris=city:France/Paris", "paris", "/France/Paris.json"},
+		{"here=autoip", "here", "/autoip.json"},
+	}
+
+	for _, tt := range tests {
+		loc, err := parseLocation("testkey", tt.value)
+		if err != nil {
+			t.Fatalf("%s: error parsing: %s\n", tt.value, err)
+		}
+
+		if loc.Name != tt.name || !strings.HasSuffix(loc.Query.Format("%s"), tt.query) {
+			t.Fatalf("%s: expected %s %s got %s %s\n", tt.value, tt.name, tt.query, loc.Name, loc.Query.Format("%s"))
+		}
+	}
+
+	for _, value := range []string{"home=moon:1", "park=latlon:37.77", "sf=city:San Francisco"} {
+		if _, err := parseLocation("testkey", value); err == nil {
+			t.Fatalf("%s: expected an error\n", value)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Setenv(keyEnv, "")
+
+	var stderr bytes.Buffer
+	if code := run(context.Background(), []string{"--location", "home=zip:94101"}, &stderr); code != 2 || !strings.Contains(stderr.String(), "no API key") {
+		t.Fatalf("expected a missing key error got %d %s\n", code, stderr.String())
+	}
+
+	stderr.Reset()
+	if code := run(context.Background(), []string{"--key", "testkey"}, &stderr); code != 2 || !strings.Contains(stderr.String(), "no locations") {
+		t.Fatalf("expected a missing locations error got %d %s\n", code, stderr.String())
+	}
+
+	e, err := setup("testkey", locations{"home=zip:94101"}, time.Minute, 500, 10)
+	if err != nil {
+		t.Fatalf("error setting up: %s\n", err)
+	}
+
+	if e.Quota != 500 || e.Interval != time.Minute || len(e.Locations) != 1 {
+		t.Fatalf("expected the flags to configure the exporter got %+v\n", e)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	stderr.Reset()
+	if code := run(ctx, []string{"--key", "testkey", "--location", "home=zip:94101", "--listen", "127.0.0.1:0", "--rate", "0"}, &stderr); code != 0 {
+		t.Fatalf("expected a clean shutdown got %d %s\n", code, stderr.String())
+	}
+}
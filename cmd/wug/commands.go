@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/wirepair/wug"
+	wugchart "github.com/wirepair/wug/chart"
+	"github.com/wirepair/wug/model"
+)
+
+// features maps the feature names of the raw command to request types
+var features = map[string]wug.RequestType{
+	"conditions":    wug.Cond,
+	"forecast":      wug.Fore,
+	"forecast10day": wug.ForeTenDay,
+	"hourly":        wug.Hour,
+	"hourly10day":   wug.HourTenDay,
+	"alerts":        wug.Alert,
+	"astronomy":     wug.Astro,
+}
+
+// flexNumber returns the field of a flex float.
+func flexNumber(name, unit string, f wug.FlexFloat) field {
+	if !f.Valid {
+		return field{name: name, unit: unit}
+	}
+	return field{name: name, unit: unit, value: f.Value}
+}
+
+func current(ctx context.Context, w *wug.Wug, q *wug.Query, opts *options, out io.Writer) error {
+	c, err := wug.GetFeature(ctx, w, wug.ConditionsFeature, q)
+	if err != nil {
+		return err
+	}
+
+	o := c.Observation()
+	s := opts.system
+	r := record{
+		text("location", o.Location.Name),
+		timestamp("time", o.Time),
+		text("condition", o.Condition),
+		measured("temperature", o.Temperature, s),
+		measured("feels like", o.FeelsLike, s),
+		measured("dew point", o.Dewpoint, s).as("dewpoint"),
+		number("humidity", "%", o.Humidity),
+		measured("wind", o.WindSpeed, s).as("wind_speed"),
+		measured("gust", o.WindGust, s).as("wind_gust"),
+		text("direction", o.WindDirectionName).as("wind_direction"),
+		measured("pressure", o.Pressure, s),
+		measured("visibility", o.Visibility, s),
+		number("uv index", "", o.UVIndex),
+		measured("precip today", o.PrecipToday, s),
+	}
+	return render(out, opts.format, []record{r}, false)
+}
+
+func forecast(ctx context.Context, w *wug.Wug, q *wug.Query, opts *options, out io.Writer) error {
+	if opts.days < 1 || opts.days > 10 {
+		return fmt.Errorf("--days must be between 1 and 10")
+	}
+
+	var days []model.DailyForecast
+	if opts.days > 4 {
+		f, err := wug.GetFeature(ctx, w, wug.ForecastTenDayFeature, q)
+		if err != nil {
+			return err
+		}
+		days = f.DailyForecasts()
+	} else {
+		f, err := wug.GetFeature(ctx, w, wug.ForecastFeature, q)
+		if err != nil {
+			return err
+		}
+		days = f.DailyForecasts()
+	}
+
+	if len(days) > opts.days {
+		days = days[:opts.days]
+	}
+
+	s := opts.system
+	records := make([]record, 0, len(days))
+	for _, d := range days {
+		day := d
+		records = append(records, record{
+			dateField("date", day, opts.format),
+			text("condition", day.Condition),
+			measured("high", day.High, s),
+			measured("low", day.Low, s),
+			number("precip chance", "%", day.PrecipProbability).as("pop"),
+			measured("precip", day.Precip, s),
+			measured("snow", day.Snow, s),
+			measured("wind", day.AveWind, s).as("ave_wind"),
+			measured("max wind", day.MaxWind, s),
+			number("humidity", "%", day.Humidity),
+		})
+	}
+	return render(out, opts.format, records, true)
+}
+
+// dateField returns the date of a forecast day, as a weekday and date in
+// text output.
+func dateField(name string, day model.DailyForecast, format string) field {
+	if day.Date.IsZero() {
+		return field{name: name}
+	}
+
+	if format == "text" {
+		return text(name, day.Date.Format("Mon Jan 2"))
+	}
+	return text(name, day.Date.Format("2006-01-02"))
+}
+
+func hourly(ctx context.Context, w *wug.Wug, q *wug.Query, opts *options, out io.Writer) error {
+	var points []model.HourlyPoint
+	if opts.tenDay {
+		h, err := wug.GetFeature(ctx, w, wug.HourlyTenDayFeature, q)
+		if err != nil {
+			return err
+		}
+		points = h.HourlyPoints()
+	} else {
+		h, err := wug.GetFeature(ctx, w, wug.HourlyFeature, q)
+		if err != nil {
+			return err
+		}
+		points = h.HourlyPoints()
+	}
+
+	s := opts.system
+	records := make([]record, 0, len(points))
+	for _, p := range points {
+		point := p
+		records = append(records, record{
+			timestamp("time", point.Time),
+			text("condition", point.Condition),
+			measured("temperature", point.Temperature, s),
+			measured("feels like", point.FeelsLike, s),
+			number("humidity", "%", point.Humidity),
+			measured("wind", point.WindSpeed, s).as("wind_speed"),
+			text("direction", point.WindDirectionName).as("wind_direction"),
+			number("cloud cover", "%", point.CloudCover),
+			number("precip chance", "%", point.PrecipProbability).as("pop"),
+			measured("precip", point.Precip, s),
+		})
+	}
+	return render(out, opts.format, records, true)
+}
+
+func chart(ctx context.Context, w *wug.Wug, q *wug.Query, opts *options, out io.Writer) error {
+	chartOpts := wugchart.Options{Width: opts.width, Height: opts.height, System: opts.system}
+	switch opts.color {
+	case "always":
+		chartOpts.Color = true
+	case "auto":
+		chartOpts.Color = wugchart.ColorEnabled(out)
+	case "never":
+	default:
+		return fmt.Errorf("unknown --color %q, expected auto, always or never", opts.color)
+	}
+
+	var forecasts []wug.HourlyForecast
+	if opts.tenDay {
+		h, err := wug.GetFeature(ctx, w, wug.HourlyTenDayFeature, q)
+		if err != nil {
+			return err
+		}
+		forecasts = h.Hourly
+	} else {
+		h, err := wug.GetFeature(ctx, w, wug.HourlyFeature, q)
+		if err != nil {
+			return err
+		}
+		forecasts = h.Hourly
+	}
+	return wugchart.Render(out, wugchart.Hours(forecasts), chartOpts)
+}
+
+func alerts(ctx context.Context, w *wug.Wug, q *wug.Query, opts *options, out io.Writer) error {
+	a, err := wug.GetFeature(ctx, w, wug.AlertsFeature, q)
+	if err != nil {
+		return err
+	}
+
+	if len(a.Alerts) == 0 && opts.format == "text" {
+		_, err := fmt.Fprintln(out, "no active alerts")
+		return err
+	}
+
+	records := make([]record, 0, len(a.Alerts))
+	for i := range a.Alerts {
+		alert := &a.Alerts[i]
+		issued, _ := alert.Issued()
+		expires, _ := alert.Expiry()
+		records = append(records, record{
+			text("description", alert.Description),
+			text("type", alert.Type),
+			text("significance", alert.Significance),
+			timestamp("issued", issued),
+			timestamp("expires", expires),
+		})
+	}
+	return render(out, opts.format, records, true)
+}
+
+func astronomy(ctx context.Context, w *wug.Wug, q *wug.Query, opts *options, out io.Writer) error {
+	a, err := wug.GetFeature(ctx, w, wug.AstronomyFeature, q)
+	if err != nil {
+		return err
+	}
+
+	moon := &a.MoonPhase
+	r := record{
+		text("sunrise", a.SunPhase.Sunrise.String()),
+		text("sunset", a.SunPhase.Sunset.String()),
+		text("moonrise", moon.Moonrise.String()),
+		text("moonset", moon.Moonset.String()),
+		text("moon phase", moon.PhaseofMoon),
+		flexNumber("illuminated", "%", moon.PercentIlluminated),
+		flexNumber("moon age", "days", moon.AgeOfMoon),
+	}
+	return render(out, opts.format, []record{r}, false)
+}
+
+func raw(ctx context.Context, w *wug.Wug, q *wug.Query, opts *options, out io.Writer) error {
+	if len(opts.args) != 1 {
+		return fmt.Errorf("raw needs one feature: %s", strings.Join(featureNames(), ", "))
+	}
+
+	requestType, ok := features[opts.args[0]]
+	if !ok {
+		return fmt.Errorf("unknown feature %q, expected one of %s", opts.args[0], strings.Join(featureNames(), ", "))
+	}
+
+	data, err := w.GetWithContext(ctx, requestType, q)
+	if err != nil {
+		return err
+	}
+
+	_, err = out.Write(data)
+	return err
+}
+
+func featureNames() []string {
+	names := make([]string, 0, len(features))
+	for name := range features {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,274 @@
+// Command wug queries the weather underground API from the command line.
+//
+//	wug current --city "CA/San Francisco"
+//	wug forecast --zip 94101 --days 10 --units metric
+//	wug hourly --latlon 37.77,-122.42 --format csv
+//	wug chart --ten-day --width 120
+//	wug raw --pws KCASANFR70 conditions
+//
+// The API key is read from --key, the WUGKEY environment variable or the key
+// of the config file, in that order.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/wirepair/wug"
+	wugchart "github.com/wirepair/wug/chart"
+	"github.com/wirepair/wug/units"
+)
+
+// keyEnv is the environment variable the API key is read from
+const keyEnv = "WUGKEY"
+
+// newClient returns the client commands use, replaced in tests
+var newClient = wug.NewWug
+
+// command runs a subcommand, writing its output to out
+type command func(ctx context.Context, w *wug.Wug, q *wug.Query, opts *options, out io.Writer) error
+
+var commands = map[string]command{
+	"current":   current,
+	"forecast":  forecast,
+	"hourly":    hourly,
+	"chart":     chart,
+	"alerts":    alerts,
+	"astronomy": astronomy,
+	"raw":       raw,
+}
+
+const usage = `usage: wug <command> [flags]
+
+commands:
+  current     current conditions
+  forecast    daily forecast, --days 10 for the ten day forecast
+  hourly      hourly forecast, --ten-day for ten days
+  chart       meteogram of the hourly forecast, --ten-day for ten days
+  alerts      active severe weather alerts
+  astronomy   sunrise, sunset and moon phase
+  raw         raw response of a feature, e.g. wug raw conditions
+
+run wug <command> -h for the flags of a command
+`
+
+// config is the optional config file
+type config struct {
+	Key      string `json:"key"`
+	Units    string `json:"units"`
+	Language string `json:"lang"`
+}
+
+// options of every command
+type options struct {
+	key, config     string
+	zip, pws        string
+	airport, latlon string
+	city, ip        string
+	autoip          bool
+	units, format   string
+	lang            string
+	days            int
+	tenDay          bool
+	width, height   int
+	color           string
+	system          units.System
+	args            []string
+}
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command line and returns the exit code.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "wug: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+
+	opts := &options{}
+	fs := flags(args[0], opts)
+	fs.SetOutput(stderr)
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	opts.args = fs.Args()
+
+	w, q, err := setup(opts)
+	if err != nil {
+		fmt.Fprintf(stderr, "wug: %s\n", err)
+		return 2
+	}
+
+	if err := cmd(ctx, w, q, opts, stdout); err != nil {
+		fmt.Fprintf(stderr, "wug: %s\n", strings.TrimPrefix(err.Error(), "wug: "))
+		return 1
+	}
+	return 0
+}
+
+func flags(name string, opts *options) *flag.FlagSet {
+	fs := flag.NewFlagSet("wug "+name, flag.ContinueOnError)
+	fs.StringVar(&opts.key, "key", "", "API key, defaults to $"+keyEnv+" or the config file")
+	fs.StringVar(&opts.config, "config", defaultConfig(), "config file with key, units and lang")
+	fs.StringVar(&opts.zip, "zip", "", "US zip code")
+	fs.StringVar(&opts.pws, "pws", "", "personal weather station id")
+	fs.StringVar(&opts.airport, "airport", "", "airport code")
+	fs.StringVar(&opts.latlon, "latlon", "", "latitude,longitude")
+	fs.StringVar(&opts.city, "city", "", "STATE/City for US cities or Country/City")
+	fs.StringVar(&opts.ip, "ip", "", "geolocate an IP address")
+	fs.BoolVar(&opts.autoip, "autoip", false, "geolocate this machine, the default location")
+	fs.StringVar(&opts.units, "units", "", "unit system: imperial, metric, si or uk")
+	fs.StringVar(&opts.format, "format", "text", "output format: text, json or csv")
+	fs.StringVar(&opts.lang, "lang", "", "language code, e.g. FR")
+	if name == "forecast" {
+		fs.IntVar(&opts.days, "days", 4, "number of days, up to 10")
+	}
+
+	if name == "hourly" || name == "chart" {
+		fs.BoolVar(&opts.tenDay, "ten-day", false, "ten day hourly forecast")
+	}
+
+	if name == "chart" {
+		fs.IntVar(&opts.width, "width", terminalWidth(), "width in characters")
+		fs.IntVar(&opts.height, "height", wugchart.DefaultHeight, "rows of the temperature chart")
+		fs.StringVar(&opts.color, "color", "auto", "colour output: auto, always or never")
+	}
+	return fs
+}
+
+// terminalWidth returns $COLUMNS, the width of the terminal as exported by
+// most shells, or the default width of a chart.
+func terminalWidth() int {
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return wugchart.DefaultWidth
+}
+
+// defaultConfig returns the path of the config file in the user config
+// directory.
+func defaultConfig() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "wug", "config.json")
+}
+
+// readConfig reads the config file, a missing file is an empty config.
+func readConfig(path string) (config, error) {
+	var c config
+	if path == "" {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+
+	if err != nil {
+		return c, err
+	}
+
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("reading config %s: %s", path, err)
+	}
+	return c, nil
+}
+
+// setup resolves the key, settings and location of the options.
+func setup(opts *options) (*wug.Wug, *wug.Query, error) {
+	c, err := readConfig(opts.config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key := firstOf(opts.key, os.Getenv(keyEnv), c.Key)
+	if key == "" {
+		return nil, nil, fmt.Errorf("no API key, use --key, $%s or the config file", keyEnv)
+	}
+
+	opts.system, err = units.ParseSystem(firstOf(opts.units, c.Units, units.Imperial.String()))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if opts.format != "text" && opts.format != "json" && opts.format != "csv" {
+		return nil, nil, fmt.Errorf("unknown format %q", opts.format)
+	}
+
+	w := newClient()
+	w.Units = opts.system
+	if lang := firstOf(opts.lang, c.Language); lang != "" {
+		if w.Settings.Language, err = wug.ParseLanguage(lang); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	q, err := query(key, opts)
+	return w, q, err
+}
+
+// query builds the query of the single location flag, autoip by default.
+func query(key string, opts *options) (*wug.Query, error) {
+	var queries []*wug.Query
+	for _, flag := range []struct {
+		name, value string
+		queryType   wug.QueryType
+	}{
+		{"zip", opts.zip, wug.UsZip},
+		{"pws", opts.pws, wug.PwsID},
+		{"airport", opts.airport, wug.AirportCode},
+		{"latlon", opts.latlon, wug.LatLong},
+		{"city", opts.city, wug.UsStateCity},
+		{"ip", opts.ip, wug.IPGeo},
+	} {
+		if flag.value == "" {
+			continue
+		}
+
+		q, err := wug.ParseQuery(key, flag.queryType, flag.value)
+		if err != nil {
+			return nil, fmt.Errorf("--%s: %s", flag.name, strings.TrimPrefix(err.Error(), "wug: "))
+		}
+		queries = append(queries, q)
+	}
+
+	if opts.autoip {
+		queries = append(queries, wug.NewQueryByAutoIP(key))
+	}
+
+	switch len(queries) {
+	case 0:
+		return wug.NewQueryByAutoIP(key), nil
+	case 1:
+		return queries[0], nil
+	}
+	return nil, fmt.Errorf("only one location may be given")
+}
+
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wirepair/wug"
+	"github.com/wirepair/wug/wugtest"
+)
+
+// newTestServer replaces the client of the commands with one of a fake
+// server accepting testkey.
+func newTestServer(t *testing.T) *wugtest.Server {
+	s := wugtest.NewServer()
+	s.SetKey("testkey")
+	s.SetClock(func() time.Time { return time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC) })
+	newClient = s.Wug
+	t.Cleanup(func() {
+		s.Close()
+		newClient = wug.NewWug
+	})
+	t.Setenv(keyEnv, "")
+	return s
+}
+
+func runCommand(t *testing.T, args ...string) (string, string, int) {
+	var stdout, stderr bytes.Buffer
+	args = append([]string{args[0], "--config", ""}, args[1:]...)
+	code := run(context.Background(), args, &stdout, &stderr)
+	return stdout.String(), stderr.String(), code
+}
+
+func TestCurrent(t *testing.T) {
+	newTestServer(t)
+
+	out, errOut, code := runCommand(t, "current", "--key", "testkey", "--city", "CA/San Francisco")
+	if code != 0 {
+		t.Fatalf("expected exit code 0 got %d: %s\n", code, errOut)
+	}
+
+	for _, want := range []string{"location:", "San Francisco", "temperature:", "°F", "mph"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s\n", want, out)
+		}
+	}
+
+	out, errOut, code = runCommand(t, "current", "--key", "testkey", "--units", "metric", "--format", "json")
+	if code != 0 {
+		t.Fatalf("expected exit code 0 got %d: %s\n", code, errOut)
+	}
+
+	var obs map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &obs); err != nil {
+		t.Fatalf("error decoding json output: %s\n", err)
+	}
+
+	if _, ok := obs["temperature_c"].(float64); !ok {
+		t.Fatalf("expected a numeric temperature_c got %v\n", obs["temperature_c"])
+	}
+
+	for _, key := range []string{"feels_like_c", "dewpoint_c", "humidity_pct", "wind_speed_kph", "wind_direction", "pressure_hpa", "uv_index", "precip_today_mm"} {
+		if _, ok := obs[key]; !ok {
+			t.Fatalf("expected %s in json output got %v\n", key, obs)
+		}
+	}
+}
+
+func TestForecast(t *testing.T) {
+	s := newTestServer(t)
+
+	out, errOut, code := runCommand(t, "forecast", "--key", "testkey", "--zip", "94101", "--format", "csv")
+	if code != 0 {
+		t.Fatalf("expected exit code 0 got %d: %s\n", code, errOut)
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatalf("error reading csv output: %s\n", err)
+	}
+
+	if len(rows) != 5 {
+		t.Fatalf("expected header and 4 days got %d rows\n", len(rows))
+	}
+
+	if rows[0][2] != "high (°F)" {
+		t.Fatalf("expected high (°F) header got %s\n", rows[0][2])
+	}
+
+	s.Reset()
+	out, errOut, code = runCommand(t, "forecast", "--key", "testkey", "--days", "7", "--format", "json")
+	if code != 0 {
+		t.Fatalf("expected exit code 0 got %d: %s\n", code, errOut)
+	}
+
+	var days []map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &days); err != nil {
+		t.Fatalf("error decoding json output: %s\n", err)
+	}
+
+	if len(days) != 7 {
+		t.Fatalf("expected 7 days got %d\n", len(days))
+	}
+
+	for _, key := range []string{"date", "high_f", "low_f", "pop_pct", "precip_in", "ave_wind_mph", "max_wind_mph"} {
+		if _, ok := days[0][key]; !ok {
+			t.Fatalf("expected %s in json output got %v\n", key, days[0])
+		}
+	}
+
+	requests := s.Requests()
+	if len(requests) != 1 || requests[0].Features[0] != "forecast10day" {
+		t.Fatalf("expected a single forecast10day request got %v\n", requests)
+	}
+
+	if _, _, code := runCommand(t, "forecast", "--key", "testkey", "--days", "11"); code != 1 {
+		t.Fatalf("expected exit code 1 for 11 days got %d\n", code)
+	}
+}
+
+func TestHourly(t *testing.T) {
+	newTestServer(t)
+
+	out, errOut, code := runCommand(t, "hourly", "--key", "testkey", "--latlon", "37.77,-122.42")
+	if code != 0 {
+		t.Fatalf("expected exit code 0 got %d: %s\n", code, errOut)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 37 {
+		t.Fatalf("expected header and 36 hours got %d lines\n", len(lines))
+	}
+
+	if !strings.Contains(lines[0], "temperature (°F)") {
+		t.Fatalf("expected temperature header got %s\n", lines[0])
+	}
+
+	out, _, code = runCommand(t, "hourly", "--key", "testkey", "--ten-day", "--format", "csv")
+	if code != 0 || strings.Count(out, "\n") != 241 {
+		t.Fatalf("expected header and 240 hours got %d lines\n", strings.Count(out, "\n"))
+	}
+}
+
+func TestAlerts(t *testing.T) {
+	s := newTestServer(t)
+
+	out, _, code := runCommand(t, "alerts", "--key", "testkey")
+	if code != 0 || !strings.Contains(out, "no active alerts") {
+		t.Fatalf("expected no active alerts got %d %s\n", code, out)
+	}
+
+	s.Handle("alerts", "", wugtest.JSON(map[string]interface{}{
+		"alerts": []map[string]string{{
+			"type":          "WIN",
+			"description":   "Wind Advisory",
+			"date_epoch":    "1782907200",
+			"expires_epoch": "1782993600",
+			"tz_long":       "America/Los_Angeles",
+			"significance":  "Y",
+		}},
+	}))
+
+	out, errOut, code := runCommand(t, "alerts", "--key", "testkey", "--format", "json")
+	if code != 0 {
+		t.Fatalf("expected exit code 0 got %d: %s\n", code, errOut)
+	}
+
+	var alerts []map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &alerts); err != nil {
+		t.Fatalf("error decoding json output: %s\n", err)
+	}
+
+	if len(alerts) != 1 || alerts[0]["description"] != "Wind Advisory" {
+		t.Fatalf("expected the wind advisory got %v\n", alerts)
+	}
+}
+
+func TestAstronomy(t *testing.T) {
+	newTestServer(t)
+
+	out, errOut, code := runCommand(t, "astronomy", "--key", "testkey")
+	if code != 0 {
+		t.Fatalf("expected exit code 0 got %d: %s\n", code, errOut)
+	}
+
+	for _, want := range []string{"sunrise:", "sunset:", "moon phase:"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s\n", want, out)
+		}
+	}
+}
+
+func TestRaw(t *testing.T) {
+	newTestServer(t)
+
+	out, errOut, code := runCommand(t, "raw", "--key", "testkey", "conditions")
+	if code != 0 {
+		t.Fatalf("expected exit code 0 got %d: %s\n", code, errOut)
+	}
+
+	if !strings.Contains(out, "current_observation") {
+		t.Fatalf("expected a raw conditions response got %s\n", out)
+	}
+
+	if _, _, code := runCommand(t, "raw", "--key", "testkey", "weather"); code != 1 {
+		t.Fatalf("expected exit code 1 for an unknown feature got %d\n", code)
+	}
+}
+
+func TestKey(t *testing.T) {
+	s := newTestServer(t)
+
+	if _, errOut, code := runCommand(t, "current"); code != 2 || !strings.Contains(errOut, "no API key") {
+		t.Fatalf("expected a missing key error got %d %s\n", code, errOut)
+	}
+
+	t.Setenv(keyEnv, "testkey")
+	if _, errOut, code := runCommand(t, "current"); code != 0 {
+		t.Fatalf("expected the key of the environment got %d %s\n", code, errOut)
+	}
+
+	t.Setenv(keyEnv, "")
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"key": "testkey", "units": "metric"}`), 0600); err != nil {
+		t.Fatalf("error writing config: %s\n", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run(context.Background(), []string{"current", "--config", path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected the key of the config got %d %s\n", code, stderr.String())
+	}
+
+	if !strings.Contains(stdout.String(), "°C") {
+		t.Fatalf("expected metric units of the config got %s\n", stdout.String())
+	}
+
+	_, errOut, code := runCommand(t, "current", "--key", "badkey")
+	if code != 1 || !strings.Contains(errOut, "keynotfound") {
+		t.Fatalf("expected a keynotfound error got %d %s\n", code, errOut)
+	}
+
+	s.SetQuota(0)
+	if _, errOut, code := runCommand(t, "current", "--key", "testkey"); code != 1 || !strings.Contains(errOut, "invalidkey") {
+		t.Fatalf("expected an invalidkey error got %d %s\n", code, errOut)
+	}
+}
+
+func TestLocationFlags(t *testing.T) {
+	newTestServer(t)
+
+	var tests = []struct {
+		args []string
+		code int
+	}{
+		{[]string{"--zip", "94101", "--pws", "KCASANFR70"}, 2},
+		{[]string{"--latlon", "37.77"}, 2},
+		{[]string{"--city", "San Francisco"}, 2},
+		{[]string{"--units", "cubits"}, 2},
+		{[]string{"--format", "xml"}, 2},
+		{[]string{"--airport", "KSFO"}, 0},
+		{[]string{"--city", "France/Paris"}, 0},
+	}
+
+	for _, tt := range tests {
+		args := append([]string{"current", "--key", "testkey"}, tt.args...)
+		if _, errOut, code := runCommand(t, args...); code != tt.code {
+			t.Fatalf("%v: expected exit code %d got %d %s\n", tt.args, tt.code, code, errOut)
+		}
+	}
+
+	if _, _, code := runCommand(t, "weather"); code != 2 {
+		t.Fatalf("expected exit code 2 for an unknown command got %d\n", code)
+	}
+}
+
+func TestChart(t *testing.T) {
+	newTestServer(t)
+
+	out, errOut, code := runCommand(t, "chart", "--key", "testkey", "--width", "60", "--units", "metric")
+	if code != 0 {
+		t.Fatalf("expected exit code 0 got %d: %s\n", code, errOut)
+	}
+
+	if !strings.HasPrefix(out, "Temp °C") || !strings.Contains(out, "|Wed 1") {
+		t.Fatalf("expected a metric meteogram got:\n%s\n", out)
+	}
+
+	if strings.Contains(out, "\x1b[") {
+		t.Fatalf("expected no colour when not writing to a terminal")
+	}
+
+	out, _, code = runCommand(t, "chart", "--key", "testkey", "--ten-day", "--color", "always")
+	if code != 0 || !strings.Contains(out, "\x1b[") {
+		t.Fatalf("expected a coloured meteogram got %d:\n%s\n", code, out)
+	}
+
+	if _, _, code := runCommand(t, "chart", "--key", "testkey", "--color", "rainbow"); code != 1 {
+		t.Fatalf("expected exit code 1 for an unknown colour mode got %d\n", code)
+	}
+}
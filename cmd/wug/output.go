@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/wirepair/wug/units"
+)
+
+// field is a named value of a record, value is a float64, string, time.Time
+// or nil when missing
+type field struct {
+	name   string
+	unit   string
+	value  interface{}
+	column string // JSON name without the unit suffix, the name in snake case if empty
+}
+
+// as returns the field with the JSON name of the archive column it matches.
+func (f field) as(column string) field {
+	f.column = column
+	return f
+}
+
+// unitSuffixes are the JSON name suffixes of units, as in the archive columns
+var unitSuffixes = map[string]string{
+	"°C":   "c",
+	"°F":   "f",
+	"km/h": "kph",
+	"mph":  "mph",
+	"m/s":  "ms",
+	"hPa":  "hpa",
+	"inHg": "inhg",
+	"km":   "km",
+	"mi":   "mi",
+	"mm":   "mm",
+	"in":   "in",
+	"%":    "pct",
+	"days": "days",
+}
+
+// key returns the JSON name of the field, snake case with the unit suffix,
+// e.g. precip_today_mm.
+func (f field) key() string {
+	key := f.column
+	if key == "" {
+		key = strings.ReplaceAll(f.name, " ", "_")
+	}
+
+	if suffix := unitSuffixes[f.unit]; suffix != "" {
+		key += "_" + suffix
+	}
+	return key
+}
+
+// record is one row of output
+type record []field
+
+// measured returns the field of a measurement in the unit system.
+func measured[M units.Measure](name string, m *M, system units.System) field {
+	var zero M
+	_, unit := zero.In(system)
+	if m == nil {
+		return field{name: name, unit: unit}
+	}
+
+	v, _ := (*m).In(system)
+	return field{name: name, unit: unit, value: v}
+}
+
+// number returns the field of an optional number.
+func number(name, unit string, v *float64) field {
+	if v == nil {
+		return field{name: name, unit: unit}
+	}
+	return field{name: name, unit: unit, value: *v}
+}
+
+// text returns the field of a string, empty strings are missing.
+func text(name, v string) field {
+	if v == "" {
+		return field{name: name}
+	}
+	return field{name: name, value: v}
+}
+
+// timestamp returns the field of a time, the zero time is missing.
+func timestamp(name string, t time.Time) field {
+	if t.IsZero() {
+		return field{name: name}
+	}
+	return field{name: name, value: t}
+}
+
+// format the value for text and csv output, empty when missing.
+func (f field) format(layout string) string {
+	switch v := f.value.(type) {
+	case float64:
+		return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+	case string:
+		return v
+	case time.Time:
+		return v.Format(layout)
+	}
+	return ""
+}
+
+// header returns the column name with its unit.
+func (f field) header() string {
+	if f.unit == "" {
+		return f.name
+	}
+	return f.name + " (" + f.unit + ")"
+}
+
+// render writes the records in the format. A single record (list false) is
+// written as a list of fields rather than a table.
+func render(out io.Writer, format string, records []record, list bool) error {
+	switch format {
+	case "json":
+		return renderJSON(out, records, list)
+	case "csv":
+		return renderCSV(out, records)
+	}
+	return renderText(out, records, list)
+}
+
+func renderText(out io.Writer, records []record, list bool) error {
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	if !list {
+		for _, r := range records {
+			for _, f := range r {
+				v := f.format("Mon Jan 2 15:04 MST")
+				if v != "" && f.unit != "" {
+					v += " " + f.unit
+				}
+				fmt.Fprintf(tw, "%s:\t%s\n", f.name, v)
+			}
+		}
+		return tw.Flush()
+	}
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	for i, f := range records[0] {
+		if i > 0 {
+			fmt.Fprint(tw, "\t")
+		}
+		fmt.Fprint(tw, f.header())
+	}
+	fmt.Fprintln(tw)
+
+	for _, r := range records {
+		for i, f := range r {
+			if i > 0 {
+				fmt.Fprint(tw, "\t")
+			}
+
+			v := f.format("Mon Jan 2 15:04")
+			if v == "" {
+				v = "-"
+			}
+			fmt.Fprint(tw, v)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+func renderCSV(out io.Writer, records []record) error {
+	cw := csv.NewWriter(out)
+	if len(records) > 0 {
+		header := make([]string, len(records[0]))
+		for i, f := range records[0] {
+			header[i] = f.header()
+		}
+		cw.Write(header)
+	}
+
+	for _, r := range records {
+		row := make([]string, len(r))
+		for i, f := range r {
+			row[i] = f.format(time.RFC3339)
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// MarshalJSON encodes the record as an object keeping the field order, with
+// the keys of the fields.
+func (r record) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range r {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		name, _ := json.Marshal(f.key())
+		buf.Write(name)
+		buf.WriteByte(':')
+
+		value := f.value
+		if v, ok := value.(float64); ok {
+			value = math.Round(v*100) / 100
+		}
+
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func renderJSON(out io.Writer, records []record, list bool) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if !list && len(records) == 1 {
+		return enc.Encode(records[0])
+	}
+
+	if records == nil {
+		records = []record{}
+	}
+	return enc.Encode(records)
+}
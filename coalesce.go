@@ -0,0 +1,95 @@
+package wug
+
+import (
+	"context"
+	"sync"
+)
+
+// call is an in flight upstream request shared by all of its waiters
+type call struct {
+	done    chan struct{}
+	value   interface{}
+	err     error
+	waiters int
+	taken   bool // a waiter got the value
+	cancel  context.CancelFunc
+}
+
+// flightGroup coalesces concurrent identical requests into a single call. The
+// zero value is ready to use.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// do runs fn once for all concurrent callers of the same key and returns its
+// result to each of them. shared is false for exactly one waiter of a
+// successful call, the others must not modify the value. fn is given a
+// context that keeps the values of the first caller but is only cancelled
+// once every waiter has given up, so one caller cancelling does not cancel
+// the others.
+//
+// fn calls keep before producing a result it would have to copy for other
+// waiters. keep reports whether the call has more than one waiter, if not
+// the call stops accepting waiters and later callers of key start a new call,
+// so the result only ever goes to the first.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(ctx context.Context, keep func() bool) (interface{}, error)) (value interface{}, shared bool, err error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+
+	c, ok := g.calls[key]
+	if !ok {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c = &call{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = c
+		keep := func() bool {
+			g.mu.Lock()
+			defer g.mu.Unlock()
+			if c.waiters > 1 {
+				return true
+			}
+
+			if g.calls[key] == c {
+				delete(g.calls, key)
+			}
+			return false
+		}
+
+		go func() {
+			c.value, c.err = fn(callCtx, keep)
+			g.forget(key, c)
+			cancel()
+			close(c.done)
+		}()
+	}
+	c.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		g.mu.Lock()
+		shared, c.taken = c.taken, true
+		g.mu.Unlock()
+		return c.value, shared, c.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		c.waiters--
+		if c.waiters == 0 && g.calls[key] == c {
+			delete(g.calls, key)
+			c.cancel()
+		}
+		g.mu.Unlock()
+		return nil, false, ctx.Err()
+	}
+}
+
+// forget removes the call so later requests for key start a new call.
+func (g *flightGroup) forget(key string, c *call) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.calls[key] == c {
+		delete(g.calls, key)
+	}
+}
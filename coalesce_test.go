@@ -0,0 +1,238 @@
+package wug
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newBlockingWug returns a Wug whose transport counts requests and blocks
+// until release is closed or the request is cancelled.
+func newBlockingWug(count *int64, release chan struct{}) *Wug {
+	w := NewWug()
+	w.Client = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		atomic.AddInt64(count, 1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			return nil, r.Context().Err()
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("data")),
+			Request:    r,
+		}, nil
+	})}
+	return w
+}
+
+// waitForWaiters blocks until the in flight call has n waiters.
+func waitForWaiters(t *testing.T, g *flightGroup, n int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		g.mu.Lock()
+		waiters := 0
+		for _, c := range g.calls {
+			waiters += c.waiters
+		}
+		g.mu.Unlock()
+
+		if waiters == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d waiters\n", n)
+}
+
+func TestCoalesce(t *testing.T) {
+	var count int64
+	release := make(chan struct{})
+	w := newBlockingWug(&count, release)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, err := w.GetRawConditions(NewQueryByUsZip("apikey", "90210"))
+			if err == nil && string(data) != "data" {
+				t.Errorf("unexpected data %s\n", data)
+			}
+			errs <- err
+		}()
+	}
+
+	waitForWaiters(t, &w.flights, 10)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error: %s\n", err)
+		}
+	}
+
+	if count != 1 {
+		t.Fatalf("expected 1 upstream request got %d\n", count)
+	}
+}
+
+func TestCoalesceCancel(t *testing.T) {
+	var count int64
+	release := make(chan struct{})
+	w := newBlockingWug(&count, release)
+	q := NewQueryByUsZip("apikey", "90210")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error)
+	go func() {
+		_, err := w.GetWithContext(ctx, Cond, q)
+		cancelled <- err
+	}()
+
+	result := make(chan error)
+	go func() {
+		_, err := w.GetWithContext(context.Background(), Cond, q)
+		result <- err
+	}()
+
+	waitForWaiters(t, &w.flights, 2)
+	cancel()
+	if err := <-cancelled; err != context.Canceled {
+		t.Fatalf("expected context.Canceled got %v\n", err)
+	}
+
+	close(release)
+	if err := <-result; err != nil {
+		t.Fatalf("remaining waiter got error: %s\n", err)
+	}
+
+	if count != 1 {
+		t.Fatalf("expected 1 upstream request got %d\n", count)
+	}
+}
+
+func TestCoalesceAllCancelled(t *testing.T) {
+	var count int64
+	w := newBlockingWug(&count, make(chan struct{}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := w.GetWithContext(ctx, Cond, NewQueryByUsZip("apikey", "90210"))
+		done <- err
+	}()
+
+	waitForWaiters(t, &w.flights, 1)
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("expected context.Canceled got %v\n", err)
+	}
+
+	w.flights.mu.Lock()
+	defer w.flights.mu.Unlock()
+	if len(w.flights.calls) != 0 {
+		t.Fatalf("expected abandoned call to be forgotten")
+	}
+}
+
+func TestCoalesceOwnValues(t *testing.T) {
+	var count int64
+	release := make(chan struct{})
+	w := NewWug()
+	w.Client = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		atomic.AddInt64(&count, 1)
+		<-release
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"hourly_forecast": [{"condition": "Clear"}]}`)),
+			Request:    r,
+		}, nil
+	})}
+
+	results := make(chan *Hourly, 5)
+	for i := 0; i < 5; i++ {
+		go func() {
+			hourly, err := w.GetHourly(NewQueryByUsZip("apikey", "90210"))
+			if err != nil {
+				t.Errorf("error getting hourly: %s\n", err)
+			}
+			results <- hourly
+		}()
+	}
+
+	var coalesced int64
+	w.Coalesced = func(requestType RequestType) {
+		if requestType == Hour {
+			atomic.AddInt64(&coalesced, 1)
+		}
+	}
+
+	waitForWaiters(t, &w.flights, 5)
+	close(release)
+
+	// modifying one result does not change the others
+	seen := make(map[*Hourly]bool)
+	for i := 0; i < 5; i++ {
+		hourly := <-results
+		if hourly == nil || seen[hourly] || len(hourly.Hourly) != 1 || hourly.Hourly[0].Condition != "Clear" {
+			t.Fatalf("expected an unmodified result of its own got %#v\n", hourly)
+		}
+		seen[hourly] = true
+		hourly.Hourly[0].Condition = "changed"
+	}
+
+	if count != 1 || atomic.LoadInt64(&coalesced) != 4 {
+		t.Fatalf("expected 1 upstream request and 4 coalesced got %d %d\n", count, coalesced)
+	}
+}
+
+func TestCoalesceKeep(t *testing.T) {
+	var g flightGroup
+	kept := make(chan bool, 1)
+	joinable := true
+	_, shared, err := g.do(context.Background(), "key", func(ctx context.Context, keep func() bool) (interface{}, error) {
+		kept <- keep()
+		g.mu.Lock()
+		_, joinable = g.calls["key"]
+		g.mu.Unlock()
+		return "value", nil
+	})
+	if err != nil || shared {
+		t.Fatalf("expected the value of its own got %v %v\n", shared, err)
+	}
+
+	// a lone waiter keeps nothing and stops the call from being joined
+	if <-kept || joinable {
+		t.Fatalf("expected a call with one waiter not to keep its result or be joined")
+	}
+
+	release := make(chan struct{})
+	go g.do(context.Background(), "key", func(ctx context.Context, keep func() bool) (interface{}, error) {
+		<-release
+		kept <- keep()
+		return "value", nil
+	})
+	waitForWaiters(t, &g, 1)
+
+	done := make(chan struct{})
+	go func() {
+		g.do(context.Background(), "key", nil)
+		close(done)
+	}()
+	waitForWaiters(t, &g, 2)
+	close(release)
+	<-done
+
+	if !<-kept {
+		t.Fatalf("expected a call with two waiters to keep its result")
+	}
+}
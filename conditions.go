@@ -2,6 +2,7 @@ package wug
 
 // Conditions current conditions
 type Conditions struct {
+	DecodeResult
 	Response struct {
 		Version        string `json:"version"`
 		TermsofService string `json:"termsofService"`
@@ -16,82 +17,82 @@ type Conditions struct {
 			Link  string `json:"link"`
 		} `json:"image"`
 		DisplayLocation struct {
-			Full           string `json:"full"`
-			City           string `json:"city"`
-			State          string `json:"state"`
-			StateName      string `json:"state_name"`
-			Country        string `json:"country"`
-			CountryIso3166 string `json:"country_iso3166"`
-			Zip            string `json:"zip"`
-			Magic          string `json:"magic"`
-			Wmo            string `json:"wmo"`
-			Latitude       string `json:"latitude"`
-			Longitude      string `json:"longitude"`
-			Elevation      string `json:"elevation"`
+			Full           string    `json:"full"`
+			City           string    `json:"city"`
+			State          string    `json:"state"`
+			StateName      string    `json:"state_name"`
+			Country        string    `json:"country"`
+			CountryIso3166 string    `json:"country_iso3166"`
+			Zip            string    `json:"zip"`
+			Magic          string    `json:"magic"`
+			Wmo            string    `json:"wmo"`
+			Latitude       FlexFloat `json:"latitude"`
+			Longitude      FlexFloat `json:"longitude"`
+			Elevation      FlexFloat `json:"elevation"`
 		} `json:"display_location"`
 		ObservationLocation struct {
-			Full           string `json:"full"`
-			City           string `json:"city"`
-			State          string `json:"state"`
-			Country        string `json:"country"`
-			CountryIso3166 string `json:"country_iso3166"`
-			Latitude       string `json:"latitude"`
-			Longitude      string `json:"longitude"`
-			Elevation      string `json:"elevation"`
+			Full           string    `json:"full"`
+			City           string    `json:"city"`
+			State          string    `json:"state"`
+			Country        string    `json:"country"`
+			CountryIso3166 string    `json:"country_iso3166"`
+			Latitude       FlexFloat `json:"latitude"`
+			Longitude      FlexFloat `json:"longitude"`
+			Elevation      string    `json:"elevation"` // with unit, e.g. "151 ft"
 		} `json:"observation_location"`
 		Estimated struct {
 		} `json:"estimated"`
-		StationID             string  `json:"station_id"`
-		ObservationTime       string  `json:"observation_time"`
-		ObservationTimeRfc822 string  `json:"observation_time_rfc822"`
-		ObservationEpoch      string  `json:"observation_epoch"`
-		LocalTimeRfc822       string  `json:"local_time_rfc822"`
-		LocalEpoch            string  `json:"local_epoch"`
-		LocalTzShort          string  `json:"local_tz_short"`
-		LocalTzLong           string  `json:"local_tz_long"`
-		LocalTzOffset         string  `json:"local_tz_offset"`
-		Weather               string  `json:"weather"`
-		TemperatureString     string  `json:"temperature_string"`
-		TempF                 float64 `json:"temp_f"`
-		TempC                 float64 `json:"temp_c"`
-		RelativeHumidity      string  `json:"relative_humidity"`
-		WindString            string  `json:"wind_string"`
-		WindDir               string  `json:"wind_dir"`
-		WindDegrees           int     `json:"wind_degrees"`
-		WindMph               float64 `json:"wind_mph"`
-		WindGustMph           string  `json:"wind_gust_mph"`
-		WindKph               float64 `json:"wind_kph"`
-		WindGustKph           string  `json:"wind_gust_kph"`
-		PressureMb            string  `json:"pressure_mb"`
-		PressureIn            string  `json:"pressure_in"`
-		PressureTrend         string  `json:"pressure_trend"`
-		DewpointString        string  `json:"dewpoint_string"`
-		DewpointF             int     `json:"dewpoint_f"`
-		DewpointC             int     `json:"dewpoint_c"`
-		HeatIndexString       string  `json:"heat_index_string"`
-		HeatIndexF            string  `json:"heat_index_f"`
-		HeatIndexC            string  `json:"heat_index_c"`
-		WindchillString       string  `json:"windchill_string"`
-		WindchillF            string  `json:"windchill_f"`
-		WindchillC            string  `json:"windchill_c"`
-		FeelslikeString       string  `json:"feelslike_string"`
-		FeelslikeF            string  `json:"feelslike_f"`
-		FeelslikeC            string  `json:"feelslike_c"`
-		VisibilityMi          string  `json:"visibility_mi"`
-		VisibilityKm          string  `json:"visibility_km"`
-		Solarradiation        string  `json:"solarradiation"`
-		UV                    string  `json:"UV"`
-		Precip1HrString       string  `json:"precip_1hr_string"`
-		Precip1HrIn           string  `json:"precip_1hr_in"`
-		Precip1HrMetric       string  `json:"precip_1hr_metric"`
-		PrecipTodayString     string  `json:"precip_today_string"`
-		PrecipTodayIn         string  `json:"precip_today_in"`
-		PrecipTodayMetric     string  `json:"precip_today_metric"`
-		Icon                  string  `json:"icon"`
-		IconURL               string  `json:"icon_url"`
-		ForecastURL           string  `json:"forecast_url"`
-		HistoryURL            string  `json:"history_url"`
-		ObURL                 string  `json:"ob_url"`
-		Nowcast               string  `json:"nowcast"`
+		StationID             string    `json:"station_id"`
+		ObservationTime       string    `json:"observation_time"`
+		ObservationTimeRfc822 string    `json:"observation_time_rfc822"`
+		ObservationEpoch      string    `json:"observation_epoch"`
+		LocalTimeRfc822       string    `json:"local_time_rfc822"`
+		LocalEpoch            string    `json:"local_epoch"`
+		LocalTzShort          string    `json:"local_tz_short"`
+		LocalTzLong           string    `json:"local_tz_long"`
+		LocalTzOffset         string    `json:"local_tz_offset"`
+		Weather               string    `json:"weather"`
+		TemperatureString     string    `json:"temperature_string"`
+		TempF                 FlexFloat `json:"temp_f"`
+		TempC                 FlexFloat `json:"temp_c"`
+		RelativeHumidity      FlexFloat `json:"relative_humidity"`
+		WindString            string    `json:"wind_string"`
+		WindDir               string    `json:"wind_dir"`
+		WindDegrees           FlexFloat `json:"wind_degrees"`
+		WindMph               FlexFloat `json:"wind_mph"`
+		WindGustMph           FlexFloat `json:"wind_gust_mph"`
+		WindKph               FlexFloat `json:"wind_kph"`
+		WindGustKph           FlexFloat `json:"wind_gust_kph"`
+		PressureMb            FlexFloat `json:"pressure_mb"`
+		PressureIn            FlexFloat `json:"pressure_in"`
+		PressureTrend         string    `json:"pressure_trend"`
+		DewpointString        string    `json:"dewpoint_string"`
+		DewpointF             FlexFloat `json:"dewpoint_f"`
+		DewpointC             FlexFloat `json:"dewpoint_c"`
+		HeatIndexString       string    `json:"heat_index_string"`
+		HeatIndexF            FlexFloat `json:"heat_index_f"`
+		HeatIndexC            FlexFloat `json:"heat_index_c"`
+		WindchillString       string    `json:"windchill_string"`
+		WindchillF            FlexFloat `json:"windchill_f"`
+		WindchillC            FlexFloat `json:"windchill_c"`
+		FeelslikeString       string    `json:"feelslike_string"`
+		FeelslikeF            FlexFloat `json:"feelslike_f"`
+		FeelslikeC            FlexFloat `json:"feelslike_c"`
+		VisibilityMi          FlexFloat `json:"visibility_mi"`
+		VisibilityKm          FlexFloat `json:"visibility_km"`
+		Solarradiation        FlexFloat `json:"solarradiation"`
+		UV                    FlexFloat `json:"UV"`
+		Precip1HrString       string    `json:"precip_1hr_string"`
+		Precip1HrIn           FlexFloat `json:"precip_1hr_in"`
+		Precip1HrMetric       FlexFloat `json:"precip_1hr_metric"`
+		PrecipTodayString     string    `json:"precip_today_string"`
+		PrecipTodayIn         FlexFloat `json:"precip_today_in"`
+		PrecipTodayMetric     FlexFloat `json:"precip_today_metric"`
+		Icon                  string    `json:"icon"`
+		IconURL               string    `json:"icon_url"`
+		ForecastURL           string    `json:"forecast_url"`
+		HistoryURL            string    `json:"history_url"`
+		ObURL                 string    `json:"ob_url"`
+		Nowcast               string    `json:"nowcast"`
 	} `json:"current_observation"`
 }
@@ -0,0 +1,71 @@
+package wug
+
+import (
+	"sort"
+	"strings"
+)
+
+// DailyView combines the simple forecast of a day with the narrative text
+// forecast of its day time and night time periods.
+type DailyView struct {
+	ForecastDay
+	Day   *TxtForecastDay // day time text, nil if the forecast starts at night
+	Night *TxtForecastDay // night time text, nil if not forecast
+}
+
+// IsNight returns true if the text forecast period is a night time period.
+// The icon is checked first since titles are localized.
+func (t *TxtForecastDay) IsNight() bool {
+	if _, night := t.IconType(); night {
+		return true
+	}
+
+	title := strings.ToLower(t.Title)
+	return strings.HasSuffix(title, " night") || title == "tonight"
+}
+
+// Days returns the simple forecast days joined with their day and night text
+// periods. When the text forecast starts with a night (Tonight) the first day
+// only has a Night.
+func (f *ForecastData) Days() []DailyView {
+	simple := f.Simpleforecast.Forecastday
+	days := make([]DailyView, len(simple))
+	for i := range simple {
+		days[i].ForecastDay = simple[i]
+	}
+
+	periods := make([]TxtForecastDay, len(f.Textforecast.TxtForecastday))
+	copy(periods, f.Textforecast.TxtForecastday)
+	sort.SliceStable(periods, func(i, j int) bool {
+		return periods[i].Period < periods[j].Period
+	})
+
+	offset := 0
+	if len(periods) > 0 && periods[0].IsNight() {
+		offset = 1
+	}
+
+	for i := range periods {
+		day := (i + offset) / 2
+		if day >= len(days) {
+			break
+		}
+
+		if (i+offset)%2 == 0 {
+			days[day].Day = &periods[i]
+		} else {
+			days[day].Night = &periods[i]
+		}
+	}
+	return days
+}
+
+// Days returns the forecast days joined with their text periods.
+func (f *Forecast) Days() []DailyView {
+	return f.Forecast.Days()
+}
+
+// Days returns the ten day forecast days joined with their text periods.
+func (f *ForecastTenDay) Days() []DailyView {
+	return f.Forecast.Days()
+}
@@ -0,0 +1,75 @@
+package wug
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDays(t *testing.T) {
+	var tests = []struct {
+		name    string
+		periods string
+		day     []string
+		night   []string
+	}{
+		{
+			"starts with day",
+			`[{"period": 0, "icon": "clear", "title": "Tuesday"}, {"period": 1, "icon": "nt_clear", "title": "Tuesday Night"},
+			  {"period": 2, "icon": "rain", "title": "Wednesday"}, {"period": 3, "icon": "nt_rain", "title": "Wednesday Night"}]`,
+			[]string{"Tuesday", "Wednesday"},
+			[]string{"Tuesday Night", "Wednesday Night"},
+		},
+		{
+			"starts with night",
+			`[{"period": 0, "icon": "partlycloudy", "icon_url": "http://icons.wxug.com/i/c/k/nt_partlycloudy.gif", "title": "Ce soir"},
+			  {"period": 1, "icon": "rain", "title": "Mercredi"}, {"period": 2, "icon": "nt_rain", "title": "Mercredi soir"}]`,
+			[]string{"", "Mercredi"},
+			[]string{"Ce soir", "Mercredi soir"},
+		},
+		{
+			"out of order",
+			`[{"period": 1, "title": "Tuesday Night"}, {"period": 0, "title": "Tuesday"}, {"period": 2, "title": "Wednesday"}]`,
+			[]string{"Tuesday", "Wednesday"},
+			[]string{"Tuesday Night", ""},
+		},
+		{
+			"more periods than days",
+			`[{"period": 0, "title": "Tonight"}, {"period": 1, "title": "Wednesday"}, {"period": 2, "title": "Wednesday Night"},
+			  {"period": 3, "title": "Thursday"}, {"period": 4, "title": "Thursday Night"}]`,
+			[]string{"", "Wednesday"},
+			[]string{"Tonight", "Wednesday Night"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forecast := &Forecast{}
+			data := `{"forecast": {"txt_forecast": {"forecastday": ` + tt.periods + `}, "simpleforecast": {"forecastday": [{"period": 1}, {"period": 2}]}}}`
+			if err := json.Unmarshal([]byte(data), forecast); err != nil {
+				t.Fatalf("error decoding forecast: %s\n", err)
+			}
+
+			days := forecast.Days()
+			if len(days) != 2 {
+				t.Fatalf("expected 2 days got %d\n", len(days))
+			}
+
+			for i, day := range days {
+				if day.Period != i+1 {
+					t.Fatalf("expected period %d got %d\n", i+1, day.Period)
+				}
+
+				if title(day.Day) != tt.day[i] || title(day.Night) != tt.night[i] {
+					t.Fatalf("day %d: expected %q/%q got %q/%q\n", i, tt.day[i], tt.night[i], title(day.Day), title(day.Night))
+				}
+			}
+		})
+	}
+}
+
+func title(t *TxtForecastDay) string {
+	if t == nil {
+		return ""
+	}
+	return t.Title
+}
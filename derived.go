@@ -0,0 +1,274 @@
+package wug
+
+import (
+	"github.com/wirepair/wug/meteo"
+	"github.com/wirepair/wug/units"
+)
+
+// measurements are the basic values derived values are computed from
+type measurements struct {
+	temp        units.Temperature
+	hasTemp     bool
+	dewpoint    units.Temperature
+	hasDewpoint bool
+	humidity    FlexFloat
+	wind        units.Speed
+	hasWind     bool
+	pressure    units.Pressure
+	hasPressure bool
+}
+
+func (c *Conditions) measurements() (m measurements) {
+	m.temp, m.hasTemp = c.Temperature()
+	m.dewpoint, m.hasDewpoint = c.DewpointTemp()
+	m.humidity = c.CurrentObservation.RelativeHumidity
+	m.wind, m.hasWind = c.WindSpeed()
+	m.pressure, m.hasPressure = c.Pressure()
+	return m
+}
+
+func (h *HourlyForecast) measurements() (m measurements) {
+	m.temp, m.hasTemp = h.Temperature()
+	m.dewpoint, m.hasDewpoint = h.DewpointTemp()
+	m.humidity = h.Humidity
+	m.wind, m.hasWind = h.WindSpeed()
+	m.pressure, m.hasPressure = h.Pressure()
+	return m
+}
+
+// estimate returns the reported value when ok, otherwise the computed one.
+func estimate[T any](reported T, ok bool, computed func() (T, bool)) (T, meteo.Source) {
+	if ok {
+		return reported, meteo.SourceAPI
+	}
+
+	if value, ok := computed(); ok {
+		return value, meteo.SourceComputed
+	}
+	var zero T
+	return zero, meteo.SourceNone
+}
+
+// relativeHumidity returns the reported humidity, or computes it from the
+// temperature and dew point.
+func (m measurements) relativeHumidity() (float64, bool) {
+	if m.humidity.Valid {
+		return m.humidity.Value, true
+	}
+
+	if m.hasTemp && m.hasDewpoint {
+		return meteo.RelativeHumidity(m.temp, m.dewpoint), true
+	}
+	return 0, false
+}
+
+func (m measurements) heatIndex() (units.Temperature, bool) {
+	rh, ok := m.relativeHumidity()
+	if !m.hasTemp || !ok || !meteo.HeatIndexApplies(m.temp) {
+		return units.Temperature(0), false
+	}
+	return meteo.HeatIndex(m.temp, rh), true
+}
+
+func (m measurements) windChill() (units.Temperature, bool) {
+	if !m.hasTemp || !m.hasWind || !meteo.WindChillApplies(m.temp, m.wind) {
+		return units.Temperature(0), false
+	}
+	return meteo.WindChill(m.temp, m.wind), true
+}
+
+func (m measurements) feelsLike() (units.Temperature, bool) {
+	rh, ok := m.relativeHumidity()
+	if !m.hasTemp || !ok || !m.hasWind {
+		return units.Temperature(0), false
+	}
+	return meteo.FeelsLike(m.temp, rh, m.wind), true
+}
+
+func (m measurements) apparentTemperature() (units.Temperature, bool) {
+	rh, ok := m.relativeHumidity()
+	if !m.hasTemp || !ok || !m.hasWind {
+		return units.Temperature(0), false
+	}
+	return meteo.ApparentTemperature(m.temp, rh, m.wind), true
+}
+
+func (m measurements) computedDewpoint() (units.Temperature, bool) {
+	if !m.hasTemp || !m.humidity.Valid || m.humidity.Value <= 0 {
+		return units.Temperature(0), false
+	}
+	return meteo.DewPoint(m.temp, m.humidity.Value), true
+}
+
+func (m measurements) computedHumidity() (float64, bool) {
+	if !m.hasTemp || !m.hasDewpoint {
+		return 0, false
+	}
+	return meteo.RelativeHumidity(m.temp, m.dewpoint), true
+}
+
+// dewpointEstimate returns the reported dew point, or computes it from the
+// temperature and humidity.
+func (m measurements) dewpointEstimate() (units.Temperature, bool) {
+	if m.hasDewpoint {
+		return m.dewpoint, true
+	}
+	return m.computedDewpoint()
+}
+
+func (m measurements) wetBulb() (units.Temperature, bool) {
+	rh, ok := m.relativeHumidity()
+	if !m.hasTemp || !ok {
+		return units.Temperature(0), false
+	}
+	return meteo.WetBulb(m.temp, rh), true
+}
+
+func (m measurements) humidex() (units.Temperature, bool) {
+	td, ok := m.dewpointEstimate()
+	if !m.hasTemp || !ok {
+		return units.Temperature(0), false
+	}
+	return meteo.Humidex(m.temp, td), true
+}
+
+func (m measurements) cloudBase() (units.Length, bool) {
+	td, ok := m.dewpointEstimate()
+	if !m.hasTemp || !ok {
+		return units.Length(0), false
+	}
+	return meteo.CloudBase(m.temp, td), true
+}
+
+func (m measurements) airDensity() (float64, bool) {
+	rh, ok := m.relativeHumidity()
+	if !m.hasTemp || !m.hasPressure || !ok {
+		return 0, false
+	}
+	return meteo.AirDensity(m.temp, m.pressure, rh), true
+}
+
+// HeatIndexEstimate returns the reported heat index, or computes it from the
+// temperature and humidity when it was not reported. Below 80°F the heat
+// index does not apply and meteo.SourceNone is returned.
+func (c *Conditions) HeatIndexEstimate() (units.Temperature, meteo.Source) {
+	reported, ok := c.HeatIndexTemp()
+	return estimate(reported, ok, c.measurements().heatIndex)
+}
+
+// WindchillEstimate returns the reported wind chill, or computes it from the
+// temperature and wind speed when it was not reported. Above 50°F or with
+// winds of 3 mph or less wind chill is not defined and meteo.SourceNone is
+// returned.
+func (c *Conditions) WindchillEstimate() (units.Temperature, meteo.Source) {
+	reported, ok := c.WindchillTemp()
+	return estimate(reported, ok, c.measurements().windChill)
+}
+
+// FeelsLikeEstimate returns the reported feels like temperature, or computes
+// it when it was not reported.
+func (c *Conditions) FeelsLikeEstimate() (units.Temperature, meteo.Source) {
+	reported, ok := c.FeelsLikeTemp()
+	return estimate(reported, ok, c.measurements().feelsLike)
+}
+
+// DewpointEstimate returns the reported dew point, or computes it from the
+// temperature and humidity when it was not reported.
+func (c *Conditions) DewpointEstimate() (units.Temperature, meteo.Source) {
+	reported, ok := c.DewpointTemp()
+	return estimate(reported, ok, c.measurements().computedDewpoint)
+}
+
+// HumidityEstimate returns the reported relative humidity, or computes it
+// from the temperature and dew point when it was not reported.
+func (c *Conditions) HumidityEstimate() (float64, meteo.Source) {
+	rh := c.CurrentObservation.RelativeHumidity
+	return estimate(rh.Value, rh.Valid, c.measurements().computedHumidity)
+}
+
+// ApparentTemperature computes the Steadman apparent temperature.
+func (c *Conditions) ApparentTemperature() (units.Temperature, bool) {
+	return c.measurements().apparentTemperature()
+}
+
+// WetBulb computes the wet bulb temperature.
+func (c *Conditions) WetBulb() (units.Temperature, bool) {
+	return c.measurements().wetBulb()
+}
+
+// Humidex computes the humidex.
+func (c *Conditions) Humidex() (units.Temperature, bool) {
+	return c.measurements().humidex()
+}
+
+// CloudBase estimates the height of the cloud base above the station.
+func (c *Conditions) CloudBase() (units.Length, bool) {
+	return c.measurements().cloudBase()
+}
+
+// AirDensity computes the air density in kg/m³. The reported pressure is
+// adjusted to sea level, so this is the density at sea level.
+func (c *Conditions) AirDensity() (float64, bool) {
+	return c.measurements().airDensity()
+}
+
+// HeatIndexEstimate returns the forecast heat index, or computes it from the
+// temperature and humidity when it was not forecast and the heat index
+// applies.
+func (h *HourlyForecast) HeatIndexEstimate() (units.Temperature, meteo.Source) {
+	reported, ok := h.HeatIndexTemp()
+	return estimate(reported, ok, h.measurements().heatIndex)
+}
+
+// WindchillEstimate returns the forecast wind chill, or computes it from the
+// temperature and wind speed when it was not forecast and wind chill is
+// defined.
+func (h *HourlyForecast) WindchillEstimate() (units.Temperature, meteo.Source) {
+	reported, ok := h.WindchillTemp()
+	return estimate(reported, ok, h.measurements().windChill)
+}
+
+// FeelsLikeEstimate returns the forecast feels like temperature, or computes
+// it when it was not forecast.
+func (h *HourlyForecast) FeelsLikeEstimate() (units.Temperature, meteo.Source) {
+	reported, ok := h.FeelsLikeTemp()
+	return estimate(reported, ok, h.measurements().feelsLike)
+}
+
+// DewpointEstimate returns the forecast dew point, or computes it from the
+// temperature and humidity when it was not forecast.
+func (h *HourlyForecast) DewpointEstimate() (units.Temperature, meteo.Source) {
+	reported, ok := h.DewpointTemp()
+	return estimate(reported, ok, h.measurements().computedDewpoint)
+}
+
+// HumidityEstimate returns the forecast relative humidity, or computes it
+// from the temperature and dew point when it was not forecast.
+func (h *HourlyForecast) HumidityEstimate() (float64, meteo.Source) {
+	return estimate(h.Humidity.Value, h.Humidity.Valid, h.measurements().computedHumidity)
+}
+
+// ApparentTemperature computes the Steadman apparent temperature.
+func (h *HourlyForecast) ApparentTemperature() (units.Temperature, bool) {
+	return h.measurements().apparentTemperature()
+}
+
+// WetBulb computes the wet bulb temperature.
+func (h *HourlyForecast) WetBulb() (units.Temperature, bool) {
+	return h.measurements().wetBulb()
+}
+
+// Humidex computes the humidex.
+func (h *HourlyForecast) Humidex() (units.Temperature, bool) {
+	return h.measurements().humidex()
+}
+
+// CloudBase estimates the height of the cloud base above ground.
+func (h *HourlyForecast) CloudBase() (units.Length, bool) {
+	return h.measurements().cloudBase()
+}
+
+// AirDensity computes the air density in kg/m³ at sea level pressure.
+func (h *HourlyForecast) AirDensity() (float64, bool) {
+	return h.measurements().airDensity()
+}
@@ -0,0 +1,87 @@
+package wug
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/wirepair/wug/meteo"
+)
+
+func TestConditionsDerived(t *testing.T) {
+	data := []byte(`{"current_observation": {"temp_f": 90.0, "temp_c": "NA", "relative_humidity": "50%", "dewpoint_f": "NA", "dewpoint_c": "NA", "wind_mph": 5, "pressure_mb": "1013.25", "heat_index_f": "NA", "heat_index_c": "NA", "windchill_f": "NA", "windchill_c": "NA", "feelslike_f": "96", "feelslike_c": "NA"}}`)
+	c := &Conditions{}
+	if err := json.Unmarshal(data, c); err != nil {
+		t.Fatalf("error decoding conditions: %s\n", err)
+	}
+
+	hi, source := c.HeatIndexEstimate()
+	if source != meteo.SourceComputed || math.Abs(hi.Fahrenheit()-95) > 1 {
+		t.Fatalf("expected computed heat index of 95 got %v %s\n", hi.Fahrenheit(), source)
+	}
+
+	feels, source := c.FeelsLikeEstimate()
+	if source != meteo.SourceAPI || feels.Fahrenheit() != 96 {
+		t.Fatalf("expected reported feels like of 96 got %v %s\n", feels.Fahrenheit(), source)
+	}
+
+	dewpoint, source := c.DewpointEstimate()
+	if source != meteo.SourceComputed || math.Abs(dewpoint.Fahrenheit()-69.1) > 0.5 {
+		t.Fatalf("expected computed dew point got %v %s\n", dewpoint.Fahrenheit(), source)
+	}
+
+	if rh, source := c.HumidityEstimate(); source != meteo.SourceAPI || rh != 50 {
+		t.Fatalf("expected reported humidity got %v %s\n", rh, source)
+	}
+
+	// wind chill is not defined at 90F
+	if _, source := c.WindchillEstimate(); source != meteo.SourceNone {
+		t.Fatalf("expected no wind chill at 90F got %s\n", source)
+	}
+
+	calm := &Conditions{}
+	calm.CurrentObservation.TempF = FlexFloat{Value: 20, Valid: true}
+	calm.CurrentObservation.WindMph = FlexFloat{Value: 2, Valid: true}
+	if _, source := calm.WindchillEstimate(); source != meteo.SourceNone {
+		t.Fatalf("expected no wind chill in calm air got %s\n", source)
+	}
+
+	if base, ok := c.CloudBase(); !ok || math.Abs(base.Meters()-1460) > 20 {
+		t.Fatalf("unexpected cloud base %v %v\n", base.Meters(), ok)
+	}
+
+	if density, ok := c.AirDensity(); !ok || density < 1.1 || density > 1.2 {
+		t.Fatalf("unexpected air density %v %v\n", density, ok)
+	}
+}
+
+func TestHourlyDerived(t *testing.T) {
+	data := []byte(`{"temp": {"english": "0", "metric": "-18"}, "dewpoint": {"english": "-10", "metric": "-23"}, "wspd": {"english": "15", "metric": "24"}, "humidity": "", "windchill": {"english": "-9999", "metric": "-9999"}, "heatindex": {"english": "-9999", "metric": "-9999"}, "feelslike": {"english": "-19", "metric": "-28"}, "mslp": {"english": "", "metric": ""}}`)
+	h := &HourlyForecast{}
+	if err := json.Unmarshal(data, h); err != nil {
+		t.Fatalf("error decoding hourly forecast: %s\n", err)
+	}
+
+	wc, source := h.WindchillEstimate()
+	if source != meteo.SourceComputed || math.Abs(wc.Celsius()-(-28.5)) > 1 {
+		t.Fatalf("expected computed wind chill got %v %s\n", wc.Celsius(), source)
+	}
+
+	if rh, source := h.HumidityEstimate(); source != meteo.SourceComputed || rh < 60 || rh > 70 {
+		t.Fatalf("expected computed humidity got %v %s\n", rh, source)
+	}
+
+	if _, ok := h.AirDensity(); ok {
+		t.Fatalf("expected no air density without pressure\n")
+	}
+
+	// the heat index does not apply in the cold
+	if _, source := h.HeatIndexEstimate(); source != meteo.SourceNone {
+		t.Fatalf("expected no heat index at 0F got %s\n", source)
+	}
+
+	empty := &HourlyForecast{}
+	if _, source := empty.HeatIndexEstimate(); source != meteo.SourceNone {
+		t.Fatalf("expected no heat index got %s\n", source)
+	}
+}
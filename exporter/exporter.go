@@ -0,0 +1,271 @@
+// Package exporter exposes the current conditions of weather underground
+// locations as Prometheus metrics. Conditions are refreshed in the
+// background and scrapes are served from the last refresh, so scrapes never
+// cause API requests.
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/wirepair/wug"
+	"github.com/wirepair/wug/model"
+)
+
+// DefaultInterval between refreshes of the conditions
+const DefaultInterval = 5 * time.Minute
+
+// Location is a query whose conditions are exported
+type Location struct {
+	Name  string // location label, the query if empty
+	Query *wug.Query
+}
+
+// Exporter refreshes the conditions of its locations and serves them as
+// metrics
+type Exporter struct {
+	Locations []Location
+	Interval  time.Duration // between refreshes, DefaultInterval if 0
+	Quota     int           // requests per day the key allows, 0 if unknown
+
+	w   *wug.Wug
+	now func() time.Time
+
+	mu          sync.Mutex
+	samples     []sample // by index of Locations
+	requests    int
+	errors      map[string]int // by error type
+	coalesced   int            // requests served by an identical in flight request
+	day         string         // UTC date requests are counted against the quota
+	today       int            // requests of day
+	lastRefresh time.Time
+}
+
+// sample is the last refresh of a location
+type sample struct {
+	obs     model.Observation
+	ok      bool  // obs holds an observation
+	err     error // error of the last refresh
+	updated time.Time
+}
+
+// New returns an exporter of the locations. The client is instrumented to
+// count its requests and the requests it coalesced. Locations must have
+// distinct labels.
+func New(w *wug.Wug, locations []Location) (*Exporter, error) {
+	seen := make(map[string]bool)
+	for _, loc := range locations {
+		name := loc.label()
+		if seen[name] {
+			return nil, fmt.Errorf("exporter: duplicate location %q", name)
+		}
+		seen[name] = true
+	}
+
+	e := &Exporter{
+		Locations: locations,
+		w:         w,
+		now:       time.Now,
+		samples:   make([]sample, len(locations)),
+		errors:    make(map[string]int),
+	}
+	w.Middleware = append(w.Middleware, e.count)
+	coalesced := w.Coalesced
+	w.Coalesced = func(requestType wug.RequestType) {
+		if coalesced != nil {
+			coalesced(requestType)
+		}
+		e.mu.Lock()
+		e.coalesced++
+		e.mu.Unlock()
+	}
+	return e, nil
+}
+
+// label returns the location label, the name or the query if it has none.
+func (loc Location) label() string {
+	if loc.Name != "" {
+		return loc.Name
+	}
+	return loc.Query.String()
+}
+
+// count is the middleware counting requests that reach the API.
+func (e *Exporter) count(next wug.Handler) wug.Handler {
+	return func(req *wug.Request) (*http.Response, error) {
+		e.mu.Lock()
+		e.requests++
+		if day := e.now().UTC().Format("2006-01-02"); day != e.day {
+			e.day, e.today = day, 0
+		}
+		e.today++
+		e.mu.Unlock()
+		return next(req)
+	}
+}
+
+// Run refreshes the conditions every Interval until ctx is done, starting
+// with an immediate refresh.
+func (e *Exporter) Run(ctx context.Context) error {
+	interval := e.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		e.Refresh(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh requests the conditions of every location. A failed location keeps
+// its last observation and is reported as down until it succeeds again.
+func (e *Exporter) Refresh(ctx context.Context) {
+	for i, loc := range e.Locations {
+		c, err := wug.GetFeature(ctx, e.w, wug.ConditionsFeature, loc.Query)
+
+		e.mu.Lock()
+		e.samples[i].err = err
+		if err != nil {
+			e.errors[errorType(err)]++
+		} else {
+			e.samples[i] = sample{obs: c.Observation(), ok: true, updated: e.now()}
+		}
+		e.mu.Unlock()
+	}
+
+	e.mu.Lock()
+	e.lastRefresh = e.now()
+	e.mu.Unlock()
+}
+
+// apiErrorTypes are the error types of the API used as labels, others are
+// counted as api_other so the server can not add label values
+var apiErrorTypes = map[string]bool{
+	"keynotfound":     true,
+	"invalidkey":      true,
+	"querynotfound":   true,
+	"unknownfeature":  true,
+	"invalidfeatures": true,
+}
+
+// errorType returns the error label of a failed request, one of a fixed set.
+func errorType(err error) string {
+	var apiErr *wug.APIError
+	var statusErr *wug.StatusError
+	var netErr net.Error
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &apiErr):
+		if apiErrorTypes[apiErr.Type] {
+			return apiErr.Type
+		}
+		return "api_other"
+	case errors.As(err, &statusErr):
+		if statusErr.StatusCode >= 400 && statusErr.StatusCode < 600 {
+			return "status_" + strconv.Itoa(statusErr.StatusCode/100) + "xx"
+		}
+		return "status_other"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, wug.ErrResponseTooLarge):
+		return "too_large"
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return "decode"
+	}
+	return "network"
+}
+
+// ServeHTTP serves the metrics in the Prometheus text format.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	e.WriteMetrics(w)
+}
+
+// WriteMetrics writes the metrics of the last refresh and of the exporter in
+// the Prometheus text format.
+func (e *Exporter) WriteMetrics(out io.Writer) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	mw := &metricWriter{w: out}
+	up := make([]metric, len(e.Locations))
+	for i, loc := range e.Locations {
+		up[i] = metric{labels: loc.labels(), value: 0}
+		if e.samples[i].ok && e.samples[i].err == nil {
+			up[i].value = 1
+		}
+	}
+	mw.family("wug_up", "Whether the last refresh of the location succeeded.", "gauge", up)
+
+	var stations []metric
+	for i, loc := range e.Locations {
+		if e.samples[i].ok {
+			stations = append(stations, metric{labels: append(loc.labels(), label{"station_id", e.samples[i].obs.Location.StationID}), value: 1})
+		}
+	}
+	mw.family("wug_station_info", "Station of the last observation of the location.", "gauge", stations)
+
+	for _, g := range gauges {
+		var metrics []metric
+		for i, loc := range e.Locations {
+			if !e.samples[i].ok {
+				continue
+			}
+
+			if v, ok := g.value(&e.samples[i].obs); ok {
+				metrics = append(metrics, metric{labels: loc.labels(), value: v})
+			}
+		}
+		mw.family(g.name, g.help, "gauge", metrics)
+	}
+
+	mw.family("wug_exporter_requests_total", "Requests made to the API.", "counter", []metric{{value: float64(e.requests)}})
+
+	var errs []metric
+	for _, t := range sortedKeys(e.errors) {
+		errs = append(errs, metric{labels: []label{{"type", t}}, value: float64(e.errors[t])})
+	}
+	mw.family("wug_exporter_errors_total", "Failed refreshes of locations by error type.", "counter", errs)
+	mw.family("wug_exporter_cache_hits_total", "Requests served by an identical in flight request instead of the API.", "counter", []metric{{value: float64(e.coalesced)}})
+
+	if e.Quota > 0 {
+		today := e.today
+		if e.day != e.now().UTC().Format("2006-01-02") {
+			today = 0
+		}
+		mw.family("wug_exporter_quota_remaining", "Requests left of the daily quota of the key, the day is in UTC.", "gauge", []metric{{value: float64(max(0, e.Quota-today))}})
+	}
+
+	if e.w.Limiter != nil {
+		mw.family("wug_exporter_limiter_tokens", "Requests the client limiter allows without waiting.", "gauge", []metric{{value: e.w.Limiter.Tokens()}})
+	}
+
+	if !e.lastRefresh.IsZero() {
+		mw.family("wug_exporter_last_refresh_timestamp_seconds", "When the last refresh finished.", "gauge", []metric{{value: float64(e.lastRefresh.UnixNano()) / 1e9}})
+	}
+	return mw.err
+}
+
+// labels returns the labels of the series of a location.
+func (loc Location) labels() []label {
+	return []label{{"location", loc.label()}}
+}
@@ -0,0 +1,225 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wirepair/wug"
+	"github.com/wirepair/wug/wugtest"
+	"golang.org/x/time/rate"
+)
+
+func newTestExporter(t *testing.T) (*Exporter, *wugtest.Server) {
+	s := wugtest.NewServer()
+	t.Cleanup(s.Close)
+	s.SetKey("testkey")
+	s.SetLocation("pws:KCASANFR70", wugtest.DefaultLocation)
+
+	e, err := New(s.Wug(), []Location{
+		{Name: "home", Query: wug.NewQueryByUsZip("testkey", "94101")},
+		{Query: wug.NewQueryByPwsID("testkey", "KCASANFR70")},
+	})
+	if err != nil {
+		t.Fatalf("error creating exporter: %s\n", err)
+	}
+	return e, s
+}
+
+func metrics(t *testing.T, e *Exporter) string {
+	var b strings.Builder
+	if err := e.WriteMetrics(&b); err != nil {
+		t.Fatalf("error writing metrics: %s\n", err)
+	}
+	return b.String()
+}
+
+func TestRefresh(t *testing.T) {
+	e, s := newTestExporter(t)
+
+	// nothing is requested until the first refresh
+	out := metrics(t, e)
+	if len(s.Requests()) != 0 {
+		t.Fatalf("expected scrapes not to request the API")
+	}
+
+	if !strings.Contains(out, `wug_up{location="home"} 0`) || !strings.Contains(out, `wug_up{location="pws:KCASANFR70"} 0`) || strings.Contains(out, "wug_temperature_celsius") {
+		t.Fatalf("expected locations to be down before a refresh got:\n%s\n", out)
+	}
+
+	e.Refresh(context.Background())
+	out = metrics(t, e)
+	if len(s.Requests()) != 2 {
+		t.Fatalf("expected a request per location got %d\n", len(s.Requests()))
+	}
+
+	for _, want := range []string{
+		`# TYPE wug_temperature_celsius gauge`,
+		`wug_up{location="home"} 1`,
+		`wug_up{location="pws:KCASANFR70"} 1`,
+		`wug_station_info{location="home",station_id="WUGTEST"} 1`,
+		`wug_exporter_requests_total 2`,
+		`wug_exporter_cache_hits_total 0`,
+		`wug_exporter_last_refresh_timestamp_seconds`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in:\n%s\n", want, out)
+		}
+	}
+
+	for _, name := range []string{"relative_humidity_ratio", "pressure_pascals", "wind_speed_meters_per_second", "wind_gust_meters_per_second",
+		"wind_direction_degrees", "precipitation_today_meters", "uv_index", "visibility_meters"} {
+		if !strings.Contains(out, "\nwug_"+name+"{") {
+			t.Fatalf("expected wug_%s in:\n%s\n", name, out)
+		}
+	}
+
+	// scrapes are served from the refresh
+	metrics(t, e)
+	if len(s.Requests()) != 2 {
+		t.Fatalf("expected scrapes not to request the API got %d requests\n", len(s.Requests()))
+	}
+}
+
+func TestErrors(t *testing.T) {
+	e, s := newTestExporter(t)
+	e.Refresh(context.Background())
+
+	s.SetKey("otherkey")
+	e.Refresh(context.Background())
+	s.SetKey("testkey")
+	s.Handle("conditions", "", wugtest.StatusResponse(http.StatusBadGateway))
+	e.Refresh(context.Background())
+
+	out := metrics(t, e)
+	for _, want := range []string{
+		`wug_exporter_errors_total{type="keynotfound"} 2`,
+		`wug_exporter_errors_total{type="status_5xx"} 2`,
+		`wug_up{location="home"} 0`,
+		`wug_temperature_celsius{location="home"}`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in:\n%s\n", want, out)
+		}
+	}
+}
+
+func TestDuplicateLocations(t *testing.T) {
+	_, err := New(wug.NewWug(), []Location{
+		{Query: wug.NewQueryByUsZip("testkey", "94101")},
+		{Name: "94101", Query: wug.NewQueryByPwsID("testkey", "KCASANFR70")},
+	})
+	if err == nil || !strings.Contains(err.Error(), `duplicate location "94101"`) {
+		t.Fatalf("expected a duplicate location error got %v\n", err)
+	}
+}
+
+func TestCacheHits(t *testing.T) {
+	w := wug.NewWug()
+	var chained int
+	w.Coalesced = func(wug.RequestType) { chained++ }
+	e, err := New(w, []Location{{Query: wug.NewQueryByUsZip("testkey", "94101")}})
+	if err != nil {
+		t.Fatalf("error creating exporter: %s\n", err)
+	}
+	w.Coalesced(wug.Cond)
+	w.Coalesced(wug.Cond)
+	if out := metrics(t, e); !strings.Contains(out, "wug_exporter_cache_hits_total 2\n") {
+		t.Fatalf("expected 2 cache hits got:\n%s\n", out)
+	}
+	if chained != 2 {
+		t.Fatalf("expected the previous hook to be called 2 times got %d\n", chained)
+	}
+}
+
+func TestErrorType(t *testing.T) {
+	var tests = []struct {
+		err  error
+		want string
+	}{
+		{&wug.APIError{Type: "querynotfound"}, "querynotfound"},
+		{&wug.APIError{Type: "newtype"}, "api_other"},
+		{fmt.Errorf("get: %w", &wug.StatusError{StatusCode: 503}), "status_5xx"},
+		{&wug.StatusError{StatusCode: 404}, "status_4xx"},
+		{&wug.StatusError{StatusCode: 302}, "status_other"},
+		{context.DeadlineExceeded, "timeout"},
+		{context.Canceled, "canceled"},
+		{wug.ErrResponseTooLarge, "too_large"},
+		{errors.New("connection refused"), "network"},
+	}
+
+	for _, tt := range tests {
+		if got := errorType(tt.err); got != tt.want {
+			t.Fatalf("%v: expected %s got %s\n", tt.err, tt.want, got)
+		}
+	}
+}
+
+func TestQuota(t *testing.T) {
+	e, _ := newTestExporter(t)
+	e.Quota = 500
+	now := time.Date(2026, 7, 1, 23, 0, 0, 0, time.UTC)
+	e.now = func() time.Time { return now }
+	e.w.Limiter = rate.NewLimiter(rate.Every(time.Second), 10)
+
+	e.Refresh(context.Background())
+	out := metrics(t, e)
+	if !strings.Contains(out, "wug_exporter_quota_remaining 498") || !strings.Contains(out, "wug_exporter_limiter_tokens") {
+		t.Fatalf("expected 498 requests left and the limiter tokens in:\n%s\n", out)
+	}
+
+	// the quota resets with the UTC day
+	now = now.Add(2 * time.Hour)
+	if out := metrics(t, e); !strings.Contains(out, "wug_exporter_quota_remaining 500") {
+		t.Fatalf("expected the quota to reset in:\n%s\n", out)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	e, _ := newTestExporter(t)
+	e.Interval = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- e.Run(ctx) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+			t.Fatalf("expected the prometheus content type got %s\n", rec.Header().Get("Content-Type"))
+		}
+
+		if strings.Contains(rec.Body.String(), "wug_temperature_celsius{") {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("expected Run to refresh the conditions")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("expected context.Canceled got %v\n", err)
+	}
+}
+
+func TestMetricWriter(t *testing.T) {
+	var b strings.Builder
+	mw := &metricWriter{w: &b}
+	mw.family("test_metric", "A \\ help\nline.", "gauge", []metric{{labels: []label{{"name", "a \"quoted\"\nvalue\\"}}, value: 1.5}})
+	mw.family("test_empty", "Not written.", "gauge", nil)
+
+	want := "# HELP test_metric A \\\\ help\\nline.\n# TYPE test_metric gauge\ntest_metric{name=\"a \\\"quoted\\\"\\nvalue\\\\\"} 1.5\n"
+	if b.String() != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s\n", want, b.String())
+	}
+}
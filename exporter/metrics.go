@@ -0,0 +1,144 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/wirepair/wug/model"
+	"github.com/wirepair/wug/units"
+)
+
+// gauge is a metric of an observation
+type gauge struct {
+	name, help string
+	value      func(o *model.Observation) (float64, bool)
+}
+
+// optional returns the value of an optional measurement.
+func optional[M any](m *M, convert func(M) float64) (float64, bool) {
+	if m == nil {
+		return 0, false
+	}
+	return convert(*m), true
+}
+
+func identity(v float64) float64 { return v }
+
+func precipitationMeters(p units.Precipitation) float64 { return p.Millimeters() / 1000 }
+
+// gauges of an observation, in base units
+var gauges = []gauge{
+	{"wug_temperature_celsius", "Observed temperature.", func(o *model.Observation) (float64, bool) {
+		return optional(o.Temperature, units.Temperature.Celsius)
+	}},
+	{"wug_dewpoint_celsius", "Observed dew point.", func(o *model.Observation) (float64, bool) {
+		return optional(o.Dewpoint, units.Temperature.Celsius)
+	}},
+	{"wug_feels_like_celsius", "Reported feels like temperature.", func(o *model.Observation) (float64, bool) {
+		return optional(o.FeelsLike, units.Temperature.Celsius)
+	}},
+	{"wug_relative_humidity_ratio", "Observed relative humidity from 0 to 1.", func(o *model.Observation) (float64, bool) {
+		return optional(o.Humidity, func(h float64) float64 { return h / 100 })
+	}},
+	{"wug_pressure_pascals", "Observed pressure.", func(o *model.Observation) (float64, bool) {
+		return optional(o.Pressure, units.Pressure.Pascals)
+	}},
+	{"wug_wind_speed_meters_per_second", "Observed wind speed.", func(o *model.Observation) (float64, bool) {
+		return optional(o.WindSpeed, units.Speed.MetersPerSecond)
+	}},
+	{"wug_wind_gust_meters_per_second", "Observed wind gust speed.", func(o *model.Observation) (float64, bool) {
+		return optional(o.WindGust, units.Speed.MetersPerSecond)
+	}},
+	{"wug_wind_direction_degrees", "Direction the wind blows from.", func(o *model.Observation) (float64, bool) {
+		return optional(o.WindDirection, identity)
+	}},
+	{"wug_precipitation_last_hour_meters", "Precipitation of the last hour.", func(o *model.Observation) (float64, bool) {
+		return optional(o.Precip1Hr, precipitationMeters)
+	}},
+	{"wug_precipitation_today_meters", "Precipitation since midnight.", func(o *model.Observation) (float64, bool) {
+		return optional(o.PrecipToday, precipitationMeters)
+	}},
+	{"wug_uv_index", "Observed UV index.", func(o *model.Observation) (float64, bool) {
+		return optional(o.UVIndex, identity)
+	}},
+	{"wug_visibility_meters", "Observed visibility.", func(o *model.Observation) (float64, bool) {
+		return optional(o.Visibility, units.Length.Meters)
+	}},
+	{"wug_observation_timestamp_seconds", "When the observation was made.", func(o *model.Observation) (float64, bool) {
+		return float64(o.Time.Unix()), !o.Time.IsZero()
+	}},
+}
+
+// label of a metric
+type label struct {
+	name, value string
+}
+
+// metric is a sample of a metric family
+type metric struct {
+	labels []label
+	value  float64
+}
+
+// metricWriter writes metric families in the Prometheus text format, keeping
+// the first error
+type metricWriter struct {
+	w   io.Writer
+	err error
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+// family writes the help, type and samples of a metric family, families
+// without samples are left out.
+func (mw *metricWriter) family(name, help, kind string, metrics []metric) {
+	if mw.err != nil || len(metrics) == 0 {
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, helpEscaper.Replace(help), name, kind)
+	for _, m := range metrics {
+		b.WriteString(name)
+		if len(m.labels) > 0 {
+			b.WriteByte('{')
+			for i, l := range m.labels {
+				if i > 0 {
+					b.WriteByte(',')
+				}
+				fmt.Fprintf(&b, `%s="%s"`, l.name, labelEscaper.Replace(l.value))
+			}
+			b.WriteByte('}')
+		}
+		b.WriteByte(' ')
+		b.WriteString(formatValue(m.value))
+		b.WriteByte('\n')
+	}
+	_, mw.err = io.WriteString(mw.w, b.String())
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
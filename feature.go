@@ -0,0 +1,100 @@
+package wug
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxResponseSize is the largest response body read when
+// Wug.MaxResponseSize is not set.
+const DefaultMaxResponseSize = 8 << 20
+
+// ErrResponseTooLarge is returned when a response body exceeds the maximum
+// response size.
+var ErrResponseTooLarge = errors.New("wug: response exceeds maximum response size")
+
+// Feature ties a RequestType to the type its response decodes into. Adding a
+// typed feature only requires declaring its Feature.
+type Feature[T any] struct {
+	Type RequestType
+}
+
+// The typed features
+var (
+	ConditionsFeature     = Feature[Conditions]{Type: Cond}
+	ForecastFeature       = Feature[Forecast]{Type: Fore}
+	ForecastTenDayFeature = Feature[ForecastTenDay]{Type: ForeTenDay}
+	HourlyFeature         = Feature[Hourly]{Type: Hour}
+	HourlyTenDayFeature   = Feature[HourlyTenDay]{Type: HourTenDay}
+	AlertsFeature         = Feature[Alerts]{Type: Alert}
+	AstronomyFeature      = Feature[Astronomy]{Type: Astro}
+)
+
+// GetFeature requests the feature for the query and decodes the response body
+// as it streams in. When Wug.Lenient is set the body is decoded leniently and
+// the warnings are set on the DecodeWarnings of the result. Concurrent
+// identical requests are coalesced into one upstream request, but every
+// caller gets its own value.
+func GetFeature[T any](ctx context.Context, w *Wug, feature Feature[T], query *Query) (*T, error) {
+	lenient := w.Lenient
+	kind := fmt.Sprintf("%T lenient=%t", (*T)(nil), lenient)
+	value, err := w.request(ctx, feature.Type, query, kind, func(body io.Reader) (interface{}, error) {
+		result := new(T)
+		if !lenient {
+			if err := json.NewDecoder(body).Decode(result); err != nil {
+				return nil, err
+			}
+			return result, nil
+		}
+
+		warnings, err := decodeLenient(body, result)
+		if err != nil {
+			return nil, err
+		}
+
+		if setter, ok := interface{}(result).(decodeWarningSetter); ok {
+			setter.setDecodeWarnings(warnings)
+		}
+		return result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*T), nil
+}
+
+// limitedReader reads up to max bytes and then fails with
+// ErrResponseTooLarge if the underlying reader has more data.
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func newLimitedReader(r io.Reader, max int64) *limitedReader {
+	return &limitedReader{r: r, remaining: max}
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		var probe [1]byte
+		for {
+			n, err := l.r.Read(probe[:])
+			if n > 0 {
+				return 0, ErrResponseTooLarge
+			}
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
@@ -0,0 +1,66 @@
+package wug
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// newStaticWug returns a Wug whose transport always responds with body.
+func newStaticWug(body string) *Wug {
+	w := NewWug()
+	w.Client = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(body)),
+			Request:    r,
+		}, nil
+	})}
+	return w
+}
+
+func TestGetFeature(t *testing.T) {
+	w := newStaticWug(`{"response": {"features": {"hourly": 1}}, "hourly_forecast": [{"FCTTIME": {"hour": "13"}, "temp": {"english": "66", "metric": "19"}}]}`)
+	hourly, err := w.GetHourly(NewQueryByUsZip("apikey", "90210"))
+	if err != nil {
+		t.Fatalf("error getting hourly: %s\n", err)
+	}
+
+	if hourly.Response.Features.Hourly != 1 || len(hourly.Hourly) != 1 || hourly.Hourly[0].Temp.Metric.Value != 19 {
+		t.Fatalf("unexpected hourly %#v\n", hourly)
+	}
+
+	// raw and typed requests for the same url do not share results
+	data, err := w.GetRawHourly(NewQueryByUsZip("apikey", "90210"))
+	if err != nil || len(data) == 0 {
+		t.Fatalf("error getting raw hourly: %v\n", err)
+	}
+
+	if _, err := newStaticWug(`{"response": `).GetConditions(NewQueryByAutoIP("apikey")); err == nil {
+		t.Fatalf("expected error decoding truncated response")
+	}
+}
+
+func TestMaxResponseSize(t *testing.T) {
+	body := `{"response": {"version": "0.1"}}`
+	w := newStaticWug(body)
+	w.MaxResponseSize = int64(len(body))
+	if _, err := GetFeature(context.Background(), w, ConditionsFeature, NewQueryByAutoIP("apikey")); err != nil {
+		t.Fatalf("unexpected error at exact size: %s\n", err)
+	}
+
+	if _, err := w.GetRawConditions(NewQueryByAutoIP("apikey")); err != nil {
+		t.Fatalf("unexpected raw error at exact size: %s\n", err)
+	}
+
+	w.MaxResponseSize = int64(len(body)) - 1
+	if _, err := w.GetConditions(NewQueryByAutoIP("apikey")); err != ErrResponseTooLarge {
+		t.Fatalf("expected ErrResponseTooLarge got %v\n", err)
+	}
+
+	if _, err := w.GetRawConditions(NewQueryByAutoIP("apikey")); err != ErrResponseTooLarge {
+		t.Fatalf("expected ErrResponseTooLarge for raw got %v\n", err)
+	}
+}
@@ -0,0 +1,89 @@
+package wug
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// FlexFloat is a measurement that weather underground may send as a number,
+// a numeric string or a sentinel for missing data ("NA", "-9999", "--" or an
+// empty string). Valid is false when the measurement is missing.
+type FlexFloat struct {
+	Value float64
+	Valid bool
+}
+
+// sentinel values weather underground uses for missing measurements
+var flexSentinels = map[string]bool{
+	"":      true,
+	"NA":    true,
+	"N/A":   true,
+	"--":    true,
+	"-":     true,
+	"-999":  true,
+	"-9999": true,
+	"-9998": true,
+}
+
+// UnmarshalJSON decodes a number, numeric string, null or sentinel. A trailing
+// % is allowed in strings, e.g. relative_humidity "65%".
+func (f *FlexFloat) UnmarshalJSON(data []byte) error {
+	*f = FlexFloat{}
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	raw := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+		raw = strings.TrimSuffix(strings.TrimSpace(raw), "%")
+	}
+
+	if flexSentinels[raw] {
+		return nil
+	}
+
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return err
+	}
+
+	if value == -999 || value == -9999 || value == -9998 || math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil
+	}
+
+	f.Value = value
+	f.Valid = true
+	return nil
+}
+
+// MarshalJSON encodes the value as a number, or null when it is not valid.
+func (f FlexFloat) MarshalJSON() ([]byte, error) {
+	if !f.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(f.Value)
+}
+
+// Or returns the value, or def when it is not valid.
+func (f FlexFloat) Or(def float64) float64 {
+	if !f.Valid {
+		return def
+	}
+	return f.Value
+}
+
+// String returns the value formatted as the API would, or NA when it is not
+// valid.
+func (f FlexFloat) String() string {
+	if !f.Valid {
+		return "NA"
+	}
+	return strconv.FormatFloat(f.Value, 'f', -1, 64)
+}
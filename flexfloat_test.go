@@ -0,0 +1,80 @@
+package wug
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFlexFloat(t *testing.T) {
+	var tests = []struct {
+		input string
+		value float64
+		valid bool
+	}{
+		{`12.5`, 12.5, true},
+		{`-3`, -3, true},
+		{`"29.92"`, 29.92, true},
+		{`" 7 "`, 7, true},
+		{`"65%"`, 65, true},
+		{`"0"`, 0, true},
+		{`"NA"`, 0, false},
+		{`"N/A"`, 0, false},
+		{`"-9999"`, 0, false},
+		{`-9999`, 0, false},
+		{`"-999"`, 0, false},
+		{`"--"`, 0, false},
+		{`""`, 0, false},
+		{`null`, 0, false},
+		{`"NaN"`, 0, false},
+	}
+
+	for _, tt := range tests {
+		var f FlexFloat
+		if err := json.Unmarshal([]byte(tt.input), &f); err != nil {
+			t.Fatalf("error decoding %s: %s\n", tt.input, err)
+		}
+
+		if f.Value != tt.value || f.Valid != tt.valid {
+			t.Fatalf("%s: expected %v %v got %v %v\n", tt.input, tt.value, tt.valid, f.Value, f.Valid)
+		}
+	}
+
+	var f FlexFloat
+	for _, input := range []string{`"abc"`, `true`, `{}`} {
+		if err := json.Unmarshal([]byte(input), &f); err == nil {
+			t.Fatalf("expected error decoding %s\n", input)
+		}
+	}
+}
+
+func TestFlexFloatMarshal(t *testing.T) {
+	data, err := json.Marshal([]FlexFloat{{Value: 1.5, Valid: true}, {}})
+	if err != nil {
+		t.Fatalf("error encoding: %s\n", err)
+	}
+
+	if string(data) != `[1.5,null]` {
+		t.Fatalf("unexpected encoding %s\n", data)
+	}
+
+	if (FlexFloat{}).Or(4) != 4 || (FlexFloat{Value: 2, Valid: true}).String() != "2" || (FlexFloat{}).String() != "NA" {
+		t.Fatalf("unexpected Or/String results")
+	}
+}
+
+func TestFlexFloatConditions(t *testing.T) {
+	data := []byte(`{"current_observation": {"temp_f": 66.3, "relative_humidity": "65%", "wind_gust_mph": "NA", "pressure_mb": "1015", "dewpoint_f": 54, "UV": "-1", "precip_1hr_in": "-999.00", "feelslike_f": "--"}}`)
+	conditions := &Conditions{}
+	if err := json.Unmarshal(data, conditions); err != nil {
+		t.Fatalf("error decoding conditions: %s\n", err)
+	}
+
+	obs := conditions.CurrentObservation
+	if !obs.TempF.Valid || obs.RelativeHumidity.Value != 65 || obs.WindGustMph.Valid || obs.PressureMb.Value != 1015 || obs.DewpointF.Value != 54 {
+		t.Fatalf("unexpected observation %#v\n", obs)
+	}
+
+	if obs.UV.Value != -1 || obs.Precip1HrIn.Valid || obs.FeelslikeF.Valid {
+		t.Fatalf("unexpected observation %#v\n", obs)
+	}
+}
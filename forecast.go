@@ -23,57 +23,57 @@ type ForecastDay struct {
 	} `json:"date"`
 	Period int `json:"period"`
 	High   struct {
-		Fahrenheit string `json:"fahrenheit"`
-		Celsius    string `json:"celsius"`
+		Fahrenheit FlexFloat `json:"fahrenheit"`
+		Celsius    FlexFloat `json:"celsius"`
 	} `json:"high"`
 	Low struct {
-		Fahrenheit string `json:"fahrenheit"`
-		Celsius    string `json:"celsius"`
+		Fahrenheit FlexFloat `json:"fahrenheit"`
+		Celsius    FlexFloat `json:"celsius"`
 	} `json:"low"`
-	Conditions string `json:"conditions"`
-	Icon       string `json:"icon"`
-	IconURL    string `json:"icon_url"`
-	Skyicon    string `json:"skyicon"`
-	Pop        int    `json:"pop"`
+	Conditions string    `json:"conditions"`
+	Icon       string    `json:"icon"`
+	IconURL    string    `json:"icon_url"`
+	Skyicon    string    `json:"skyicon"`
+	Pop        FlexFloat `json:"pop"`
 	QpfAllday  struct {
-		In float64 `json:"in"`
-		Mm int     `json:"mm"`
+		In FlexFloat `json:"in"`
+		Mm FlexFloat `json:"mm"`
 	} `json:"qpf_allday"`
 	QpfDay struct {
-		In float64 `json:"in"`
-		Mm int     `json:"mm"`
+		In FlexFloat `json:"in"`
+		Mm FlexFloat `json:"mm"`
 	} `json:"qpf_day"`
 	QpfNight struct {
-		In float64 `json:"in"`
-		Mm int     `json:"mm"`
+		In FlexFloat `json:"in"`
+		Mm FlexFloat `json:"mm"`
 	} `json:"qpf_night"`
 	SnowAllday struct {
-		In float64 `json:"in"`
-		Cm float64 `json:"cm"`
+		In FlexFloat `json:"in"`
+		Cm FlexFloat `json:"cm"`
 	} `json:"snow_allday"`
 	SnowDay struct {
-		In float64 `json:"in"`
-		Cm float64 `json:"cm"`
+		In FlexFloat `json:"in"`
+		Cm FlexFloat `json:"cm"`
 	} `json:"snow_day"`
 	SnowNight struct {
-		In float64 `json:"in"`
-		Cm float64 `json:"cm"`
+		In FlexFloat `json:"in"`
+		Cm FlexFloat `json:"cm"`
 	} `json:"snow_night"`
 	Maxwind struct {
-		Mph     int    `json:"mph"`
-		Kph     int    `json:"kph"`
-		Dir     string `json:"dir"`
-		Degrees int    `json:"degrees"`
+		Mph     FlexFloat `json:"mph"`
+		Kph     FlexFloat `json:"kph"`
+		Dir     string    `json:"dir"`
+		Degrees FlexFloat `json:"degrees"`
 	} `json:"maxwind"`
 	Avewind struct {
-		Mph     int    `json:"mph"`
-		Kph     int    `json:"kph"`
-		Dir     string `json:"dir"`
-		Degrees int    `json:"degrees"`
+		Mph     FlexFloat `json:"mph"`
+		Kph     FlexFloat `json:"kph"`
+		Dir     string    `json:"dir"`
+		Degrees FlexFloat `json:"degrees"`
 	} `json:"avewind"`
-	Avehumidity int `json:"avehumidity"`
-	Maxhumidity int `json:"maxhumidity"`
-	Minhumidity int `json:"minhumidity"`
+	Avehumidity FlexFloat `json:"avehumidity"`
+	Maxhumidity FlexFloat `json:"maxhumidity"`
+	Minhumidity FlexFloat `json:"minhumidity"`
 }
 
 // TxtForecastDay text representation of a day of forecast information
@@ -106,6 +106,7 @@ type ForecastData struct {
 
 // Forecast current forecast
 type Forecast struct {
+	DecodeResult
 	Response struct {
 		Version        string `json:"version"`
 		TermsofService string `json:"termsofService"`
@@ -118,6 +119,7 @@ type Forecast struct {
 
 // ForecastTenDay the ten day forecast
 type ForecastTenDay struct {
+	DecodeResult
 	Response struct {
 		Version        string `json:"version"`
 		TermsofService string `json:"termsofService"`
@@ -0,0 +1,208 @@
+package wug_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wirepair/wug"
+	"github.com/wirepair/wug/wugtest"
+)
+
+// fuzzMaxResponseSize keeps fuzzed responses from allocating unbounded memory
+const fuzzMaxResponseSize = 1 << 16
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// newFuzzWug returns a client answering every request with body and passing
+// the request url to seen.
+func newFuzzWug(body []byte, seen func(*url.URL)) *wug.Wug {
+	w := wug.NewWug()
+	w.MaxResponseSize = fuzzMaxResponseSize
+	w.Client = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if seen != nil {
+			seen(r.URL)
+		}
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(string(body))),
+			Request:    r,
+		}, nil
+	})}
+	return w
+}
+
+// addResponseSeeds adds generated responses of every feature and payloads the
+// API is known to send as seeds. Generated forecasts are kept short, large
+// seeds slow the fuzzer down to a crawl.
+func addResponseSeeds(f *testing.F) {
+	now := time.Date(2017, 4, 25, 12, 0, 0, 0, time.UTC)
+	loc := wugtest.DefaultLocation
+	f.Add(wugtest.JSON(wugtest.GenerateConditions(loc, now)).Body)
+	f.Add(wugtest.JSON(&wug.Forecast{Forecast: wugtest.GenerateForecast(loc, now, 2)}).Body)
+	f.Add(wugtest.JSON(&wug.HourlyTenDay{Hourly: wugtest.GenerateHourly(loc, now, 3)}).Body)
+
+	f.Add(wugtest.ErrorResponse(wugtest.ErrorKeyNotFound, "this key does not exist").Body)
+	f.Add(wugtest.Ambiguous(wugtest.Result{Name: "San Francisco", City: "San Francisco", State: "CA", Country: "US"}).Body)
+	f.Add([]byte(`{"current_observation": {"temp_f": "NA", "temp_c": "-9999", "relative_humidity": "65%", "wind_degrees": "N/A", "observation_epoch": "abc", "local_tz_long": "Nowhere/Unknown"}}`))
+	f.Add([]byte(`{"hourly_forecast": [{"FCTTIME": {"epoch": "-1", "year": "2017", "mon": "13"}, "temp": {"english": 1e400}, "fctcode": "99"}]}`))
+	f.Add([]byte(`{"forecast": {"txt_forecast": {"forecastday": [{"period": -1, "title": "Tonight"}]}, "simpleforecast": {"forecastday": [{"high": {"celsius": []}}]}}}`))
+	f.Add([]byte(`null`))
+	f.Add([]byte(``))
+}
+
+// FuzzResponses feeds arbitrary responses through every typed getter, strict
+// and lenient, and through the conversions of the decoded responses.
+func FuzzResponses(f *testing.F) {
+	addResponseSeeds(f)
+	f.Fuzz(func(t *testing.T, body []byte) {
+		q := wug.NewQueryByAutoIP("key")
+		for _, lenient := range []bool{false, true} {
+			w := newFuzzWug(body, nil)
+			w.Lenient = lenient
+
+			conditions, err := w.GetConditions(q)
+			checkSize(t, body, err)
+			if err == nil {
+				conditions.Observation()
+				conditions.IconType()
+				conditions.HeatIndexEstimate()
+				conditions.AirDensity()
+			}
+
+			forecast, err := w.GetForecast(q)
+			checkSize(t, body, err)
+			if err == nil {
+				forecast.Days()
+				forecast.DailyForecasts()
+				forecast.DailyWeather()
+			}
+
+			forecastTenDay, err := w.GetForecastTenDay(q)
+			checkSize(t, body, err)
+			if err == nil {
+				forecastTenDay.Days()
+				forecastTenDay.DailyWeather().HeatingDegreeDays(0)
+			}
+
+			hourly, err := w.GetHourly(q)
+			checkSize(t, body, err)
+			if err == nil {
+				hourly.HourlyPoints()
+				hourly.Series(wug.FieldWindDirection).Daily(wug.AggregateMean)
+			}
+
+			hourlyTenDay, err := w.GetHourlyTenDay(q)
+			checkSize(t, body, err)
+			if err == nil {
+				hourlyTenDay.HourlyPoints()
+				hourlyTenDay.HourlyWeather().ChillHours()
+			}
+		}
+	})
+}
+
+// checkSize fails if a response over the size limit was decoded. Invalid
+// responses may fail before reaching the limit.
+func checkSize(t *testing.T, body []byte, err error) {
+	if len(body) > fuzzMaxResponseSize && err == nil {
+		t.Fatalf("expected a too large error for %d bytes\n", len(body))
+	}
+}
+
+// FuzzLenient checks the lenient decoder accepts everything the strict one
+// does.
+func FuzzLenient(f *testing.F) {
+	addResponseSeeds(f)
+	f.Fuzz(func(t *testing.T, body []byte) {
+		for _, v := range []func() interface{}{
+			func() interface{} { return &wug.Conditions{} },
+			func() interface{} { return &wug.Forecast{} },
+			func() interface{} { return &wug.HourlyTenDay{} },
+		} {
+			if err := json.Unmarshal(body, v()); err != nil {
+				continue
+			}
+
+			if _, err := wug.UnmarshalLenient(body, v()); err != nil {
+				t.Fatalf("lenient decode failed where strict succeeded: %s\n", err)
+			}
+		}
+	})
+}
+
+// FuzzQueries builds every query type from arbitrary input and checks the
+// request url parses and stays within the /q/ path.
+func FuzzQueries(f *testing.F) {
+	f.Add("key", "CA", "San Francisco")
+	f.Add("key", "Schweiz", "Zürich")
+	f.Add("key", "..", "..")
+	f.Add("../key", "a/b", "c?d#e")
+	f.Add("key", "%2F%2E%2E", "%zz")
+	f.Add("", "", "")
+	f.Add("key", "37.776289", "-122.395234")
+	f.Add("k\x00ey", "\xff\xfe", "Å")
+
+	f.Fuzz(func(t *testing.T, key, a, b string) {
+		queries := []*wug.Query{
+			wug.NewQueryByPwsID(key, a),
+			wug.NewQueryByUsStateCity(key, a, b),
+			wug.NewQueryByUsZip(key, a),
+			wug.NewQueryByCountryCity(key, a, b),
+			wug.NewQueryByLatLong(key, a, b),
+			wug.NewQueryByAirportCode(key, a),
+			wug.NewQueryByAutoIP(key),
+			wug.NewQueryByIPGeo(key, a),
+		}
+
+		for _, q := range queries {
+			var seen *url.URL
+			w := newFuzzWug([]byte("{}"), func(u *url.URL) { seen = u })
+			w.Settings = wug.Settings{Language: wug.LangFrench}
+			if _, err := w.GetWithContext(context.Background(), wug.Cond, q); err != nil {
+				t.Fatalf("error requesting %q %q %q: %s\n", key, a, b, err)
+			}
+			checkURL(t, seen)
+		}
+	})
+}
+
+// checkURL checks the url parses back to itself and that resolving dot
+// segments keeps it within the /q/ path.
+func checkURL(t *testing.T, u *url.URL) {
+	parsed, err := url.Parse(u.String())
+	if err != nil {
+		t.Fatalf("error parsing %s: %s\n", u, err)
+	}
+
+	escaped := parsed.EscapedPath()
+	if escaped != u.EscapedPath() {
+		t.Fatalf("path %s changed to %s when parsed\n", u.EscapedPath(), escaped)
+	}
+
+	segments := strings.Split(escaped, "/")
+	if len(segments) < 7 || segments[1] != "api" || segments[3] != "conditions" || segments[4] != "lang:FR" || segments[5] != "q" {
+		t.Fatalf("unexpected path %s\n", escaped)
+	}
+
+	// empty components collapse when cleaned, so clean the prefix too
+	prefix := path.Clean(strings.Join(segments[:6], "/")) + "/"
+	if cleaned := path.Clean(escaped); !strings.HasPrefix(cleaned+"/", prefix) {
+		t.Fatalf("path %s leaves %s\n", escaped, prefix)
+	}
+
+	if geoIP := parsed.Query()["geo_ip"]; len(geoIP) > 1 {
+		t.Fatalf("query arguments were injected into %s\n", u)
+	}
+}
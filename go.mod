@@ -0,0 +1,8 @@
+module github.com/wirepair/wug
+
+go 1.21
+
+require (
+	golang.org/x/text v0.22.0
+	golang.org/x/time v0.10.0
+)
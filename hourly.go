@@ -35,58 +35,59 @@ type FCTTIME struct {
 type HourlyForecast struct {
 	Fcttime FCTTIME `json:"FCTTIME"`
 	Temp    struct {
-		English string `json:"english"`
-		Metric  string `json:"metric"`
+		English FlexFloat `json:"english"`
+		Metric  FlexFloat `json:"metric"`
 	} `json:"temp"`
 	Dewpoint struct {
-		English string `json:"english"`
-		Metric  string `json:"metric"`
+		English FlexFloat `json:"english"`
+		Metric  FlexFloat `json:"metric"`
 	} `json:"dewpoint"`
-	Condition string `json:"condition"`
-	Icon      string `json:"icon"`
-	IconURL   string `json:"icon_url"`
-	Fctcode   string `json:"fctcode"`
-	Sky       string `json:"sky"`
+	Condition string    `json:"condition"`
+	Icon      string    `json:"icon"`
+	IconURL   string    `json:"icon_url"`
+	Fctcode   string    `json:"fctcode"`
+	Sky       FlexFloat `json:"sky"`
 	Wspd      struct {
-		English string `json:"english"`
-		Metric  string `json:"metric"`
+		English FlexFloat `json:"english"`
+		Metric  FlexFloat `json:"metric"`
 	} `json:"wspd"`
 	Wdir struct {
-		Dir     string `json:"dir"`
-		Degrees string `json:"degrees"`
+		Dir     string    `json:"dir"`
+		Degrees FlexFloat `json:"degrees"`
 	} `json:"wdir"`
-	Wx        string `json:"wx"`
-	Uvi       string `json:"uvi"`
-	Humidity  string `json:"humidity"`
+	Wx        string    `json:"wx"`
+	Uvi       FlexFloat `json:"uvi"`
+	Humidity  FlexFloat `json:"humidity"`
 	Windchill struct {
-		English string `json:"english"`
-		Metric  string `json:"metric"`
+		English FlexFloat `json:"english"`
+		Metric  FlexFloat `json:"metric"`
 	} `json:"windchill"`
 	Heatindex struct {
-		English string `json:"english"`
-		Metric  string `json:"metric"`
+		English FlexFloat `json:"english"`
+		Metric  FlexFloat `json:"metric"`
 	} `json:"heatindex"`
 	Feelslike struct {
-		English string `json:"english"`
-		Metric  string `json:"metric"`
+		English FlexFloat `json:"english"`
+		Metric  FlexFloat `json:"metric"`
 	} `json:"feelslike"`
 	Qpf struct {
-		English string `json:"english"`
-		Metric  string `json:"metric"`
+		English FlexFloat `json:"english"`
+		Metric  FlexFloat `json:"metric"`
 	} `json:"qpf"`
 	Snow struct {
-		English string `json:"english"`
-		Metric  string `json:"metric"`
+		English FlexFloat `json:"english"`
+		Metric  FlexFloat `json:"metric"`
 	} `json:"snow"`
-	Pop  string `json:"pop"`
+	Pop  FlexFloat `json:"pop"`
 	Mslp struct {
-		English string `json:"english"`
-		Metric  string `json:"metric"`
+		English FlexFloat `json:"english"`
+		Metric  FlexFloat `json:"metric"`
 	} `json:"mslp"`
 }
 
 // Hourly data
 type Hourly struct {
+	DecodeResult
 	Response struct {
 		Version        string `json:"version"`
 		TermsofService string `json:"termsofService"`
@@ -99,6 +100,7 @@ type Hourly struct {
 
 // HourlyTenDay the ten day hourly forecast
 type HourlyTenDay struct {
+	DecodeResult
 	Response struct {
 		Version        string `json:"version"`
 		TermsofService string `json:"termsofService"`
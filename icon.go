@@ -0,0 +1,314 @@
+package wug
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PrecipType is the kind of precipitation a condition implies
+type PrecipType int
+
+// PrecipType constants
+const (
+	PrecipNone  PrecipType = iota // no precipitation
+	PrecipRain                    // rain or showers
+	PrecipSnow                    // snow, flurries or blowing snow
+	PrecipSleet                   // sleet or ice pellets
+	PrecipMixed                   // a mix of rain and frozen precipitation
+)
+
+// ThunderRisk a condition implies
+type ThunderRisk int
+
+// ThunderRisk constants
+const (
+	ThunderNone     ThunderRisk = iota // no thunder
+	ThunderPossible                    // chance of thunderstorms
+	ThunderLikely                      // thunderstorms
+)
+
+// condition holds the semantics shared by icons and fctcodes
+type condition struct {
+	name         string
+	precip       PrecipType
+	thunder      ThunderRisk
+	severity     int                 // 0 (clear) to 10 (blizzard)
+	descriptions map[Language]string // short descriptions, LangEnglish is required
+}
+
+func (c condition) description(lang Language) string {
+	if description, ok := c.descriptions[lang]; ok {
+		return description
+	}
+
+	if lang == LangFrenchCanadian {
+		return c.description(LangFrench)
+	}
+	return c.descriptions[LangEnglish]
+}
+
+// Icon is a weather underground condition icon, without its night variant
+type Icon int
+
+// Icon constants
+const (
+	IconUnknown Icon = iota
+	IconClear
+	IconSunny
+	IconMostlySunny
+	IconPartlySunny
+	IconPartlyCloudy
+	IconMostlyCloudy
+	IconCloudy
+	IconHazy
+	IconFog
+	IconChanceFlurries
+	IconFlurries
+	IconChanceRain
+	IconRain
+	IconChanceSleet
+	IconSleet
+	IconChanceSnow
+	IconSnow
+	IconChanceTStorms
+	IconTStorms
+)
+
+var iconMap = map[Icon]condition{
+	IconUnknown:        {"unknown", PrecipNone, ThunderNone, 0, descriptions("Unknown", "Inconnu", "Unbekannt", "Desconocido", "不明")},
+	IconClear:          {"clear", PrecipNone, ThunderNone, 0, descriptions("Clear", "Dégagé", "Klar", "Despejado", "快晴")},
+	IconSunny:          {"sunny", PrecipNone, ThunderNone, 0, descriptions("Sunny", "Ensoleillé", "Sonnig", "Soleado", "晴れ")},
+	IconMostlySunny:    {"mostlysunny", PrecipNone, ThunderNone, 1, descriptions("Mostly Sunny", "Plutôt ensoleillé", "Überwiegend sonnig", "Mayormente soleado", "おおむね晴れ")},
+	IconPartlySunny:    {"partlysunny", PrecipNone, ThunderNone, 1, descriptions("Partly Sunny", "Partiellement ensoleillé", "Teilweise sonnig", "Parcialmente soleado", "晴れ時々曇り")},
+	IconPartlyCloudy:   {"partlycloudy", PrecipNone, ThunderNone, 1, descriptions("Partly Cloudy", "Partiellement nuageux", "Teilweise bewölkt", "Parcialmente nublado", "所により曇り")},
+	IconMostlyCloudy:   {"mostlycloudy", PrecipNone, ThunderNone, 2, descriptions("Mostly Cloudy", "Plutôt nuageux", "Überwiegend bewölkt", "Mayormente nublado", "おおむね曇り")},
+	IconCloudy:         {"cloudy", PrecipNone, ThunderNone, 3, descriptions("Cloudy", "Nuageux", "Bewölkt", "Nublado", "曇り")},
+	IconHazy:           {"hazy", PrecipNone, ThunderNone, 3, descriptions("Hazy", "Brumeux", "Dunstig", "Calima", "もや")},
+	IconFog:            {"fog", PrecipNone, ThunderNone, 4, descriptions("Fog", "Brouillard", "Nebel", "Niebla", "霧")},
+	IconChanceFlurries: {"chanceflurries", PrecipSnow, ThunderNone, 4, descriptions("Chance of Flurries", "Risque d'averses de neige", "Schneegestöber möglich", "Posibilidad de nevisca", "にわか雪の可能性")},
+	IconFlurries:       {"flurries", PrecipSnow, ThunderNone, 5, descriptions("Flurries", "Averses de neige", "Schneegestöber", "Nevisca", "にわか雪")},
+	IconChanceRain:     {"chancerain", PrecipRain, ThunderNone, 4, descriptions("Chance of Rain", "Risque de pluie", "Regen möglich", "Posibilidad de lluvia", "雨の可能性")},
+	IconRain:           {"rain", PrecipRain, ThunderNone, 6, descriptions("Rain", "Pluie", "Regen", "Lluvia", "雨")},
+	IconChanceSleet:    {"chancesleet", PrecipSleet, ThunderNone, 5, descriptions("Chance of Sleet", "Risque de grésil", "Schneeregen möglich", "Posibilidad de aguanieve", "みぞれの可能性")},
+	IconSleet:          {"sleet", PrecipSleet, ThunderNone, 7, descriptions("Sleet", "Grésil", "Schneeregen", "Aguanieve", "みぞれ")},
+	IconChanceSnow:     {"chancesnow", PrecipSnow, ThunderNone, 5, descriptions("Chance of Snow", "Risque de neige", "Schnee möglich", "Posibilidad de nieve", "雪の可能性")},
+	IconSnow:           {"snow", PrecipSnow, ThunderNone, 7, descriptions("Snow", "Neige", "Schnee", "Nieve", "雪")},
+	IconChanceTStorms:  {"chancetstorms", PrecipRain, ThunderPossible, 7, descriptions("Chance of Thunderstorms", "Risque d'orages", "Gewitter möglich", "Posibilidad de tormentas", "雷雨の可能性")},
+	IconTStorms:        {"tstorms", PrecipRain, ThunderLikely, 9, descriptions("Thunderstorms", "Orages", "Gewitter", "Tormentas", "雷雨")},
+}
+
+// descriptions builds the description table for the translated languages.
+func descriptions(english, french, german, spanish, japanese string) map[Language]string {
+	return map[Language]string{
+		LangEnglish:  english,
+		LangFrench:   french,
+		LangGerman:   german,
+		LangSpanish:  spanish,
+		LangJapanese: japanese,
+	}
+}
+
+// ParseIcon parses an icon name such as chancetstorms or nt_partlycloudy,
+// night is true for the nt_ variants. Unknown names return IconUnknown.
+func ParseIcon(name string) (icon Icon, night bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if strings.HasPrefix(name, "nt_") {
+		name, night = name[3:], true
+	}
+
+	for icon, c := range iconMap {
+		if c.name == name {
+			return icon, night
+		}
+	}
+	return IconUnknown, night
+}
+
+// parseIconURL parses the icon from the icon name, using the icon url to find
+// night variants the name does not carry (as in current observations).
+func parseIconURL(name, iconURL string) (Icon, bool) {
+	icon, night := ParseIcon(name)
+	return icon, night || strings.Contains(iconURL, "/nt_")
+}
+
+// String returns the day time icon name.
+func (i Icon) String() string {
+	return iconMap[i].name
+}
+
+// Variant returns the icon name of the day or night variant.
+func (i Icon) Variant(night bool) string {
+	if night {
+		return "nt_" + i.String()
+	}
+	return i.String()
+}
+
+// Precipitation returns the kind of precipitation of the icon.
+func (i Icon) Precipitation() PrecipType {
+	return iconMap[i].precip
+}
+
+// Thunder returns the thunder risk of the icon.
+func (i Icon) Thunder() ThunderRisk {
+	return iconMap[i].thunder
+}
+
+// Severity ranks the icon from 0 (clear) to 10 (most severe).
+func (i Icon) Severity() int {
+	return iconMap[i].severity
+}
+
+// Description returns a short description of the icon in the language,
+// falling back to English.
+func (i Icon) Description(lang Language) string {
+	return iconMap[i].description(lang)
+}
+
+// FctCode is the numeric forecast condition code (1-24) of hourly forecasts
+type FctCode int
+
+// FctCode constants
+const (
+	FctUnknown FctCode = iota
+	FctClear
+	FctPartlyCloudy
+	FctMostlyCloudy
+	FctCloudy
+	FctHazy
+	FctFoggy
+	FctVeryHot
+	FctVeryCold
+	FctBlowingSnow
+	FctChanceShowers
+	FctShowers
+	FctChanceRain
+	FctRain
+	FctChanceThunderstorm
+	FctThunderstorm
+	FctFlurries
+	FctOmitted
+	FctChanceSnowShowers
+	FctSnowShowers
+	FctChanceSnow
+	FctSnow
+	FctChanceIcePellets
+	FctIcePellets
+	FctBlizzard
+)
+
+var fctCodeMap = map[FctCode]condition{
+	FctUnknown:            {"unknown", PrecipNone, ThunderNone, 0, descriptions("Unknown", "Inconnu", "Unbekannt", "Desconocido", "不明")},
+	FctClear:              {"clear", PrecipNone, ThunderNone, 0, descriptions("Clear", "Dégagé", "Klar", "Despejado", "快晴")},
+	FctPartlyCloudy:       {"partlycloudy", PrecipNone, ThunderNone, 1, descriptions("Partly Cloudy", "Partiellement nuageux", "Teilweise bewölkt", "Parcialmente nublado", "所により曇り")},
+	FctMostlyCloudy:       {"mostlycloudy", PrecipNone, ThunderNone, 2, descriptions("Mostly Cloudy", "Plutôt nuageux", "Überwiegend bewölkt", "Mayormente nublado", "おおむね曇り")},
+	FctCloudy:             {"cloudy", PrecipNone, ThunderNone, 3, descriptions("Cloudy", "Nuageux", "Bewölkt", "Nublado", "曇り")},
+	FctHazy:               {"hazy", PrecipNone, ThunderNone, 3, descriptions("Hazy", "Brumeux", "Dunstig", "Calima", "もや")},
+	FctFoggy:              {"fog", PrecipNone, ThunderNone, 4, descriptions("Foggy", "Brouillard", "Neblig", "Niebla", "霧")},
+	FctVeryHot:            {"clear", PrecipNone, ThunderNone, 5, descriptions("Very Hot", "Très chaud", "Sehr heiß", "Muy caluroso", "猛暑")},
+	FctVeryCold:           {"clear", PrecipNone, ThunderNone, 5, descriptions("Very Cold", "Très froid", "Sehr kalt", "Muy frío", "厳寒")},
+	FctBlowingSnow:        {"snow", PrecipSnow, ThunderNone, 7, descriptions("Blowing Snow", "Poudrerie", "Schneetreiben", "Ventisca", "地吹雪")},
+	FctChanceShowers:      {"chancerain", PrecipRain, ThunderNone, 4, descriptions("Chance of Showers", "Risque d'averses", "Schauer möglich", "Posibilidad de chubascos", "にわか雨の可能性")},
+	FctShowers:            {"rain", PrecipRain, ThunderNone, 5, descriptions("Showers", "Averses", "Schauer", "Chubascos", "にわか雨")},
+	FctChanceRain:         {"chancerain", PrecipRain, ThunderNone, 4, descriptions("Chance of Rain", "Risque de pluie", "Regen möglich", "Posibilidad de lluvia", "雨の可能性")},
+	FctRain:               {"rain", PrecipRain, ThunderNone, 6, descriptions("Rain", "Pluie", "Regen", "Lluvia", "雨")},
+	FctChanceThunderstorm: {"chancetstorms", PrecipRain, ThunderPossible, 7, descriptions("Chance of a Thunderstorm", "Risque d'orage", "Gewitter möglich", "Posibilidad de tormenta", "雷雨の可能性")},
+	FctThunderstorm:       {"tstorms", PrecipRain, ThunderLikely, 9, descriptions("Thunderstorm", "Orage", "Gewitter", "Tormenta", "雷雨")},
+	FctFlurries:           {"flurries", PrecipSnow, ThunderNone, 5, descriptions("Flurries", "Averses de neige", "Schneegestöber", "Nevisca", "にわか雪")},
+	FctOmitted:            {"unknown", PrecipNone, ThunderNone, 0, descriptions("Omitted", "Omis", "Ausgelassen", "Omitido", "省略")},
+	FctChanceSnowShowers:  {"chancesnow", PrecipSnow, ThunderNone, 5, descriptions("Chance of Snow Showers", "Risque d'averses de neige", "Schneeschauer möglich", "Posibilidad de chubascos de nieve", "にわか雪の可能性")},
+	FctSnowShowers:        {"snow", PrecipSnow, ThunderNone, 6, descriptions("Snow Showers", "Averses de neige", "Schneeschauer", "Chubascos de nieve", "にわか雪")},
+	FctChanceSnow:         {"chancesnow", PrecipSnow, ThunderNone, 5, descriptions("Chance of Snow", "Risque de neige", "Schnee möglich", "Posibilidad de nieve", "雪の可能性")},
+	FctSnow:               {"snow", PrecipSnow, ThunderNone, 7, descriptions("Snow", "Neige", "Schnee", "Nieve", "雪")},
+	FctChanceIcePellets:   {"chancesleet", PrecipSleet, ThunderNone, 6, descriptions("Chance of Ice Pellets", "Risque de grésil", "Eiskörner möglich", "Posibilidad de granizo menudo", "凍雨の可能性")},
+	FctIcePellets:         {"sleet", PrecipSleet, ThunderNone, 8, descriptions("Ice Pellets", "Grésil", "Eiskörner", "Granizo menudo", "凍雨")},
+	FctBlizzard:           {"snow", PrecipSnow, ThunderNone, 10, descriptions("Blizzard", "Blizzard", "Schneesturm", "Ventisca fuerte", "猛吹雪")},
+}
+
+// ParseFctCode parses the fctcode string of an hourly forecast, ok is false
+// if it is not a code between 1 and 24.
+func ParseFctCode(code string) (FctCode, bool) {
+	n, err := strconv.Atoi(strings.TrimSpace(code))
+	if err != nil || n < int(FctClear) || n > int(FctBlizzard) {
+		return FctUnknown, false
+	}
+	return FctCode(n), true
+}
+
+// Icon returns the icon that best represents the code.
+func (f FctCode) Icon() Icon {
+	icon, _ := ParseIcon(fctCodeMap[f].name)
+	return icon
+}
+
+// Precipitation returns the kind of precipitation of the code.
+func (f FctCode) Precipitation() PrecipType {
+	return fctCodeMap[f].precip
+}
+
+// Thunder returns the thunder risk of the code.
+func (f FctCode) Thunder() ThunderRisk {
+	return fctCodeMap[f].thunder
+}
+
+// Severity ranks the code from 0 (clear) to 10 (blizzard).
+func (f FctCode) Severity() int {
+	return fctCodeMap[f].severity
+}
+
+// Description returns a short description of the code in the language,
+// falling back to English.
+func (f FctCode) Description(lang Language) string {
+	return fctCodeMap[f].description(lang)
+}
+
+// WxPrecipitation returns the kind of precipitation of a free form wx
+// string, such as "Rain Showers/Wind" or "Rain/Snow".
+func WxPrecipitation(wx string) PrecipType {
+	wx = strings.ToLower(wx)
+	snow := strings.Contains(wx, "snow") || strings.Contains(wx, "flurr")
+	rain := strings.Contains(wx, "rain") || strings.Contains(wx, "drizzle") || strings.Contains(wx, "thunder") ||
+		strings.Contains(wx, "shower") && !strings.Contains(wx, "snow shower")
+	sleet := strings.Contains(wx, "sleet") || strings.Contains(wx, "ice pellet") || strings.Contains(wx, "freezing")
+
+	switch {
+	case sleet && !rain && !snow:
+		return PrecipSleet
+	case rain && snow, sleet:
+		return PrecipMixed
+	case snow:
+		return PrecipSnow
+	case rain:
+		return PrecipRain
+	}
+	return PrecipNone
+}
+
+// IconType returns the parsed icon of the observation and whether it is the
+// night variant.
+func (c *Conditions) IconType() (Icon, bool) {
+	return parseIconURL(c.CurrentObservation.Icon, c.CurrentObservation.IconURL)
+}
+
+// IconType returns the parsed icon of the forecast day and whether it is the
+// night variant.
+func (f *ForecastDay) IconType() (Icon, bool) {
+	return parseIconURL(f.Icon, f.IconURL)
+}
+
+// IconType returns the parsed icon of the text forecast period and whether it
+// is the night variant.
+func (t *TxtForecastDay) IconType() (Icon, bool) {
+	return parseIconURL(t.Icon, t.IconURL)
+}
+
+// IconType returns the parsed icon of the hourly forecast and whether it is
+// the night variant.
+func (h *HourlyForecast) IconType() (Icon, bool) {
+	return parseIconURL(h.Icon, h.IconURL)
+}
+
+// Code returns the parsed fctcode of the hourly forecast.
+func (h *HourlyForecast) Code() (FctCode, bool) {
+	return ParseFctCode(h.Fctcode)
+}
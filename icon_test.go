@@ -0,0 +1,108 @@
+package wug
+
+import (
+	"testing"
+)
+
+func TestParseIcon(t *testing.T) {
+	for icon := range iconMap {
+		for _, night := range []bool{false, true} {
+			parsed, parsedNight := ParseIcon(icon.Variant(night))
+			if parsed != icon || parsedNight != night {
+				t.Fatalf("%s did not round trip, got %s %v\n", icon.Variant(night), parsed, parsedNight)
+			}
+		}
+	}
+
+	if icon, night := ParseIcon(" NT_ChanceTStorms "); icon != IconChanceTStorms || !night {
+		t.Fatalf("expected night chancetstorms got %s %v\n", icon, night)
+	}
+
+	if icon, _ := ParseIcon("volcano"); icon != IconUnknown {
+		t.Fatalf("expected unknown got %s\n", icon)
+	}
+}
+
+func TestIconSemantics(t *testing.T) {
+	if IconChanceTStorms.Thunder() != ThunderPossible || IconTStorms.Thunder() != ThunderLikely || IconRain.Thunder() != ThunderNone {
+		t.Fatalf("unexpected thunder risks")
+	}
+
+	if IconSleet.Precipitation() != PrecipSleet || IconFlurries.Precipitation() != PrecipSnow || IconPartlyCloudy.Precipitation() != PrecipNone {
+		t.Fatalf("unexpected precipitation types")
+	}
+
+	if IconClear.Severity() >= IconCloudy.Severity() || IconCloudy.Severity() >= IconRain.Severity() || IconRain.Severity() >= IconTStorms.Severity() {
+		t.Fatalf("unexpected severity ranking")
+	}
+
+	if IconPartlyCloudy.Description(LangFrench) != "Partiellement nuageux" || IconPartlyCloudy.Description(LangFrenchCanadian) != "Partiellement nuageux" {
+		t.Fatalf("unexpected french description")
+	}
+
+	if IconSnow.Description(LangJapanese) != "雪" || IconSnow.Description(LangWelsh) != "Snow" {
+		t.Fatalf("unexpected description fallback")
+	}
+
+	for icon, c := range iconMap {
+		if c.descriptions[LangEnglish] == "" {
+			t.Fatalf("%s has no english description\n", icon)
+		}
+	}
+}
+
+func TestFctCode(t *testing.T) {
+	for n := 1; n <= 24; n++ {
+		code, ok := ParseFctCode(string(rune('0'+n/10)) + string(rune('0'+n%10)))
+		if !ok || int(code) != n {
+			t.Fatalf("expected code %d got %d %v\n", n, code, ok)
+		}
+
+		if code.Description(LangEnglish) == "" || fctCodeMap[code].name == "" {
+			t.Fatalf("code %d has no description\n", n)
+		}
+	}
+
+	for _, invalid := range []string{"", "0", "25", "x"} {
+		if _, ok := ParseFctCode(invalid); ok {
+			t.Fatalf("expected %q to be invalid\n", invalid)
+		}
+	}
+
+	if FctBlizzard.Severity() != 10 || FctBlizzard.Precipitation() != PrecipSnow || FctBlizzard.Icon() != IconSnow {
+		t.Fatalf("unexpected blizzard semantics")
+	}
+
+	if FctChanceThunderstorm.Thunder() != ThunderPossible || FctChanceThunderstorm.Icon() != IconChanceTStorms || FctIcePellets.Precipitation() != PrecipSleet {
+		t.Fatalf("unexpected code semantics")
+	}
+
+	h := &HourlyForecast{Fctcode: "15", Icon: "tstorms", IconURL: "http://icons.wxug.com/i/c/k/nt_tstorms.gif"}
+	if code, ok := h.Code(); !ok || code != FctThunderstorm {
+		t.Fatalf("expected thunderstorm code got %d\n", code)
+	}
+
+	if icon, night := h.IconType(); icon != IconTStorms || !night {
+		t.Fatalf("expected night tstorms got %s %v\n", icon, night)
+	}
+}
+
+func TestWxPrecipitation(t *testing.T) {
+	var tests = map[string]PrecipType{
+		"":                  PrecipNone,
+		"Rain Showers/Wind": PrecipRain,
+		"Snow Showers":      PrecipSnow,
+		"Showers/Snow":      PrecipMixed,
+		"Light Snow":        PrecipSnow,
+		"Rain/Snow":         PrecipMixed,
+		"Freezing Rain":     PrecipMixed,
+		"Sleet":             PrecipSleet,
+		"Thunderstorms":     PrecipRain,
+	}
+
+	for wx, want := range tests {
+		if got := WxPrecipitation(wx); got != want {
+			t.Fatalf("%q: expected %d got %d\n", wx, want, got)
+		}
+	}
+}
@@ -0,0 +1,382 @@
+package wug
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WarningAction taken by the lenient decoder for a field
+type WarningAction int
+
+// WarningAction constants
+const (
+	Coerced WarningAction = iota // value was converted to the field type
+	Dropped                      // value could not be converted, field left as zero
+)
+
+// DecodeWarning records a field whose value did not match its declared type
+// when decoding leniently.
+type DecodeWarning struct {
+	Path   string        // json path of the field, e.g. forecast.txt_forecast.forecastday[0].pop
+	Action WarningAction // what happened to the value
+	Value  string        // the raw json value
+	Type   string        // the go type of the field
+}
+
+func (w DecodeWarning) String() string {
+	action := "coerced"
+	if w.Action == Dropped {
+		action = "dropped"
+	}
+	return fmt.Sprintf("%s: %s %s into %s", w.Path, action, w.Value, w.Type)
+}
+
+// DecodeResult is embedded in the response types to carry the warnings of a
+// lenient decode.
+type DecodeResult struct {
+	DecodeWarnings []DecodeWarning `json:"-"`
+}
+
+func (r *DecodeResult) setDecodeWarnings(warnings []DecodeWarning) {
+	r.DecodeWarnings = warnings
+}
+
+type decodeWarningSetter interface {
+	setDecodeWarnings(warnings []DecodeWarning)
+}
+
+// DecodeError is returned by the lenient decoder when the structure of the
+// response does not match, e.g. an array where an object is expected.
+type DecodeError struct {
+	Path     string
+	Expected string
+	Got      string
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("wug: decoding %s: expected %s got %s", e.Path, e.Expected, e.Got)
+}
+
+// UnmarshalLenient decodes data into v, tolerating number/string/null drift
+// of individual fields. Scalar fields that can be converted are coerced,
+// those that can not are dropped, and both are reported as warnings. An error
+// is only returned for invalid json or structural mismatches.
+func UnmarshalLenient(data []byte, v interface{}) ([]DecodeWarning, error) {
+	return decodeLenient(bytes.NewReader(data), v)
+}
+
+// decodeLenient is UnmarshalLenient for a stream.
+func decodeLenient(r io.Reader, v interface{}) ([]DecodeWarning, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil, fmt.Errorf("wug: lenient decode requires a non nil pointer, got %T", v)
+	}
+
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	var tree interface{}
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+
+	d := &lenientDecoder{}
+	if err := d.assign(rv.Elem(), tree, ""); err != nil {
+		return nil, err
+	}
+	return d.warnings, nil
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+type lenientDecoder struct {
+	warnings []DecodeWarning
+}
+
+func (d *lenientDecoder) warn(dst reflect.Value, src interface{}, path string, action WarningAction) {
+	raw, _ := json.Marshal(src)
+	d.warnings = append(d.warnings, DecodeWarning{Path: path, Action: action, Value: string(raw), Type: dst.Type().String()})
+}
+
+// assign sets dst from the decoded json value src.
+func (d *lenientDecoder) assign(dst reflect.Value, src interface{}, path string) error {
+	if src == nil {
+		return nil
+	}
+
+	if dst.CanAddr() && dst.Addr().Type().Implements(unmarshalerType) {
+		raw, err := json.Marshal(src)
+		if err != nil {
+			return err
+		}
+
+		if err := dst.Addr().Interface().(json.Unmarshaler).UnmarshalJSON(raw); err != nil {
+			dst.Set(reflect.Zero(dst.Type()))
+			d.warn(dst, src, path, Dropped)
+		}
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Ptr:
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return d.assign(dst.Elem(), src, path)
+	case reflect.Struct:
+		object, ok := src.(map[string]interface{})
+		if !ok {
+			return &DecodeError{Path: path, Expected: "object", Got: jsonKind(src)}
+		}
+		return d.assignStruct(dst, object, path)
+	case reflect.Slice:
+		array, ok := src.([]interface{})
+		if !ok {
+			return &DecodeError{Path: path, Expected: "array", Got: jsonKind(src)}
+		}
+
+		slice := reflect.MakeSlice(dst.Type(), len(array), len(array))
+		for i, element := range array {
+			if err := d.assign(slice.Index(i), element, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(slice)
+		return nil
+	case reflect.Map:
+		object, ok := src.(map[string]interface{})
+		if !ok || dst.Type().Key().Kind() != reflect.String {
+			return &DecodeError{Path: path, Expected: "object", Got: jsonKind(src)}
+		}
+
+		m := reflect.MakeMapWithSize(dst.Type(), len(object))
+		for key, element := range object {
+			value := reflect.New(dst.Type().Elem()).Elem()
+			if err := d.assign(value, element, joinPath(path, key)); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(key).Convert(dst.Type().Key()), value)
+		}
+		dst.Set(m)
+		return nil
+	case reflect.Interface:
+		if dst.NumMethod() != 0 {
+			return &DecodeError{Path: path, Expected: dst.Type().String(), Got: jsonKind(src)}
+		}
+		dst.Set(reflect.ValueOf(plainJSON(src)))
+		return nil
+	}
+
+	d.assignScalar(dst, src, path)
+	return nil
+}
+
+// assignStruct matches the object keys to the json names of the struct
+// fields like encoding/json: an exact match wins, then the first case
+// insensitive match in field order.
+func (d *lenientDecoder) assignStruct(dst reflect.Value, object map[string]interface{}, path string) error {
+	fields := collectFields(dst, nil, 0)
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].depth < fields[j].depth })
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		field, ok := matchField(fields, key)
+		if !ok {
+			continue
+		}
+
+		if err := d.assign(field, object[key], joinPath(path, key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// structField is a settable field of a struct by its json name
+type structField struct {
+	name  string
+	value reflect.Value
+	depth int // of embedding, shallower fields win
+}
+
+// matchField returns the field of a key, preferring exact matches.
+func matchField(fields []structField, key string) (reflect.Value, bool) {
+	for _, f := range fields {
+		if f.name == key {
+			return f.value, true
+		}
+	}
+
+	for _, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return f.value, true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// collectFields appends the settable fields of the struct in declaration
+// order, flattening embedded structs.
+func collectFields(v reflect.Value, fields []structField, depth int) []structField {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && tag == "" {
+			fields = collectFields(v.Field(i), fields, depth+1)
+			continue
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, structField{name: name, value: v.Field(i), depth: depth})
+	}
+	return fields
+}
+
+// assignScalar converts src to the scalar kind of dst, recording a warning
+// when the json type did not match.
+func (d *lenientDecoder) assignScalar(dst reflect.Value, src interface{}, path string) {
+	switch dst.Kind() {
+	case reflect.String:
+		switch s := src.(type) {
+		case string:
+			dst.SetString(s)
+		case json.Number:
+			dst.SetString(s.String())
+			d.warn(dst, src, path, Coerced)
+		case bool:
+			dst.SetString(strconv.FormatBool(s))
+			d.warn(dst, src, path, Coerced)
+		default:
+			d.warn(dst, src, path, Dropped)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, exact, ok := toFloat(src)
+		if !ok || n < math.MinInt64 || n >= math.MaxInt64 || dst.OverflowInt(int64(math.Round(n))) {
+			d.warn(dst, src, path, Dropped)
+			return
+		}
+
+		dst.SetInt(int64(math.Round(n)))
+		if !exact || n != math.Round(n) {
+			d.warn(dst, src, path, Coerced)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, exact, ok := toFloat(src)
+		if !ok || n < 0 || n >= math.MaxUint64 || dst.OverflowUint(uint64(math.Round(n))) {
+			d.warn(dst, src, path, Dropped)
+			return
+		}
+
+		dst.SetUint(uint64(math.Round(n)))
+		if !exact || n != math.Round(n) {
+			d.warn(dst, src, path, Coerced)
+		}
+	case reflect.Float32, reflect.Float64:
+		n, exact, ok := toFloat(src)
+		if !ok || dst.OverflowFloat(n) {
+			d.warn(dst, src, path, Dropped)
+			return
+		}
+
+		dst.SetFloat(n)
+		if !exact {
+			d.warn(dst, src, path, Coerced)
+		}
+	case reflect.Bool:
+		switch b := src.(type) {
+		case bool:
+			dst.SetBool(b)
+		case string, json.Number:
+			parsed, err := strconv.ParseBool(fmt.Sprint(b))
+			if err != nil {
+				d.warn(dst, src, path, Dropped)
+				return
+			}
+			dst.SetBool(parsed)
+			d.warn(dst, src, path, Coerced)
+		default:
+			d.warn(dst, src, path, Dropped)
+		}
+	default:
+		d.warn(dst, src, path, Dropped)
+	}
+}
+
+// toFloat converts a json number or numeric string to a float64. exact is
+// false when the json value was not a number.
+func toFloat(src interface{}) (n float64, exact bool, ok bool) {
+	switch v := src.(type) {
+	case json.Number:
+		n, err := v.Float64()
+		return n, true, err == nil
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, false, err == nil && !math.IsNaN(n)
+	}
+	return 0, false, false
+}
+
+// plainJSON converts json.Number values back to float64 like encoding/json
+// does for interface{} fields.
+func plainJSON(src interface{}) interface{} {
+	switch v := src.(type) {
+	case json.Number:
+		n, _ := v.Float64()
+		return n
+	case []interface{}:
+		for i := range v {
+			v[i] = plainJSON(v[i])
+		}
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = plainJSON(v[key])
+		}
+	}
+	return src
+}
+
+func jsonKind(src interface{}) string {
+	switch src.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "bool"
+	}
+	return "null"
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,126 @@
+package wug
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const driftedForecast = `{
+	"response": {"version": "0.1", "features": {"forecast": "1"}},
+	"forecast": {
+		"txt_forecast": {"date": "5:00 PM", "forecastday": [{"period": 0, "title": "Tonight", "pop": 20}]},
+		"simpleforecast": {"forecastday": [{
+			"date": {"epoch": 1493082000, "day": 24.0, "month": "4", "year": 2017.5, "yday": "x"},
+			"period": "1",
+			"high": {"fahrenheit": "68", "celsius": "abc"},
+			"pop": "20",
+			"qpf_allday": {"in": 0.05, "mm": "1.3"},
+			"conditions": {"text": "Clear"},
+			"avehumidity": null
+		}]}
+	}
+}`
+
+func TestUnmarshalLenient(t *testing.T) {
+	if err := json.Unmarshal([]byte(driftedForecast), &Forecast{}); err == nil {
+		t.Fatalf("expected strict decoding to fail")
+	}
+
+	forecast := &Forecast{}
+	warnings, err := UnmarshalLenient([]byte(driftedForecast), forecast)
+	if err != nil {
+		t.Fatalf("error decoding leniently: %s\n", err)
+	}
+
+	day := forecast.Forecast.Simpleforecast.Forecastday[0]
+	if day.Date.Epoch != "1493082000" || day.Date.Day != 24 || day.Date.Month != 4 || day.Date.Year != 2018 || day.Period != 1 {
+		t.Fatalf("unexpected date %#v\n", day.Date)
+	}
+
+	if day.High.Fahrenheit.Value != 68 || day.High.Celsius.Valid || day.Pop.Value != 20 || day.QpfAllday.Mm.Value != 1.3 || day.Conditions != "" {
+		t.Fatalf("unexpected day %#v\n", day)
+	}
+
+	if forecast.Response.Features.Forecast != 1 || forecast.Forecast.Textforecast.TxtForecastday[0].Pop != "20" {
+		t.Fatalf("unexpected response %#v\n", forecast.Response)
+	}
+
+	var got []string
+	for _, warning := range warnings {
+		got = append(got, warning.String())
+	}
+
+	expected := []string{
+		`forecast.simpleforecast.forecastday[0].conditions: dropped {"text":"Clear"} into string`,
+		`forecast.simpleforecast.forecastday[0].date.epoch: coerced 1493082000 into string`,
+		`forecast.simpleforecast.forecastday[0].date.month: coerced "4" into int`,
+		`forecast.simpleforecast.forecastday[0].date.yday: dropped "x" into int`,
+		`forecast.simpleforecast.forecastday[0].date.year: coerced 2017.5 into int`,
+		`forecast.simpleforecast.forecastday[0].high.celsius: dropped "abc" into wug.FlexFloat`,
+		`forecast.simpleforecast.forecastday[0].period: coerced "1" into int`,
+		`forecast.txt_forecast.forecastday[0].pop: coerced 20 into string`,
+		`response.features.forecast: coerced "1" into int`,
+	}
+
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected warnings\n%s\n", strings.Join(got, "\n"))
+	}
+}
+
+func TestUnmarshalLenientStructural(t *testing.T) {
+	for _, input := range []string{
+		`{"hourly_forecast": {"FCTTIME": {}}}`,
+		`{"hourly_forecast": [{"FCTTIME": "13"}]}`,
+		`[]`,
+		`{"hourly_forecast": [`,
+	} {
+		if _, err := UnmarshalLenient([]byte(input), &Hourly{}); err == nil {
+			t.Fatalf("expected error decoding %s\n", input)
+		}
+	}
+}
+
+func TestUnmarshalLenientFieldOrder(t *testing.T) {
+	type fields struct {
+		Upper string `json:"Name"`
+		Lower string `json:"nAME"`
+		Other string `json:"other"`
+	}
+
+	for _, input := range []string{`{"Name": "a", "OTHER": "b"}`, `{"nAME": "a", "OTHER": "b"}`, `{"NAME": "a", "OTHER": "b"}`} {
+		var strict, lenient fields
+		if err := json.Unmarshal([]byte(input), &strict); err != nil {
+			t.Fatalf("error decoding %s: %s\n", input, err)
+		}
+
+		// repeated to catch a random choice between the fields
+		for i := 0; i < 20; i++ {
+			lenient = fields{}
+			if _, err := UnmarshalLenient([]byte(input), &lenient); err != nil {
+				t.Fatalf("error decoding %s leniently: %s\n", input, err)
+			}
+
+			if lenient != strict {
+				t.Fatalf("expected %s to decode like encoding/json %+v got %+v\n", input, strict, lenient)
+			}
+		}
+	}
+}
+
+func TestGetFeatureLenient(t *testing.T) {
+	w := newStaticWug(driftedForecast)
+	if _, err := w.GetForecast(NewQueryByAutoIP("apikey")); err == nil {
+		t.Fatalf("expected strict decoding to fail")
+	}
+
+	w.Lenient = true
+	forecast, err := w.GetForecast(NewQueryByAutoIP("apikey"))
+	if err != nil {
+		t.Fatalf("error getting forecast: %s\n", err)
+	}
+
+	if len(forecast.DecodeWarnings) != 9 {
+		t.Fatalf("expected 9 decode warnings got %v\n", forecast.DecodeWarnings)
+	}
+}
@@ -0,0 +1,154 @@
+package meteo
+
+import (
+	"math"
+	"time"
+
+	"github.com/wirepair/wug/units"
+)
+
+// DegreeDays is an accumulation of degrees over days, stored in °C days
+type DegreeDays float64
+
+// Celsius returns the degree days in °C days.
+func (d DegreeDays) Celsius() float64 { return float64(d) }
+
+// Fahrenheit returns the degree days in °F days.
+func (d DegreeDays) Fahrenheit() float64 { return float64(d) * 9 / 5 }
+
+// GDDMethod selects how growing degree days are computed
+type GDDMethod int
+
+// GDDMethod constants
+const (
+	GDDAverage  GDDMethod = iota // mean of the high (capped) and low less the base
+	GDDModified                  // high and low are both clamped between the base and cap
+)
+
+// HeatingDegreeDays returns the heating degree days of a day with the high
+// and low temperatures, relative to base.
+func HeatingDegreeDays(high, low, base units.Temperature) DegreeDays {
+	mean := (high.Celsius() + low.Celsius()) / 2
+	return DegreeDays(math.Max(base.Celsius()-mean, 0))
+}
+
+// CoolingDegreeDays returns the cooling degree days of a day with the high
+// and low temperatures, relative to base.
+func CoolingDegreeDays(high, low, base units.Temperature) DegreeDays {
+	mean := (high.Celsius() + low.Celsius()) / 2
+	return DegreeDays(math.Max(mean-base.Celsius(), 0))
+}
+
+// GrowingDegreeDays returns the growing degree days of a day with the high
+// and low temperatures, relative to base with temperatures above limit
+// not counting. A limit at or below base disables it.
+func GrowingDegreeDays(high, low, base, limit units.Temperature, method GDDMethod) DegreeDays {
+	h, l, b := high.Celsius(), low.Celsius(), base.Celsius()
+	if limit > base {
+		h = math.Min(h, limit.Celsius())
+		l = math.Min(l, limit.Celsius())
+	}
+
+	if method == GDDModified {
+		h = math.Max(h, b)
+		l = math.Max(l, b)
+	}
+	return DegreeDays(math.Max((h+l)/2-b, 0))
+}
+
+// Chill hour range of the 32-45°F (0-7.2°C) model
+const (
+	chillLow  = 32.0
+	chillHigh = 45.0
+)
+
+// IsChillHour returns true if an hour at temperature t counts towards the
+// chill requirement of fruit trees.
+func IsChillHour(t units.Temperature) bool {
+	f := math.Round(t.Fahrenheit()*1e6) / 1e6
+	return f >= chillLow && f <= chillHigh
+}
+
+// DailyWeather is a summary of the weather of a single day, the input of the
+// degree day and evapotranspiration calculations.
+type DailyWeather struct {
+	Date           time.Time // midnight of the day, in the local zone
+	High           units.Temperature
+	Low            units.Temperature
+	Humidity       *float64     // mean relative humidity percent
+	HumidityMax    *float64     // maximum relative humidity percent
+	HumidityMin    *float64     // minimum relative humidity percent
+	Wind           *units.Speed // mean wind speed at 2 m
+	SolarRadiation *float64     // MJ/m² over the day
+}
+
+// Days is a series of daily weather
+type Days []DailyWeather
+
+// HeatingDegreeDays returns the total heating degree days relative to base.
+func (days Days) HeatingDegreeDays(base units.Temperature) (total DegreeDays) {
+	for _, d := range days {
+		total += HeatingDegreeDays(d.High, d.Low, base)
+	}
+	return total
+}
+
+// CoolingDegreeDays returns the total cooling degree days relative to base.
+func (days Days) CoolingDegreeDays(base units.Temperature) (total DegreeDays) {
+	for _, d := range days {
+		total += CoolingDegreeDays(d.High, d.Low, base)
+	}
+	return total
+}
+
+// GrowingDegreeDays returns the total growing degree days.
+func (days Days) GrowingDegreeDays(base, limit units.Temperature, method GDDMethod) (total DegreeDays) {
+	for _, d := range days {
+		total += GrowingDegreeDays(d.High, d.Low, base, limit, method)
+	}
+	return total
+}
+
+// ET0 returns the total reference evapotranspiration at site.
+func (days Days) ET0(site Site) (total units.Precipitation) {
+	for _, d := range days {
+		total += DailyET0(site, d)
+	}
+	return total
+}
+
+// HourlyWeather is the weather of a single hour, the input of the chill hour
+// and hourly evapotranspiration calculations.
+type HourlyWeather struct {
+	Time           time.Time // start of the hour, in the local zone
+	Temperature    units.Temperature
+	Humidity       float64      // relative humidity percent
+	Wind           *units.Speed // mean wind speed at 2 m
+	SolarRadiation *float64     // MJ/m² over the hour
+	CloudCover     *float64     // sky cover percent, used when radiation is unknown
+}
+
+// Hours is a series of hourly weather
+type Hours []HourlyWeather
+
+// ChillHours returns the number of hours between 32°F and 45°F.
+func (hours Hours) ChillHours() int {
+	count := 0
+	for _, h := range hours {
+		if IsChillHour(h.Temperature) {
+			count++
+		}
+	}
+	return count
+}
+
+// ET0 returns the total reference evapotranspiration at site. Hours that have
+// neither solar radiation nor cloud cover are skipped.
+func (hours Hours) ET0(site Site) (total units.Precipitation) {
+	for _, h := range hours {
+		if et0, ok := HourlyET0(site, h); ok {
+			total += et0
+		}
+	}
+	return total
+}
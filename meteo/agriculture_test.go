@@ -0,0 +1,136 @@
+package meteo
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/wirepair/wug/units"
+)
+
+func TestDegreeDays(t *testing.T) {
+	base := units.Fahrenheit(65)
+	if hdd := HeatingDegreeDays(units.Fahrenheit(50), units.Fahrenheit(30), base); math.Abs(hdd.Fahrenheit()-25) > 1e-9 {
+		t.Fatalf("expected 25 heating degree days got %v\n", hdd.Fahrenheit())
+	}
+
+	if cdd := CoolingDegreeDays(units.Fahrenheit(50), units.Fahrenheit(30), base); cdd != 0 {
+		t.Fatalf("expected no cooling degree days got %v\n", cdd)
+	}
+
+	if cdd := CoolingDegreeDays(units.Fahrenheit(90), units.Fahrenheit(70), base); math.Abs(cdd.Fahrenheit()-15) > 1e-9 {
+		t.Fatalf("expected 15 cooling degree days got %v\n", cdd.Fahrenheit())
+	}
+
+	// corn, base 50°F capped at 86°F
+	gddBase, gddCap := units.Fahrenheit(50), units.Fahrenheit(86)
+	var tests = []struct {
+		high, low float64
+		method    GDDMethod
+		expected  float64
+	}{
+		{80, 60, GDDAverage, 20},
+		{95, 60, GDDAverage, 23},
+		{80, 40, GDDAverage, 10},
+		{80, 40, GDDModified, 15},
+		{95, 40, GDDModified, 18},
+		{45, 30, GDDModified, 0},
+	}
+
+	for _, tt := range tests {
+		gdd := GrowingDegreeDays(units.Fahrenheit(tt.high), units.Fahrenheit(tt.low), gddBase, gddCap, tt.method)
+		if math.Abs(gdd.Fahrenheit()-tt.expected) > 1e-9 {
+			t.Fatalf("%v/%v method %d: expected %v got %v\n", tt.high, tt.low, tt.method, tt.expected, gdd.Fahrenheit())
+		}
+	}
+
+	days := Days{
+		{High: units.Fahrenheit(50), Low: units.Fahrenheit(30)},
+		{High: units.Fahrenheit(60), Low: units.Fahrenheit(40)},
+	}
+	if hdd := days.HeatingDegreeDays(base); math.Abs(hdd.Fahrenheit()-40) > 1e-9 {
+		t.Fatalf("expected 40 total heating degree days got %v\n", hdd.Fahrenheit())
+	}
+}
+
+func TestChillHours(t *testing.T) {
+	hours := Hours{
+		{Temperature: units.Fahrenheit(30)},
+		{Temperature: units.Fahrenheit(32)},
+		{Temperature: units.Fahrenheit(40)},
+		{Temperature: units.Fahrenheit(45)},
+		{Temperature: units.Fahrenheit(50)},
+	}
+
+	if chill := hours.ChillHours(); chill != 3 {
+		t.Fatalf("expected 3 chill hours got %d\n", chill)
+	}
+}
+
+func TestDailyET0(t *testing.T) {
+	// FAO-56 example 18, Brussels on 6 July
+	rhMax, rhMin, rs := 84.0, 63.0, 22.07
+	wind := units.MetersPerSecond(2.078)
+	site := Site{Latitude: 50.8, Elevation: units.Meters(100)}
+	day := DailyWeather{
+		Date:           time.Date(2017, time.July, 6, 0, 0, 0, 0, time.UTC),
+		High:           units.Celsius(21.5),
+		Low:            units.Celsius(12.3),
+		HumidityMax:    &rhMax,
+		HumidityMin:    &rhMin,
+		Wind:           &wind,
+		SolarRadiation: &rs,
+	}
+
+	if et0 := DailyET0(site, day); math.Abs(et0.Millimeters()-3.9) > 0.05 {
+		t.Fatalf("expected 3.9 mm got %v\n", et0.Millimeters())
+	}
+
+	// estimated radiation should land in the same range
+	day.SolarRadiation = nil
+	if et0 := DailyET0(site, day); et0.Millimeters() < 2.5 || et0.Millimeters() > 4.5 {
+		t.Fatalf("unexpected estimated et0 %v\n", et0.Millimeters())
+	}
+
+	if wind := WindAt2m(units.MetersPerSecond(3.2), units.Meters(10)); math.Abs(wind.MetersPerSecond()-2.4) > 0.01 {
+		t.Fatalf("expected 2.4 m/s got %v\n", wind.MetersPerSecond())
+	}
+}
+
+func TestHourlyET0(t *testing.T) {
+	// FAO-56 example 19, N'Diaye Senegal on 1 October
+	site := Site{Latitude: 16.2, Longitude: -16.25, Elevation: units.Meters(8)}
+	rs := 2.450
+	wind := units.MetersPerSecond(3.3)
+	hour := HourlyWeather{
+		Time:           time.Date(2017, time.October, 1, 14, 0, 0, 0, time.UTC),
+		Temperature:    units.Celsius(38),
+		Humidity:       52,
+		Wind:           &wind,
+		SolarRadiation: &rs,
+	}
+
+	et0, ok := HourlyET0(site, hour)
+	if !ok || math.Abs(et0.Millimeters()-0.63) > 0.02 {
+		t.Fatalf("expected 0.63 mm got %v %v\n", et0.Millimeters(), ok)
+	}
+
+	night := 0.0
+	wind = units.MetersPerSecond(1.9)
+	hour = HourlyWeather{
+		Time:           time.Date(2017, time.October, 1, 2, 0, 0, 0, time.UTC),
+		Temperature:    units.Celsius(28),
+		Humidity:       90,
+		Wind:           &wind,
+		SolarRadiation: &night,
+	}
+
+	et0, ok = HourlyET0(site, hour)
+	if !ok || et0.Millimeters() > 0.05 {
+		t.Fatalf("expected no et0 at night got %v %v\n", et0.Millimeters(), ok)
+	}
+
+	if _, ok := HourlyET0(site, HourlyWeather{Temperature: units.Celsius(20)}); ok {
+		t.Fatalf("expected no et0 without radiation or cloud cover\n")
+	}
+}
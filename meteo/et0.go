@@ -0,0 +1,177 @@
+package meteo
+
+import (
+	"math"
+	"time"
+
+	"github.com/wirepair/wug/units"
+)
+
+// Site is the location evapotranspiration is computed for
+type Site struct {
+	Latitude  float64 // degrees north
+	Longitude float64 // degrees east
+	Elevation units.Length
+}
+
+// FAO-56 constants
+const (
+	solarConstant    = 0.0820    // MJ/(m²·min)
+	stefanBoltzmann  = 4.903e-9  // MJ/(K⁴·m²·day)
+	hargreavesKRs    = 0.16      // interior location adjustment
+	defaultWindSpeed = 2.0       // m/s, used when wind is unknown
+	hourlyBoltzmann  = 2.043e-10 // MJ/(K⁴·m²·hour)
+	nightRatio       = 0.5       // Rs/Rso assumed at night without cloud cover
+)
+
+// WindAt2m converts a wind speed v measured at height above ground to the
+// speed at 2 m using the FAO-56 logarithmic wind profile.
+func WindAt2m(v units.Speed, height units.Length) units.Speed {
+	return units.MetersPerSecond(v.MetersPerSecond() * 4.87 / math.Log(67.8*height.Meters()-5.42))
+}
+
+// saturation returns the FAO-56 saturation vapor pressure in kPa at c °C.
+func saturation(c float64) float64 {
+	return 0.6108 * math.Exp(17.27*c/(c+237.3))
+}
+
+// slope returns the slope of the saturation vapor pressure curve in kPa/°C.
+func slope(c float64) float64 {
+	return 4098 * saturation(c) / math.Pow(c+237.3, 2)
+}
+
+// psychrometric returns the psychrometric constant in kPa/°C at elevation z.
+func psychrometric(z float64) float64 {
+	p := 101.3 * math.Pow((293-0.0065*z)/293, 5.26)
+	return 0.000665 * p
+}
+
+// solarGeometry returns the inverse relative earth sun distance and solar
+// declination of the day of year.
+func solarGeometry(t time.Time) (dr, declination float64) {
+	j := float64(t.YearDay())
+	dr = 1 + 0.033*math.Cos(2*math.Pi/365*j)
+	declination = 0.409 * math.Sin(2*math.Pi/365*j-1.39)
+	return dr, declination
+}
+
+// sunsetAngle returns the sunset hour angle for latitude phi and declination.
+func sunsetAngle(phi, declination float64) float64 {
+	return math.Acos(math.Max(-1, math.Min(1, -math.Tan(phi)*math.Tan(declination))))
+}
+
+// withWind returns the wind speed or the FAO-56 default when unknown.
+func withWind(wind *units.Speed) float64 {
+	if wind == nil {
+		return defaultWindSpeed
+	}
+	return wind.MetersPerSecond()
+}
+
+// DailyET0 returns the FAO-56 Penman-Monteith reference evapotranspiration
+// of a day at site. Solar radiation is estimated from the temperature range
+// with the Hargreaves formula when unknown, and the actual vapor pressure from
+// the low temperature when humidity is unknown.
+func DailyET0(site Site, d DailyWeather) units.Precipitation {
+	tmax, tmin := d.High.Celsius(), d.Low.Celsius()
+	tmean := (tmax + tmin) / 2
+	z := site.Elevation.Meters()
+
+	es := (saturation(tmax) + saturation(tmin)) / 2
+	var ea float64
+	switch {
+	case d.HumidityMax != nil && d.HumidityMin != nil:
+		ea = (saturation(tmin)**d.HumidityMax/100 + saturation(tmax)**d.HumidityMin/100) / 2
+	case d.Humidity != nil:
+		ea = *d.Humidity / 100 * es
+	default:
+		ea = saturation(tmin)
+	}
+
+	phi := site.Latitude * math.Pi / 180
+	dr, declination := solarGeometry(d.Date)
+	ws := sunsetAngle(phi, declination)
+	ra := 24 * 60 / math.Pi * solarConstant * dr *
+		(ws*math.Sin(phi)*math.Sin(declination) + math.Cos(phi)*math.Cos(declination)*math.Sin(ws))
+	rso := (0.75 + 2e-5*z) * ra
+
+	rs := hargreavesKRs * math.Sqrt(math.Max(tmax-tmin, 0)) * ra
+	if d.SolarRadiation != nil {
+		rs = *d.SolarRadiation
+	}
+
+	ratio := 0.0
+	if rso > 0 {
+		ratio = math.Min(rs/rso, 1)
+	}
+	rnl := stefanBoltzmann * (math.Pow(tmax+273.16, 4) + math.Pow(tmin+273.16, 4)) / 2 *
+		(0.34 - 0.14*math.Sqrt(ea)) * (1.35*ratio - 0.35)
+	rn := 0.77*rs - rnl
+
+	u2 := withWind(d.Wind)
+	delta, gamma := slope(tmean), psychrometric(z)
+	et0 := (0.408*delta*rn + gamma*900/(tmean+273)*u2*(es-ea)) / (delta + gamma*(1+0.34*u2))
+	return units.Millimeters(math.Max(et0, 0))
+}
+
+// HourlyET0 returns the FAO-56 Penman-Monteith reference evapotranspiration
+// of an hour at site. Solar radiation is estimated from cloud cover when
+// unknown, ok is false if neither is known.
+func HourlyET0(site Site, h HourlyWeather) (et0 units.Precipitation, ok bool) {
+	if h.SolarRadiation == nil && h.CloudCover == nil {
+		return 0, false
+	}
+
+	t := h.Temperature.Celsius()
+	z := site.Elevation.Meters()
+	e0 := saturation(t)
+	ea := e0 * h.Humidity / 100
+
+	// solar time angle at the midpoint of the hour
+	phi := site.Latitude * math.Pi / 180
+	dr, declination := solarGeometry(h.Time)
+	b := 2 * math.Pi * float64(h.Time.YearDay()-81) / 364
+	sc := 0.1645*math.Sin(2*b) - 0.1255*math.Cos(b) - 0.025*math.Sin(b)
+	_, offset := h.Time.Zone()
+	lz, lm := -float64(offset)/3600*15, -site.Longitude
+	mid := float64(h.Time.Hour()) + float64(h.Time.Minute())/60 + 0.5
+	w := math.Pi / 12 * ((mid + 0.06667*(lz-lm) + sc) - 12)
+
+	ws := sunsetAngle(phi, declination)
+	w1 := math.Max(w-math.Pi/24, -ws)
+	w2 := math.Min(w+math.Pi/24, ws)
+	day := w1 < w2
+
+	ra := 0.0
+	if day {
+		ra = 12 * 60 / math.Pi * solarConstant * dr *
+			((w2-w1)*math.Sin(phi)*math.Sin(declination) + math.Cos(phi)*math.Cos(declination)*(math.Sin(w2)-math.Sin(w1)))
+	}
+	rso := (0.75 + 2e-5*z) * ra
+
+	ratio := nightRatio
+	if h.CloudCover != nil {
+		ratio = 1 - 0.75*math.Pow(*h.CloudCover/100, 3.4)
+	}
+
+	rs := ratio * rso
+	if h.SolarRadiation != nil {
+		rs = *h.SolarRadiation
+		if rso > 0 {
+			ratio = math.Min(rs/rso, 1)
+		}
+	}
+
+	rnl := hourlyBoltzmann * math.Pow(t+273.16, 4) * (0.34 - 0.14*math.Sqrt(ea)) * (1.35*ratio - 0.35)
+	rn := 0.77*rs - rnl
+
+	g := 0.5 * rn
+	if day {
+		g = 0.1 * rn
+	}
+
+	u2 := withWind(h.Wind)
+	delta, gamma := slope(t), psychrometric(z)
+	value := (0.408*delta*(rn-g) + gamma*37/(t+273)*u2*(e0-ea)) / (delta + gamma*(1+0.34*u2))
+	return units.Millimeters(math.Max(value, 0)), true
+}
@@ -0,0 +1,162 @@
+// Package meteo computes derived meteorological values such as heat index,
+// wind chill and dew point from basic measurements.
+package meteo
+
+import (
+	"math"
+
+	"github.com/wirepair/wug/units"
+)
+
+// Source of a value that may be reported by the API or computed
+type Source int
+
+// Source constants
+const (
+	SourceNone     Source = iota // neither reported nor computable
+	SourceAPI                    // reported by the API
+	SourceComputed               // computed from other measurements
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceAPI:
+		return "api"
+	case SourceComputed:
+		return "computed"
+	}
+	return "none"
+}
+
+// Magnus formula coefficients (Alduchov and Eskridge 1996)
+const (
+	magnusA = 17.625
+	magnusB = 243.04 // °C
+)
+
+// SaturationVaporPressure returns the saturation vapor pressure over water at
+// temperature t.
+func SaturationVaporPressure(t units.Temperature) units.Pressure {
+	c := t.Celsius()
+	return units.Hectopascals(6.1094 * math.Exp(magnusA*c/(magnusB+c)))
+}
+
+// HeatIndex returns the NWS heat index for temperature t and relative
+// humidity rh (percent) using the Rothfusz regression, with the simple
+// formula and adjustments the NWS applies outside its range.
+func HeatIndex(t units.Temperature, rh float64) units.Temperature {
+	f := t.Fahrenheit()
+	simple := 0.5 * (f + 61.0 + (f-68.0)*1.2 + rh*0.094)
+	if (simple+f)/2 < 80 {
+		return units.Fahrenheit(simple)
+	}
+
+	hi := -42.379 + 2.04901523*f + 10.14333127*rh - 0.22475541*f*rh -
+		0.00683783*f*f - 0.05481717*rh*rh + 0.00122874*f*f*rh +
+		0.00085282*f*rh*rh - 0.00000199*f*f*rh*rh
+
+	switch {
+	case rh < 13 && f >= 80 && f <= 112:
+		hi -= (13 - rh) / 4 * math.Sqrt((17-math.Abs(f-95))/17)
+	case rh > 85 && f >= 80 && f <= 87:
+		hi += (rh - 85) / 10 * (87 - f) / 5
+	}
+	return units.Fahrenheit(hi)
+}
+
+// HeatIndexApplies reports whether the heat index is meaningful for
+// temperature t, at or above 80°F.
+func HeatIndexApplies(t units.Temperature) bool {
+	return t.Fahrenheit() >= 80
+}
+
+// WindChillApplies reports whether wind chill is defined for temperature t
+// and wind speed v, at or below 50°F with winds above 3 mph.
+func WindChillApplies(t units.Temperature, v units.Speed) bool {
+	return t.Fahrenheit() <= 50 && v.MilesPerHour() > 3
+}
+
+// WindChill returns the NWS wind chill for temperature t and wind speed v.
+// Outside of where wind chill is defined t is returned, see WindChillApplies.
+func WindChill(t units.Temperature, v units.Speed) units.Temperature {
+	if !WindChillApplies(t, v) {
+		return t
+	}
+
+	f, mph := t.Fahrenheit(), v.MilesPerHour()
+	p := math.Pow(mph, 0.16)
+	return units.Fahrenheit(35.74 + 0.6215*f - 35.75*p + 0.4275*f*p)
+}
+
+// ApparentTemperature returns the Steadman apparent temperature (as used by
+// the Australian Bureau of Meteorology) for temperature t, relative humidity
+// rh (percent) and wind speed v, without solar radiation.
+func ApparentTemperature(t units.Temperature, rh float64, v units.Speed) units.Temperature {
+	e := rh / 100 * SaturationVaporPressure(t).Hectopascals()
+	return units.Celsius(t.Celsius() + 0.33*e - 0.70*v.MetersPerSecond() - 4.00)
+}
+
+// FeelsLike returns the temperature as weather underground reports it: the
+// heat index when hot, the wind chill when cold and windy, otherwise t.
+func FeelsLike(t units.Temperature, rh float64, v units.Speed) units.Temperature {
+	switch {
+	case HeatIndexApplies(t):
+		return HeatIndex(t, rh)
+	case WindChillApplies(t, v):
+		return WindChill(t, v)
+	}
+	return t
+}
+
+// DewPoint returns the dew point for temperature t and relative humidity rh
+// (percent, above 0) using the Magnus formula.
+func DewPoint(t units.Temperature, rh float64) units.Temperature {
+	c := t.Celsius()
+	gamma := math.Log(rh/100) + magnusA*c/(magnusB+c)
+	return units.Celsius(magnusB * gamma / (magnusA - gamma))
+}
+
+// RelativeHumidity returns the relative humidity (percent) for temperature t
+// and dew point td using the Magnus formula.
+func RelativeHumidity(t, td units.Temperature) float64 {
+	rh := 100 * SaturationVaporPressure(td).Hectopascals() / SaturationVaporPressure(t).Hectopascals()
+	return math.Min(rh, 100)
+}
+
+// WetBulb returns the wet bulb temperature for temperature t and relative
+// humidity rh (percent) using the Stull (2011) approximation, valid for
+// rh between 5% and 99% and t between -20°C and 50°C at sea level pressure.
+func WetBulb(t units.Temperature, rh float64) units.Temperature {
+	c := t.Celsius()
+	return units.Celsius(c*math.Atan(0.151977*math.Sqrt(rh+8.313659)) +
+		math.Atan(c+rh) - math.Atan(rh-1.676331) +
+		0.00391838*math.Pow(rh, 1.5)*math.Atan(0.023101*rh) - 4.686035)
+}
+
+// Humidex returns the Canadian humidex for temperature t and dew point td.
+func Humidex(t, td units.Temperature) units.Temperature {
+	e := 6.11 * math.Exp(5417.7530*(1/273.16-1/td.Kelvin()))
+	return units.Celsius(t.Celsius() + 0.5555*(e-10))
+}
+
+// CloudBase estimates the height above ground of the base of convective
+// clouds from temperature t and dew point td, using a lapse rate of the
+// spread of 8°C per 1000 m.
+func CloudBase(t, td units.Temperature) units.Length {
+	spread := math.Max(t.Celsius()-td.Celsius(), 0)
+	return units.Meters(spread * 125)
+}
+
+// Gas constants for dry air and water vapor in J/(kg·K)
+const (
+	dryAirConstant     = 287.058
+	waterVaporConstant = 461.495
+)
+
+// AirDensity returns the density of humid air in kg/m³ for temperature t,
+// station pressure p and relative humidity rh (percent).
+func AirDensity(t units.Temperature, p units.Pressure, rh float64) float64 {
+	vapor := rh / 100 * SaturationVaporPressure(t).Pascals()
+	dry := p.Pascals() - vapor
+	return dry/(dryAirConstant*t.Kelvin()) + vapor/(waterVaporConstant*t.Kelvin())
+}
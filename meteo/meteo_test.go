@@ -0,0 +1,57 @@
+package meteo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/wirepair/wug/units"
+)
+
+func TestMeteo(t *testing.T) {
+	var tests = []struct {
+		name      string
+		got, want float64
+		tolerance float64
+	}{
+		// NWS heat index chart values
+		{"heat index 90F 50%", HeatIndex(units.Fahrenheit(90), 50).Fahrenheit(), 95, 1},
+		{"heat index 100F 60%", HeatIndex(units.Fahrenheit(100), 60).Fahrenheit(), 129, 1},
+		{"heat index 104F 10%", HeatIndex(units.Fahrenheit(104), 10).Fahrenheit(), 98, 1.5},
+		{"heat index 70F 50%", HeatIndex(units.Fahrenheit(70), 50).Fahrenheit(), 69.4, 1},
+		// NWS wind chill chart values
+		{"wind chill 0F 15mph", WindChill(units.Fahrenheit(0), units.MilesPerHour(15)).Fahrenheit(), -19, 0.5},
+		{"wind chill 30F 10mph", WindChill(units.Fahrenheit(30), units.MilesPerHour(10)).Fahrenheit(), 21, 0.5},
+		{"wind chill calm", WindChill(units.Fahrenheit(30), units.MilesPerHour(2)).Fahrenheit(), 30, 1e-9},
+		{"wind chill warm", WindChill(units.Fahrenheit(60), units.MilesPerHour(20)).Fahrenheit(), 60, 1e-9},
+		{"apparent 30C 50% 2m/s", ApparentTemperature(units.Celsius(30), 50, units.MetersPerSecond(2)).Celsius(), 31.6, 0.2},
+		{"feels like hot", FeelsLike(units.Fahrenheit(90), 50, units.MilesPerHour(5)).Fahrenheit(), 95, 1},
+		{"feels like cold", FeelsLike(units.Fahrenheit(0), 50, units.MilesPerHour(15)).Fahrenheit(), -19, 0.5},
+		{"feels like mild", FeelsLike(units.Fahrenheit(65), 50, units.MilesPerHour(15)).Fahrenheit(), 65, 1e-9},
+		{"dew point 20C 50%", DewPoint(units.Celsius(20), 50).Celsius(), 9.3, 0.1},
+		{"dew point 30C 100%", DewPoint(units.Celsius(30), 100).Celsius(), 30, 1e-9},
+		{"humidity 20C 9.3C", RelativeHumidity(units.Celsius(20), units.Celsius(9.26)), 50, 0.2},
+		{"wet bulb 20C 50%", WetBulb(units.Celsius(20), 50).Celsius(), 13.7, 0.1},
+		{"humidex 30C 15C", Humidex(units.Celsius(30), units.Celsius(15)).Celsius(), 33.8, 0.2},
+		{"cloud base 20C 12C", CloudBase(units.Celsius(20), units.Celsius(12)).Meters(), 1000, 1e-9},
+		{"air density standard", AirDensity(units.Celsius(15), units.Hectopascals(1013.25), 0), 1.225, 0.001},
+		{"air density humid", AirDensity(units.Celsius(30), units.Hectopascals(1013.25), 80), 1.1487, 0.002},
+	}
+
+	for _, tt := range tests {
+		if math.Abs(tt.got-tt.want) > tt.tolerance {
+			t.Fatalf("%s: expected %v got %v\n", tt.name, tt.want, tt.got)
+		}
+	}
+
+	if math.Abs(RelativeHumidity(units.Celsius(20), DewPoint(units.Celsius(20), 73))-73) > 1e-9 {
+		t.Fatalf("dew point and humidity do not round trip")
+	}
+
+	if HeatIndexApplies(units.Fahrenheit(79.9)) || !HeatIndexApplies(units.Fahrenheit(80)) {
+		t.Fatalf("expected the heat index to apply from 80F")
+	}
+
+	if WindChillApplies(units.Fahrenheit(50.1), units.MilesPerHour(10)) || WindChillApplies(units.Fahrenheit(30), units.MilesPerHour(2.9)) || !WindChillApplies(units.Fahrenheit(49), units.MilesPerHour(3.1)) {
+		t.Fatalf("expected wind chill at or below 50F with winds above 3 mph")
+	}
+}
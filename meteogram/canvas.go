@@ -0,0 +1,30 @@
+package meteogram
+
+import (
+	"image/color"
+)
+
+// point in pixels, y grows downwards
+type point struct {
+	X, Y float64
+}
+
+// anchor of text relative to its point
+type anchor int
+
+// anchor constants
+const (
+	anchorStart anchor = iota
+	anchorMiddle
+	anchorEnd
+)
+
+// canvas is drawn on by the layout, implemented by the SVG writer and the
+// image rasterizer. Colours with an alpha below 255 are blended.
+type canvas interface {
+	rect(x, y, w, h float64, fill color.NRGBA)
+	polyline(points []point, stroke color.NRGBA, width float64, dashed bool)
+	polygon(points []point, fill color.NRGBA)
+	circle(c point, r float64, fill, stroke color.NRGBA) // alpha 0 is none
+	text(p point, s string, fill color.NRGBA, a anchor)  // p is the baseline
+}
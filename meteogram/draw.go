@@ -0,0 +1,475 @@
+package meteogram
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"time"
+
+	"github.com/wirepair/wug"
+	"github.com/wirepair/wug/units"
+)
+
+// Margins around the plot in pixels
+const (
+	marginLeft   = 52
+	marginRight  = 16
+	marginTop    = 28
+	marginBottom = 22
+	panelGap     = 10
+	symbolRow    = 30 // height of the cloud and condition row
+	windRow      = 34 // height of the wind barb row
+)
+
+// band is a horizontal panel of the plot
+type band struct {
+	top, bottom float64
+}
+
+// scale maps values of a band to y pixels
+type scale struct {
+	band
+	lo, hi, step float64
+}
+
+func (s scale) y(v float64) float64 {
+	if s.hi <= s.lo {
+		return (s.top + s.bottom) / 2
+	}
+	return s.bottom - (v-s.lo)/(s.hi-s.lo)*(s.bottom-s.top)
+}
+
+// layout of a meteogram being drawn
+type layout struct {
+	m      *Meteogram
+	opts   Options
+	theme  *Theme
+	c      canvas
+	left   float64
+	right  float64
+	top    float64
+	bottom float64
+	start  time.Time // start of the first hour
+	end    time.Time // end of the last hour
+}
+
+// x returns the pixel of a time.
+func (l *layout) x(t time.Time) float64 {
+	return l.left + t.Sub(l.start).Seconds()/l.end.Sub(l.start).Seconds()*(l.right-l.left)
+}
+
+// hourWidth returns the width of an hour in pixels.
+func (l *layout) hourWidth() float64 {
+	return (l.right - l.left) / l.end.Sub(l.start).Hours()
+}
+
+// every returns how many hours apart marks at least px pixels wide are.
+func (l *layout) every(px float64) int {
+	for _, n := range []int{1, 2, 3, 6, 12, 24, 48} {
+		if float64(n)*l.hourWidth() >= px {
+			return n
+		}
+	}
+	return 96
+}
+
+// values returns a value of every hour converted for the axes.
+func (l *layout) values(get func(h Hour) float64, convert func(v float64) float64) []float64 {
+	values := make([]float64, len(l.m.Hours))
+	for i, h := range l.m.Hours {
+		values[i] = get(h)
+		if !math.IsNaN(values[i]) {
+			values[i] = convert(values[i])
+		}
+	}
+	return values
+}
+
+// draw the meteogram on the canvas, the options have their defaults.
+func (m *Meteogram) draw(c canvas, opts Options) {
+	l := &layout{
+		m:      m,
+		opts:   opts,
+		theme:  opts.Theme,
+		c:      c,
+		left:   marginLeft,
+		right:  float64(opts.Width - marginRight),
+		top:    marginTop,
+		bottom: float64(opts.Height - marginBottom),
+		start:  m.Hours[0].Time.In(opts.Location),
+		end:    m.Hours[len(m.Hours)-1].Time.In(opts.Location).Add(time.Hour),
+	}
+
+	// bands top to bottom, the rest is shared by the charts
+	rest := l.bottom - l.top - symbolRow - windRow - 4*panelGap
+	symbols := band{l.top, l.top + symbolRow}
+	temperature := band{symbols.bottom + panelGap, symbols.bottom + panelGap + rest*0.48}
+	precipitation := band{temperature.bottom + panelGap, temperature.bottom + panelGap + rest*0.24}
+	wind := band{precipitation.bottom + panelGap, precipitation.bottom + panelGap + windRow}
+	pressure := band{wind.bottom + panelGap, l.bottom}
+
+	c.rect(0, 0, float64(opts.Width), float64(opts.Height), l.theme.Background)
+	l.drawNights()
+	l.drawTimeAxis()
+	l.drawSymbols(symbols)
+	l.drawTemperature(temperature)
+	l.drawPrecipitation(precipitation)
+	l.drawWind(wind)
+	l.drawPressure(pressure)
+}
+
+// sun returns the wall clock sunrise and sunset of the days in minutes
+// since midnight.
+func (l *layout) sun() (rise, set int) {
+	rise, set = 6*60, 18*60
+	if a := l.m.Astronomy; a != nil {
+		if h, m, ok := a.SunPhase.Sunrise.Clock(); ok {
+			rise = h*60 + m
+		}
+		if h, m, ok := a.SunPhase.Sunset.Clock(); ok {
+			set = h*60 + m
+		}
+	}
+	return rise, set
+}
+
+func (l *layout) drawNights() {
+	if len(l.m.Days) > 0 {
+		l.drawForecastNights()
+		return
+	}
+
+	hw := l.hourWidth()
+	for i := 0; i < len(l.m.Hours); i++ {
+		if !l.m.Hours[i].Night {
+			continue
+		}
+
+		// merge consecutive night hours into one rect
+		j := i
+		for j+1 < len(l.m.Hours) && l.m.Hours[j+1].Night {
+			j++
+		}
+
+		x := l.x(l.m.Hours[i].Time)
+		l.c.rect(x, l.top, l.x(l.m.Hours[j].Time)+hw-x, l.bottom-l.top, l.theme.Night)
+		i = j
+	}
+}
+
+// drawForecastNights shades the nights of the days of the ten day forecast,
+// midnight to sunrise and sunset to midnight.
+func (l *layout) drawForecastNights() {
+	loc := l.opts.Location
+	rise, set := l.sun()
+	days := make(map[string]bool)
+	for _, d := range l.m.Days {
+		if t, err := d.ForecastDay.Time(); err == nil {
+			days[t.In(loc).Format("2006-01-02")] = true
+		}
+	}
+
+	var from time.Time // start of the night being shaded, zero if none
+	shade := func(to time.Time) {
+		if !from.IsZero() {
+			x := l.x(maxTime(from, l.start))
+			l.c.rect(x, l.top, l.x(minTime(to, l.end))-x, l.bottom-l.top, l.theme.Night)
+		}
+		from = time.Time{}
+	}
+
+	for day := time.Date(l.start.Year(), l.start.Month(), l.start.Day(), 0, 0, 0, 0, loc); day.Before(l.end); day = day.AddDate(0, 0, 1) {
+		if !days[day.Format("2006-01-02")] {
+			shade(day)
+			continue
+		}
+
+		// nights continue from the previous day
+		sunrise := time.Date(day.Year(), day.Month(), day.Day(), 0, rise, 0, 0, loc)
+		sunset := time.Date(day.Year(), day.Month(), day.Day(), 0, set, 0, 0, loc)
+		if from.IsZero() {
+			from = day
+		}
+
+		if sunrise.After(l.start) {
+			shade(sunrise)
+		} else {
+			from = time.Time{}
+		}
+
+		if sunset.Before(l.end) {
+			from = sunset
+		}
+	}
+	shade(l.end)
+}
+
+// drawTimeAxis draws hour ticks along the bottom and separates and labels
+// the days along the top, in the zone of the options.
+func (l *layout) drawTimeAxis() {
+	loc := l.opts.Location
+	step := l.every(30)
+
+	// ticks are on the wall clock hours of the zone, which are not whole
+	// hours of absolute time in zones like Asia/Kolkata
+	for h := 0; ; h++ {
+		t := time.Date(l.start.Year(), l.start.Month(), l.start.Day(), l.start.Hour()+h, 0, 0, 0, loc)
+		if t.After(l.end) {
+			break
+		}
+
+		if t.Hour()%step != 0 || t.Before(l.start) {
+			continue
+		}
+
+		x := l.x(t)
+		if t.Hour() == 0 {
+			midnight := l.theme.Foreground
+			midnight.A /= 2
+			l.c.polyline([]point{{x, l.top - 4}, {x, l.bottom}}, midnight, 1, false)
+		} else {
+			l.c.polyline([]point{{x, l.top}, {x, l.bottom}}, l.theme.Grid, 1, false)
+		}
+
+		if step < 24 {
+			l.c.text(point{x, l.bottom + 14}, fmt.Sprintf("%02d", t.Hour()), l.theme.Foreground, anchorMiddle)
+		}
+	}
+	l.c.polyline([]point{{l.left, l.bottom}, {l.right, l.bottom}}, l.theme.Foreground, 1, false)
+
+	for day := time.Date(l.start.Year(), l.start.Month(), l.start.Day(), 0, 0, 0, 0, loc); day.Before(l.end); day = day.AddDate(0, 0, 1) {
+		from, to := l.x(maxTime(day, l.start)), l.x(minTime(day.AddDate(0, 0, 1), l.end))
+		highLow := l.highLow(day)
+		for _, label := range []string{day.Format("Monday 2") + highLow, day.Format("Mon 2") + highLow, day.Format("Mon 2"), day.Format("2")} {
+			if float64(textWidth(label)+8) <= to-from {
+				l.c.text(point{(from + to) / 2, l.top - 10}, label, l.theme.Foreground, anchorMiddle)
+				break
+			}
+		}
+	}
+}
+
+// highLow returns the forecast high and low of the day, if a ten day
+// forecast was given.
+func (l *layout) highLow(day time.Time) string {
+	for i := range l.m.Days {
+		d := &l.m.Days[i]
+		t, err := d.ForecastDay.Time()
+		if err != nil || t.In(l.opts.Location).Format("2006-01-02") != day.Format("2006-01-02") {
+			continue
+		}
+
+		high, okHigh := d.HighTemp()
+		low, okLow := d.LowTemp()
+		if okHigh && okLow {
+			h, unit := high.In(l.opts.System)
+			lo, _ := low.In(l.opts.System)
+			return fmt.Sprintf("  %.0f%s / %.0f%s", h, unit, lo, unit)
+		}
+	}
+	return ""
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// niceScale returns a scale of the band covering the values with round
+// steps, at least span wide.
+func niceScale(b band, values []float64, span float64, ticks int) scale {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+	}
+
+	if math.IsInf(lo, 1) {
+		lo, hi = 0, span
+	}
+
+	if hi-lo < span {
+		mid := (hi + lo) / 2
+		lo, hi = mid-span/2, mid+span/2
+	}
+
+	raw := (hi - lo) / float64(ticks)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := magnitude * 10
+	for _, f := range []float64{1, 2, 2.5, 5, 10} {
+		if f*magnitude >= raw {
+			step = f * magnitude
+			break
+		}
+	}
+	return scale{band: b, lo: math.Floor(lo/step) * step, hi: math.Ceil(hi/step) * step, step: step}
+}
+
+// drawAxis draws the grid lines and labels of a scale and the title of its
+// band.
+func (l *layout) drawAxis(s scale, title string) {
+	decimals := 0
+	if s.step < 1 {
+		decimals = int(math.Ceil(-math.Log10(s.step)))
+	}
+
+	for v := s.lo; v <= s.hi+s.step/2; v += s.step {
+		y := s.y(v)
+		l.c.polyline([]point{{l.left, y}, {l.right, y}}, l.theme.Grid, 1, false)
+		l.c.text(point{l.left - 6, y + 4}, fmt.Sprintf("%.*f", decimals, v), l.theme.Foreground, anchorEnd)
+	}
+	l.c.text(point{l.left + 4, s.top + 11}, title, l.theme.Foreground, anchorStart)
+}
+
+// drawLine draws the values as a line centred on each hour, missing values
+// break the line.
+func (l *layout) drawLine(s scale, values []float64, stroke color.NRGBA, width float64) {
+	hw := l.hourWidth()
+	var points []point
+	flush := func() {
+		if len(points) == 1 {
+			l.c.circle(points[0], width, stroke, stroke)
+		} else if len(points) > 1 {
+			l.c.polyline(points, stroke, width, false)
+		}
+		points = nil
+	}
+
+	for i, v := range values {
+		if math.IsNaN(v) {
+			flush()
+			continue
+		}
+		points = append(points, point{l.x(l.m.Hours[i].Time) + hw/2, s.y(v)})
+	}
+	flush()
+}
+
+func (l *layout) drawTemperature(b band) {
+	system := l.opts.System
+	convert := func(v float64) float64 {
+		t, _ := units.Celsius(v).In(system)
+		return t
+	}
+
+	temps := l.values(func(h Hour) float64 { return h.Temperature }, convert)
+	dews := l.values(func(h Hour) float64 { return h.Dewpoint }, convert)
+	_, unit := units.Celsius(0).In(system)
+
+	s := niceScale(b, append(append([]float64{}, temps...), dews...), 5, 5)
+	l.drawAxis(s, "Temperature / dew point "+unit)
+
+	if freezing := convert(0); freezing > s.lo && freezing < s.hi {
+		l.c.polyline([]point{{l.left, s.y(freezing)}, {l.right, s.y(freezing)}}, l.theme.Freezing, 1, true)
+	}
+
+	l.drawLine(s, dews, l.theme.Dewpoint, 1.5)
+	l.drawLine(s, temps, l.theme.Temperature, 2)
+}
+
+func (l *layout) drawPrecipitation(b band) {
+	convert := func(v float64) float64 {
+		p, _ := units.Millimeters(v).In(l.opts.System)
+		return p
+	}
+
+	amounts := l.values(func(h Hour) float64 { return h.Precipitation }, convert)
+	_, unit := units.Millimeters(0).In(l.opts.System)
+	span := convert(2)
+
+	s := niceScale(b, amounts, span, 2)
+	s.lo = 0
+	l.drawAxis(s, "Precipitation "+unit+", chance %")
+
+	hw := l.hourWidth()
+	for i, h := range l.m.Hours {
+		x := l.x(h.Time)
+		if !math.IsNaN(h.Pop) && h.Pop > 0 {
+			top := b.bottom - h.Pop/100*(b.bottom-b.top)
+			l.c.rect(x+hw*0.1, top, hw*0.8, b.bottom-top, l.theme.Pop)
+		}
+
+		if v := amounts[i]; !math.IsNaN(v) && v > 0 {
+			top := math.Max(s.y(v), b.top)
+			l.c.rect(x+hw*0.2, top, hw*0.6, b.bottom-top, l.theme.Precipitation)
+		}
+	}
+}
+
+func (l *layout) drawPressure(b band) {
+	convert := func(v float64) float64 {
+		p, _ := units.Hectopascals(v).In(l.opts.System)
+		return p
+	}
+
+	values := l.values(func(h Hour) float64 { return h.Pressure }, convert)
+	_, unit := units.Hectopascals(0).In(l.opts.System)
+
+	s := niceScale(b, values, convert(1013.25)-convert(1005.25), 3)
+	l.drawAxis(s, "Pressure "+unit)
+	l.drawLine(s, values, l.theme.Pressure, 1.5)
+}
+
+// drawSymbols draws the cloud cover of every hour and the condition symbol
+// of hours far enough apart.
+func (l *layout) drawSymbols(b band) {
+	hw := l.hourWidth()
+	for _, h := range l.m.Hours {
+		if math.IsNaN(h.CloudCover) || h.CloudCover <= 0 {
+			continue
+		}
+
+		cloud := l.theme.Cloud
+		cloud.A = uint8(math.Min(h.CloudCover, 100) / 100 * 96)
+		l.c.rect(l.x(h.Time), b.top, hw, 6, cloud)
+	}
+
+	step := l.every(26)
+	for i, h := range l.m.Hours {
+		if i%step != 0 {
+			continue
+		}
+		center := point{l.x(h.Time) + hw*float64(step)/2, (b.top + 6 + b.bottom) / 2}
+		l.drawSymbol(center, h.Icon, h.Night)
+	}
+}
+
+// drawWind draws wind barbs of hours far enough apart.
+func (l *layout) drawWind(b band) {
+	l.c.text(point{l.left + 4, b.top + 11}, "Wind kt", l.theme.Foreground, anchorStart)
+	l.c.polyline([]point{{l.left, b.bottom}, {l.right, b.bottom}}, l.theme.Grid, 1, false)
+
+	hw := l.hourWidth()
+	step := l.every(24)
+	for i, h := range l.m.Hours {
+		if i%step != 0 || math.IsNaN(h.WindSpeed) {
+			continue
+		}
+
+		center := point{l.x(h.Time) + hw*float64(step)/2, (b.top + b.bottom) / 2}
+		l.drawBarb(center, units.MetersPerSecond(h.WindSpeed).Knots(), h.WindDirection)
+	}
+}
+
+// icon kinds of condition symbols
+func isSunny(icon wug.Icon) bool {
+	return icon == wug.IconClear || icon == wug.IconSunny
+}
+
+func isPartly(icon wug.Icon) bool {
+	return icon == wug.IconMostlySunny || icon == wug.IconPartlySunny || icon == wug.IconPartlyCloudy
+}
+
+func isFog(icon wug.Icon) bool {
+	return icon == wug.IconFog || icon == wug.IconHazy
+}
@@ -0,0 +1,195 @@
+// Package meteogram draws hourly forecasts as meteograms: temperature and
+// dew point lines, precipitation bars, a cloud and condition row, wind barbs
+// and a pressure line sharing a time axis. Meteograms are written as SVG or
+// drawn into an image with the standard image packages, e.g. for PNG.
+package meteogram
+
+import (
+	"errors"
+	"image/color"
+	"math"
+	"time"
+
+	"github.com/wirepair/wug"
+	"github.com/wirepair/wug/units"
+)
+
+// ErrNoHours is returned when a meteogram has no hours to draw.
+var ErrNoHours = errors.New("meteogram: no hours to draw")
+
+// Default size of a meteogram in pixels
+const (
+	DefaultWidth  = 960
+	DefaultHeight = 540
+)
+
+// Hour is the drawn values of a forecast hour in metric units, NaN when
+// missing
+type Hour struct {
+	Time          time.Time
+	Temperature   float64 // °C
+	Dewpoint      float64 // °C
+	Precipitation float64 // mm, rain and the water of snow
+	Pop           float64 // percent
+	CloudCover    float64 // percent
+	WindSpeed     float64 // m/s
+	WindDirection float64 // degrees the wind blows from
+	Pressure      float64 // hPa
+	Icon          wug.Icon
+	Night         bool // the icon is the night variant
+}
+
+// Meteogram of hourly forecasts
+type Meteogram struct {
+	Hours []Hour
+
+	// Days of a ten day forecast, optional. When set nights of the days are
+	// shaded from sunset to sunrise and each day is labelled with its high
+	// and low, otherwise nights are shaded from the icons of the hours.
+	Days []wug.DailyView
+
+	// Astronomy of the location, optional. The sunrise and sunset of the
+	// days, 06:00 and 18:00 if not set.
+	Astronomy *wug.Astronomy
+}
+
+// Theme colours of a meteogram
+type Theme struct {
+	Background    color.NRGBA
+	Foreground    color.NRGBA // text and axes
+	Grid          color.NRGBA
+	Night         color.NRGBA // shading of night hours
+	Temperature   color.NRGBA
+	Freezing      color.NRGBA // the 0°C line
+	Dewpoint      color.NRGBA
+	Precipitation color.NRGBA
+	Pop           color.NRGBA // bars of the probability of precipitation
+	Cloud         color.NRGBA
+	Sun           color.NRGBA
+	Wind          color.NRGBA
+	Pressure      color.NRGBA
+}
+
+// LightTheme is the default theme, dark lines on white
+var LightTheme = Theme{
+	Background:    color.NRGBA{255, 255, 255, 255},
+	Foreground:    color.NRGBA{40, 40, 40, 255},
+	Grid:          color.NRGBA{220, 220, 220, 255},
+	Night:         color.NRGBA{30, 40, 90, 24},
+	Temperature:   color.NRGBA{214, 39, 40, 255},
+	Freezing:      color.NRGBA{31, 119, 180, 255},
+	Dewpoint:      color.NRGBA{44, 160, 44, 255},
+	Precipitation: color.NRGBA{31, 119, 180, 255},
+	Pop:           color.NRGBA{31, 119, 180, 48},
+	Cloud:         color.NRGBA{120, 120, 130, 255},
+	Sun:           color.NRGBA{255, 190, 0, 255},
+	Wind:          color.NRGBA{40, 40, 40, 255},
+	Pressure:      color.NRGBA{148, 103, 189, 255},
+}
+
+// DarkTheme is light lines on a dark background
+var DarkTheme = Theme{
+	Background:    color.NRGBA{24, 26, 32, 255},
+	Foreground:    color.NRGBA{220, 220, 220, 255},
+	Grid:          color.NRGBA{60, 64, 72, 255},
+	Night:         color.NRGBA{0, 0, 0, 110},
+	Temperature:   color.NRGBA{255, 99, 71, 255},
+	Freezing:      color.NRGBA{100, 170, 255, 255},
+	Dewpoint:      color.NRGBA{120, 210, 120, 255},
+	Precipitation: color.NRGBA{100, 170, 255, 255},
+	Pop:           color.NRGBA{100, 170, 255, 56},
+	Cloud:         color.NRGBA{170, 170, 180, 255},
+	Sun:           color.NRGBA{255, 200, 40, 255},
+	Wind:          color.NRGBA{220, 220, 220, 255},
+	Pressure:      color.NRGBA{197, 160, 230, 255},
+}
+
+// Options of a drawn meteogram
+type Options struct {
+	Width, Height int            // pixels, DefaultWidth and DefaultHeight if 0
+	Theme         *Theme         // LightTheme if nil
+	System        units.System   // units of the axes
+	Location      *time.Location // zone of the time axis, the zone of the first hour if nil
+}
+
+// defaults returns the options with their defaults filled in.
+func (o Options) defaults(m *Meteogram) Options {
+	if o.Width <= 0 {
+		o.Width = DefaultWidth
+	}
+
+	if o.Height <= 0 {
+		o.Height = DefaultHeight
+	}
+
+	if o.Theme == nil {
+		o.Theme = &LightTheme
+	}
+
+	if o.Location == nil {
+		o.Location = time.UTC
+		if len(m.Hours) > 0 {
+			o.Location = m.Hours[0].Time.Location()
+		}
+	}
+	return o
+}
+
+// New returns the meteogram of hourly forecasts, e.g. Hourly.Hourly. The ten
+// day forecast is optional.
+func New(forecasts []wug.HourlyForecast, tenDay *wug.ForecastTenDay) *Meteogram {
+	m := &Meteogram{Hours: make([]Hour, 0, len(forecasts))}
+	for i := range forecasts {
+		f := &forecasts[i]
+		t, err := f.Fcttime.Time()
+		if err != nil {
+			continue
+		}
+
+		hour := Hour{
+			Time:          t,
+			Temperature:   math.NaN(),
+			Dewpoint:      math.NaN(),
+			Precipitation: math.NaN(),
+			Pop:           flex(f.Pop),
+			CloudCover:    flex(f.Sky),
+			WindSpeed:     math.NaN(),
+			WindDirection: flex(f.Wdir.Degrees),
+			Pressure:      math.NaN(),
+		}
+		hour.Icon, hour.Night = f.IconType()
+
+		if v, ok := f.Temperature(); ok {
+			hour.Temperature = v.Celsius()
+		}
+
+		if v, ok := f.DewpointTemp(); ok {
+			hour.Dewpoint = v.Celsius()
+		}
+
+		if v, ok := f.Precipitation(); ok {
+			hour.Precipitation = v.Millimeters()
+		}
+
+		if v, ok := f.WindSpeed(); ok {
+			hour.WindSpeed = v.MetersPerSecond()
+		}
+
+		if v, ok := f.Pressure(); ok {
+			hour.Pressure = v.Hectopascals()
+		}
+		m.Hours = append(m.Hours, hour)
+	}
+
+	if tenDay != nil {
+		m.Days = tenDay.Days()
+	}
+	return m
+}
+
+func flex(f wug.FlexFloat) float64 {
+	if !f.Valid {
+		return math.NaN()
+	}
+	return f.Value
+}
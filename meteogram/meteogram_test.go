@@ -0,0 +1,190 @@
+package meteogram
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+	_ "time/tzdata" // zones of the generated forecasts
+
+	"github.com/wirepair/wug"
+	"github.com/wirepair/wug/wugtest"
+)
+
+var testNow = time.Date(2026, 1, 12, 12, 0, 0, 0, time.UTC)
+
+func testMeteogram(hours int) *Meteogram {
+	return New(wugtest.GenerateHourly(wugtest.DefaultLocation, testNow, hours), nil)
+}
+
+func TestNew(t *testing.T) {
+	m := testMeteogram(36)
+	if len(m.Hours) != 36 {
+		t.Fatalf("expected 36 hours got %d\n", len(m.Hours))
+	}
+
+	h := m.Hours[0]
+	for name, v := range map[string]float64{"temperature": h.Temperature, "dew point": h.Dewpoint, "pressure": h.Pressure, "wind": h.WindSpeed} {
+		if math.IsNaN(v) {
+			t.Fatalf("expected a %s\n", name)
+		}
+	}
+
+	if h.Icon == wug.IconUnknown {
+		t.Fatalf("expected the icon of the hour")
+	}
+
+	if h.Time.Location().String() != "America/Los_Angeles" {
+		t.Fatalf("expected hours in the zone of the forecast got %s\n", h.Time.Location())
+	}
+
+	missing := New([]wug.HourlyForecast{{Fcttime: wug.FCTTIME{Epoch: "1783000000"}}}, nil)
+	if len(missing.Hours) != 1 || !math.IsNaN(missing.Hours[0].Temperature) || !math.IsNaN(missing.Hours[0].Pop) {
+		t.Fatalf("expected missing values to be NaN got %+v\n", missing.Hours)
+	}
+
+	tenDay := &wug.ForecastTenDay{Forecast: wugtest.GenerateForecast(wugtest.DefaultLocation, testNow, 10)}
+	if m := New(nil, tenDay); len(m.Days) != 10 {
+		t.Fatalf("expected 10 days got %d\n", len(m.Days))
+	}
+}
+
+func TestOptionsDefaults(t *testing.T) {
+	m := testMeteogram(2)
+	opts := Options{}.defaults(m)
+	if opts.Width != DefaultWidth || opts.Height != DefaultHeight || opts.Theme != &LightTheme {
+		t.Fatalf("expected the default size and theme got %+v\n", opts)
+	}
+
+	if opts.Location != m.Hours[0].Time.Location() {
+		t.Fatalf("expected the zone of the first hour got %s\n", opts.Location)
+	}
+}
+
+func TestNiceScale(t *testing.T) {
+	s := niceScale(band{0, 100}, []float64{41.3, 68.9, math.NaN()}, 5, 5)
+	if s.lo != 40 || s.hi != 70 || s.step != 10 {
+		t.Fatalf("expected 40 to 70 by 10 got %v to %v by %v\n", s.lo, s.hi, s.step)
+	}
+
+	if s.y(40) != 100 || s.y(70) != 0 {
+		t.Fatalf("expected the scale to span the band got %v %v\n", s.y(40), s.y(70))
+	}
+
+	// a flat series is widened to the span
+	s = niceScale(band{0, 100}, []float64{1013, 1013}, 8, 4)
+	if s.hi-s.lo < 8 {
+		t.Fatalf("expected at least 8 wide got %v to %v\n", s.lo, s.hi)
+	}
+
+	s = niceScale(band{0, 100}, nil, 0.08, 2)
+	if s.lo != 0 || s.hi != 0.1 {
+		t.Fatalf("expected 0 to 0.1 without values got %v to %v\n", s.lo, s.hi)
+	}
+}
+
+// rect drawn on a recorder
+type rect struct {
+	x, w float64
+	fill color.NRGBA
+}
+
+// recorder is a canvas keeping the texts and rects drawn
+type recorder struct {
+	texts map[string][]point
+	rects []rect
+}
+
+func (r *recorder) rect(x, y, w, h float64, fill color.NRGBA) {
+	r.rects = append(r.rects, rect{x, w, fill})
+}
+
+func (r *recorder) polyline(points []point, stroke color.NRGBA, w float64, d bool) {}
+func (r *recorder) polygon(points []point, fill color.NRGBA)                       {}
+func (r *recorder) circle(c point, radius float64, fill, stroke color.NRGBA)       {}
+func (r *recorder) text(p point, s string, fill color.NRGBA, a anchor) {
+	r.texts[s] = append(r.texts[s], p)
+}
+
+func TestTimeAxisHalfHourZone(t *testing.T) {
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Fatalf("error loading zone: %s\n", err)
+	}
+
+	m := &Meteogram{}
+	for h := 0; h < 24; h++ {
+		m.Hours = append(m.Hours, Hour{Time: time.Date(2026, 1, 12, h, 0, 0, 0, kolkata).UTC(), Night: h < 6})
+	}
+
+	opts := Options{Width: 1200, Location: kolkata}.defaults(m)
+	r := &recorder{texts: make(map[string][]point)}
+	m.draw(r, opts)
+
+	l := &layout{left: marginLeft, right: float64(opts.Width - marginRight), start: m.Hours[0].Time, end: m.Hours[23].Time.Add(time.Hour)}
+	ticks := r.texts["03"]
+	if want := l.x(time.Date(2026, 1, 12, 3, 0, 0, 0, kolkata)); len(ticks) != 1 || math.Abs(ticks[0].X-want) > 1e-9 {
+		t.Fatalf("expected the 03 tick at %v got %v\n", want, ticks)
+	}
+
+	nights := 0
+	for _, rc := range r.rects {
+		if rc.fill == opts.Theme.Night {
+			nights++
+		}
+	}
+
+	if nights != 1 {
+		t.Fatalf("expected the night hours shaded as one rect got %d\n", nights)
+	}
+}
+
+func TestForecastNights(t *testing.T) {
+	m := &Meteogram{}
+	for h := 0; h < 72; h++ {
+		// night icons at noon are ignored with the days of a forecast
+		hour := Hour{Time: time.Date(2026, 1, 12, h, 0, 0, 0, time.UTC)}
+		hour.Night = hour.Time.Hour() == 12
+		m.Hours = append(m.Hours, hour)
+	}
+
+	for day := 12; day < 14; day++ {
+		var d wug.DailyView
+		d.Date.Epoch = strconv.FormatInt(time.Date(2026, 1, day, 19, 0, 0, 0, time.UTC).Unix(), 10)
+		m.Days = append(m.Days, d)
+	}
+	m.Astronomy = &wug.Astronomy{}
+	m.Astronomy.SunPhase.Sunrise = wug.ClockTime{Hour: "7", Minute: "15"}
+	m.Astronomy.SunPhase.Sunset = wug.ClockTime{Hour: "17", Minute: "45"}
+
+	opts := Options{}.defaults(m)
+	r := &recorder{texts: make(map[string][]point)}
+	m.draw(r, opts)
+
+	// midnight to sunrise of the first day, the night between the days and
+	// sunset to midnight of the last day, the third day is not forecast
+	var nights []rect
+	for _, rc := range r.rects {
+		if rc.fill == opts.Theme.Night {
+			nights = append(nights, rc)
+		}
+	}
+
+	l := &layout{left: marginLeft, right: float64(opts.Width - marginRight), start: m.Hours[0].Time, end: m.Hours[71].Time.Add(time.Hour)}
+	want := [][2]time.Time{
+		{time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC), time.Date(2026, 1, 12, 7, 15, 0, 0, time.UTC)},
+		{time.Date(2026, 1, 12, 17, 45, 0, 0, time.UTC), time.Date(2026, 1, 13, 7, 15, 0, 0, time.UTC)},
+		{time.Date(2026, 1, 13, 17, 45, 0, 0, time.UTC), time.Date(2026, 1, 14, 0, 0, 0, 0, time.UTC)},
+	}
+	if len(nights) != len(want) {
+		t.Fatalf("expected %d nights got %+v\n", len(want), nights)
+	}
+
+	for i, w := range want {
+		x, width := l.x(w[0]), l.x(w[1])-l.x(w[0])
+		if math.Abs(nights[i].x-x) > 1e-9 || math.Abs(nights[i].w-width) > 1e-9 {
+			t.Fatalf("expected night %d at %v+%v got %+v\n", i, x, width, nights[i])
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package meteogram
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"sort"
+)
+
+// rasterCanvas draws into an image, without anti-aliasing
+type rasterCanvas struct {
+	img *image.RGBA
+}
+
+// Image draws the meteogram into an image.
+func (m *Meteogram) Image(opts Options) (*image.RGBA, error) {
+	if len(m.Hours) == 0 {
+		return nil, ErrNoHours
+	}
+	opts = opts.defaults(m)
+
+	c := &rasterCanvas{img: image.NewRGBA(image.Rect(0, 0, opts.Width, opts.Height))}
+	m.draw(c, opts)
+	return c.img, nil
+}
+
+// PNG writes the meteogram as a PNG image.
+func (m *Meteogram) PNG(w io.Writer, opts Options) error {
+	img, err := m.Image(opts)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// blend paints the pixel with the colour over what is already drawn.
+func (c *rasterCanvas) blend(x, y int, col color.NRGBA) {
+	if !(image.Point{x, y}.In(c.img.Rect)) || col.A == 0 {
+		return
+	}
+
+	if col.A == 255 {
+		c.img.SetRGBA(x, y, color.RGBA{col.R, col.G, col.B, 255})
+		return
+	}
+
+	dst := c.img.RGBAAt(x, y)
+	a := uint32(col.A)
+	mix := func(src, dst uint8) uint8 {
+		return uint8((uint32(src)*a + uint32(dst)*(255-a)) / 255)
+	}
+	c.img.SetRGBA(x, y, color.RGBA{mix(col.R, dst.R), mix(col.G, dst.G), mix(col.B, dst.B), mix(255, dst.A)})
+}
+
+func (c *rasterCanvas) rect(x, y, w, h float64, fill color.NRGBA) {
+	for py := int(math.Round(y)); py < int(math.Round(y+h)); py++ {
+		for px := int(math.Round(x)); px < int(math.Round(x+w)); px++ {
+			c.blend(px, py, fill)
+		}
+	}
+}
+
+// stamp fills the pixels within r of p, marking them in drawn so translucent
+// strokes are only blended once.
+func (c *rasterCanvas) stamp(p point, r float64, col color.NRGBA, drawn map[image.Point]bool) {
+	r = math.Max(r, 0.71) // covers at least the nearest pixel
+	for py := int(math.Floor(p.Y - r)); py <= int(math.Ceil(p.Y+r)); py++ {
+		for px := int(math.Floor(p.X - r)); px <= int(math.Ceil(p.X+r)); px++ {
+			dx, dy := float64(px)+0.5-p.X, float64(py)+0.5-p.Y
+			if dx*dx+dy*dy > r*r {
+				continue
+			}
+
+			if pt := (image.Point{px, py}); !drawn[pt] {
+				drawn[pt] = true
+				c.blend(px, py, col)
+			}
+		}
+	}
+}
+
+func (c *rasterCanvas) polyline(points []point, stroke color.NRGBA, width float64, dashed bool) {
+	if width <= 1 {
+		// thin lines are centred on pixels so they stay one pixel wide
+		snapped := make([]point, len(points))
+		for i, p := range points {
+			snapped[i] = point{math.Floor(p.X) + 0.5, math.Floor(p.Y) + 0.5}
+		}
+		points = snapped
+	}
+
+	drawn := make(map[image.Point]bool)
+	var travelled float64
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		length := math.Hypot(b.X-a.X, b.Y-a.Y)
+		steps := int(math.Ceil(length*2)) + 1
+		for s := 0; s <= steps; s++ {
+			f := float64(s) / float64(steps)
+			if dashed && math.Mod(travelled+f*length, 7) >= 4 {
+				continue
+			}
+			c.stamp(point{a.X + (b.X-a.X)*f, a.Y + (b.Y-a.Y)*f}, width/2, stroke, drawn)
+		}
+		travelled += length
+	}
+}
+
+// polygon fills the polygon with the even-odd rule at pixel centres.
+func (c *rasterCanvas) polygon(points []point, fill color.NRGBA) {
+	if len(points) < 3 {
+		return
+	}
+
+	top, bottom := math.Inf(1), math.Inf(-1)
+	for _, p := range points {
+		top, bottom = math.Min(top, p.Y), math.Max(bottom, p.Y)
+	}
+
+	for py := int(math.Floor(top)); py <= int(math.Ceil(bottom)); py++ {
+		y := float64(py) + 0.5
+		var xs []float64
+		for i := range points {
+			a, b := points[i], points[(i+1)%len(points)]
+			if (a.Y <= y) != (b.Y <= y) {
+				xs = append(xs, a.X+(y-a.Y)/(b.Y-a.Y)*(b.X-a.X))
+			}
+		}
+		sort.Float64s(xs)
+
+		for i := 0; i+1 < len(xs); i += 2 {
+			for px := int(math.Round(xs[i])); px < int(math.Round(xs[i+1])); px++ {
+				c.blend(px, py, fill)
+			}
+		}
+	}
+}
+
+func (c *rasterCanvas) circle(p point, r float64, fill, stroke color.NRGBA) {
+	for py := int(math.Floor(p.Y - r - 1)); py <= int(math.Ceil(p.Y+r+1)); py++ {
+		for px := int(math.Floor(p.X - r - 1)); px <= int(math.Ceil(p.X+r+1)); px++ {
+			d := math.Hypot(float64(px)+0.5-p.X, float64(py)+0.5-p.Y)
+			switch {
+			case stroke.A > 0 && math.Abs(d-r) <= 0.6:
+				c.blend(px, py, stroke)
+			case d <= r:
+				c.blend(px, py, fill)
+			}
+		}
+	}
+}
+
+func (c *rasterCanvas) text(p point, s string, fill color.NRGBA, a anchor) {
+	x := int(math.Round(p.X))
+	switch a {
+	case anchorMiddle:
+		x -= textWidth(s) / 2
+	case anchorEnd:
+		x -= textWidth(s)
+	}
+
+	top := int(math.Round(p.Y)) - glyphHeight
+	for _, r := range s {
+		g := glyph(r)
+		for row, bits := range g {
+			for col := 0; col < glyphWidth; col++ {
+				if bits&(1<<(glyphWidth-1-col)) != 0 {
+					c.blend(x+col, top+row, fill)
+				}
+			}
+		}
+		x += glyphAdvance
+	}
+}
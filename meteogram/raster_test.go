@@ -0,0 +1,85 @@
+package meteogram
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestPNG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testMeteogram(240).PNG(&buf, Options{Width: 640, Height: 360, Theme: &DarkTheme}); err != nil {
+		t.Fatalf("error writing png: %s\n", err)
+	}
+
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("error decoding png: %s\n", err)
+	}
+
+	if b := img.Bounds(); b.Dx() != 640 || b.Dy() != 360 {
+		t.Fatalf("expected 640x360 got %v\n", b)
+	}
+
+	bg := DarkTheme.Background
+	if got := color.NRGBAModel.Convert(img.At(1, 1)).(color.NRGBA); got != bg {
+		t.Fatalf("expected the background %v got %v\n", bg, got)
+	}
+
+	// the temperature line is drawn somewhere
+	found := false
+	for y := 0; y < 360 && !found; y++ {
+		for x := 0; x < 640; x++ {
+			if color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA) == DarkTheme.Temperature {
+				found = true
+				break
+			}
+		}
+	}
+
+	if !found {
+		t.Fatalf("expected pixels of the temperature line")
+	}
+}
+
+func TestRasterText(t *testing.T) {
+	m := testMeteogram(1)
+	img, err := m.Image(Options{Width: 20, Height: 10})
+	if err != nil {
+		t.Fatalf("error drawing: %s\n", err)
+	}
+
+	c := &rasterCanvas{img: img}
+	c.rect(0, 0, 20, 10, LightTheme.Background)
+	c.text(point{0, 7}, "1", LightTheme.Foreground, anchorStart)
+
+	// the stem of the 1 is the third column of the glyph
+	for y := 1; y < 6; y++ {
+		if got := color.NRGBAModel.Convert(img.At(2, y)).(color.NRGBA); got != LightTheme.Foreground {
+			t.Fatalf("expected the stem of the 1 at row %d got %v\n", y, got)
+		}
+	}
+
+	if textWidth("12") != 11 || textWidth("") != 0 {
+		t.Fatalf("expected widths of 11 and 0 got %d %d\n", textWidth("12"), textWidth(""))
+	}
+
+	if glyph('a') != glyph('A') || glyph('€') != glyph('?') {
+		t.Fatalf("expected upper case and unknown glyphs")
+	}
+}
+
+func TestBlend(t *testing.T) {
+	m := testMeteogram(1)
+	img, _ := m.Image(Options{Width: 2, Height: 2})
+	c := &rasterCanvas{img: img}
+
+	c.rect(0, 0, 2, 2, color.NRGBA{255, 255, 255, 255})
+	c.blend(0, 0, color.NRGBA{0, 0, 0, 51})
+	if got := img.RGBAAt(0, 0); got.R != 204 || got.A != 255 {
+		t.Fatalf("expected 20%% black over white got %v\n", got)
+	}
+
+	c.blend(5, 5, color.NRGBA{0, 0, 0, 255}) // outside is ignored
+}
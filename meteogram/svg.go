@@ -0,0 +1,87 @@
+package meteogram
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// svgCanvas writes the drawing as SVG elements
+type svgCanvas struct {
+	w *bufio.Writer
+}
+
+// SVG writes the meteogram as an SVG document.
+func (m *Meteogram) SVG(w io.Writer, opts Options) error {
+	if len(m.Hours) == 0 {
+		return ErrNoHours
+	}
+	opts = opts.defaults(m)
+
+	c := &svgCanvas{w: bufio.NewWriter(w)}
+	fmt.Fprintf(c.w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="11">`+"\n",
+		opts.Width, opts.Height, opts.Width, opts.Height)
+	m.draw(c, opts)
+	c.w.WriteString("</svg>\n")
+	return c.w.Flush()
+}
+
+// paint returns the attribute of a colour, with its opacity if translucent.
+func paint(attr string, c color.NRGBA) string {
+	if c.A == 0 {
+		return fmt.Sprintf(`%s="none"`, attr)
+	}
+
+	s := fmt.Sprintf(`%s="#%02x%02x%02x"`, attr, c.R, c.G, c.B)
+	if c.A < 255 {
+		s += fmt.Sprintf(` %s-opacity="%.3g"`, attr, float64(c.A)/255)
+	}
+	return s
+}
+
+func formatPoints(points []point) string {
+	var b strings.Builder
+	for i, p := range points {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "%.1f,%.1f", p.X, p.Y)
+	}
+	return b.String()
+}
+
+func (c *svgCanvas) rect(x, y, w, h float64, fill color.NRGBA) {
+	fmt.Fprintf(c.w, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" %s/>`+"\n", x, y, w, h, paint("fill", fill))
+}
+
+func (c *svgCanvas) polyline(points []point, stroke color.NRGBA, width float64, dashed bool) {
+	dash := ""
+	if dashed {
+		dash = ` stroke-dasharray="4 3"`
+	}
+	fmt.Fprintf(c.w, `<polyline points="%s" fill="none" %s stroke-width="%.3g" stroke-linejoin="round"%s/>`+"\n",
+		formatPoints(points), paint("stroke", stroke), width, dash)
+}
+
+func (c *svgCanvas) polygon(points []point, fill color.NRGBA) {
+	fmt.Fprintf(c.w, `<polygon points="%s" %s/>`+"\n", formatPoints(points), paint("fill", fill))
+}
+
+func (c *svgCanvas) circle(p point, r float64, fill, stroke color.NRGBA) {
+	fmt.Fprintf(c.w, `<circle cx="%.1f" cy="%.1f" r="%.1f" %s %s/>`+"\n", p.X, p.Y, r, paint("fill", fill), paint("stroke", stroke))
+}
+
+var anchorMap = map[anchor]string{
+	anchorStart:  "start",
+	anchorMiddle: "middle",
+	anchorEnd:    "end",
+}
+
+func (c *svgCanvas) text(p point, s string, fill color.NRGBA, a anchor) {
+	fmt.Fprintf(c.w, `<text x="%.1f" y="%.1f" text-anchor="%s" %s>`, p.X, p.Y, anchorMap[a], paint("fill", fill))
+	xml.EscapeText(c.w, []byte(s))
+	c.w.WriteString("</text>\n")
+}
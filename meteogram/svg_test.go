@@ -0,0 +1,78 @@
+package meteogram
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/wirepair/wug/units"
+)
+
+func TestSVG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testMeteogram(48).SVG(&buf, Options{Width: 800, Height: 400, System: units.Metric}); err != nil {
+		t.Fatalf("error writing svg: %s\n", err)
+	}
+
+	counts := make(map[string]int)
+	var texts []string
+	dec := xml.NewDecoder(&buf)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("error parsing svg: %s\n", err)
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			counts[tok.Name.Local]++
+			if tok.Name.Local == "svg" && attr(tok, "width") != "800" {
+				t.Fatalf("expected a width of 800 got %s\n", attr(tok, "width"))
+			}
+		case xml.CharData:
+			if s := strings.TrimSpace(string(tok)); s != "" {
+				texts = append(texts, s)
+			}
+		}
+	}
+
+	if counts["svg"] != 1 || counts["polyline"] == 0 || counts["rect"] == 0 || counts["text"] == 0 {
+		t.Fatalf("expected a drawing got %v\n", counts)
+	}
+
+	all := strings.Join(texts, "|")
+	for _, want := range []string{"Temperature / dew point °C", "Precipitation mm", "Pressure hPa", "Wind kt", "Tuesday 13"} {
+		if !strings.Contains(all, want) {
+			t.Fatalf("expected %q in the labels %s\n", want, all)
+		}
+	}
+
+	if err := (&Meteogram{}).SVG(&buf, Options{}); err != ErrNoHours {
+		t.Fatalf("expected ErrNoHours got %v\n", err)
+	}
+}
+
+func attr(el xml.StartElement, name string) string {
+	for _, a := range el.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func TestPaint(t *testing.T) {
+	if got := paint("fill", LightTheme.Night); got != `fill="#1e285a" fill-opacity="0.0941"` {
+		t.Fatalf("expected a translucent fill got %s\n", got)
+	}
+
+	if got := paint("stroke", transparent); got != `stroke="none"` {
+		t.Fatalf("expected no stroke got %s\n", got)
+	}
+}
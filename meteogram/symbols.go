@@ -0,0 +1,130 @@
+package meteogram
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/wirepair/wug"
+)
+
+// transparent paints nothing
+var transparent = color.NRGBA{}
+
+// drawSymbol draws the condition of an icon centred on p: a sun or moon,
+// clouds, precipitation marks, lightning or fog.
+func (l *layout) drawSymbol(p point, icon wug.Icon, night bool) {
+	t := l.theme
+	switch {
+	case icon == wug.IconUnknown:
+		return
+	case isSunny(icon):
+		l.drawSun(p, 6, night)
+		return
+	case isFog(icon):
+		for i := -1; i <= 1; i++ {
+			y := p.Y + float64(i)*4
+			l.c.polyline([]point{{p.X - 8, y}, {p.X + 8, y}}, t.Cloud, 1.5, false)
+		}
+		return
+	case isPartly(icon):
+		l.drawSun(point{p.X - 4, p.Y - 4}, 5, night)
+	}
+
+	cloud := point{p.X + 1, p.Y}
+	if icon.Precipitation() != wug.PrecipNone || icon.Thunder() != wug.ThunderNone {
+		cloud.Y -= 3
+	}
+	l.drawCloud(cloud)
+
+	below := cloud.Y + 6
+	switch icon.Precipitation() {
+	case wug.PrecipRain:
+		for i := -1; i <= 1; i++ {
+			x := p.X + float64(i)*4
+			l.c.polyline([]point{{x + 1, below}, {x - 1, below + 4}}, t.Precipitation, 1.2, false)
+		}
+	case wug.PrecipSnow:
+		for i := -1; i <= 1; i++ {
+			l.c.circle(point{p.X + float64(i)*4, below + 2}, 1.2, t.Precipitation, transparent)
+		}
+	case wug.PrecipSleet, wug.PrecipMixed:
+		l.c.polyline([]point{{p.X - 2, below}, {p.X - 4, below + 4}}, t.Precipitation, 1.2, false)
+		l.c.circle(point{p.X + 3, below + 2}, 1.2, t.Precipitation, transparent)
+	}
+
+	if icon.Thunder() != wug.ThunderNone {
+		l.c.polygon([]point{
+			{p.X + 1, below - 1}, {p.X - 3, below + 4}, {p.X, below + 4},
+			{p.X - 2, below + 8}, {p.X + 4, below + 2}, {p.X + 1, below + 2},
+		}, t.Sun)
+	}
+}
+
+// drawSun draws a sun with rays, or a crescent moon at night.
+func (l *layout) drawSun(p point, r float64, night bool) {
+	if night {
+		l.c.circle(p, r, l.theme.Sun, transparent)
+		l.c.circle(point{p.X + r*0.5, p.Y - r*0.3}, r*0.85, l.theme.Background, transparent)
+		return
+	}
+
+	l.c.circle(p, r*0.6, l.theme.Sun, transparent)
+	for i := 0; i < 8; i++ {
+		a := float64(i) * math.Pi / 4
+		sin, cos := math.Sincos(a)
+		l.c.polyline([]point{{p.X + cos*r*0.85, p.Y + sin*r*0.85}, {p.X + cos*r*1.3, p.Y + sin*r*1.3}}, l.theme.Sun, 1, false)
+	}
+}
+
+// drawCloud draws a cloud of three puffs on a flat base.
+func (l *layout) drawCloud(p point) {
+	c := l.theme.Cloud
+	l.c.circle(point{p.X - 4, p.Y + 1}, 3.5, c, transparent)
+	l.c.circle(point{p.X + 1, p.Y - 2}, 4.5, c, transparent)
+	l.c.circle(point{p.X + 5, p.Y + 1}, 3.5, c, transparent)
+	l.c.rect(p.X-4, p.Y+1, 9, 3.5, c)
+}
+
+// drawBarb draws a wind barb centred on p, its shaft pointing where the wind
+// blows from with a pennant for each 50 knots, a full barb for each 10 and a
+// half barb for 5, on the clockwise side. Calm winds are a circle.
+func (l *layout) drawBarb(p point, knots, direction float64) {
+	stroke := l.theme.Wind
+	knots = math.Round(knots/5) * 5
+	if knots < 5 || math.IsNaN(direction) {
+		l.c.circle(p, 3, transparent, stroke)
+		return
+	}
+
+	const length = 22
+	sin, cos := math.Sincos(direction * math.Pi / 180)
+	u := point{sin, -cos}    // towards where the wind blows from
+	side := point{-u.Y, u.X} // clockwise of the shaft
+	at := func(along, across float64) point {
+		return point{p.X + u.X*along + side.X*across, p.Y + u.Y*along + side.Y*across}
+	}
+
+	l.c.polyline([]point{at(-length/2, 0), at(length/2, 0)}, stroke, 1.2, false)
+
+	pos := float64(length / 2)
+	for ; knots >= 50; knots -= 50 {
+		l.c.polygon([]point{at(pos, 0), at(pos-2, 9), at(pos-5, 0)}, stroke)
+		pos -= 6
+	}
+
+	if pos < length/2 {
+		pos -= 1
+	}
+
+	for ; knots >= 10; knots -= 10 {
+		l.c.polyline([]point{at(pos, 0), at(pos+3, 9)}, stroke, 1.2, false)
+		pos -= 3.5
+	}
+
+	if knots >= 5 {
+		if pos == length/2 {
+			pos -= 3.5 // a lone half barb is set in from the tip
+		}
+		l.c.polyline([]point{at(pos, 0), at(pos+1.5, 4.5)}, stroke, 1.2, false)
+	}
+}
@@ -0,0 +1,40 @@
+package wug
+
+import (
+	"net/http"
+)
+
+// Request is an upstream weather underground request as seen by Middleware.
+type Request struct {
+	Type     RequestType   // feature being requested
+	Query    *Query        // query of the request
+	Settings Settings      // effective settings (client merged with query)
+	HTTP     *http.Request // the http request, carrying the request context
+}
+
+// Handler performs a Request and returns the http response.
+type Handler func(req *Request) (*http.Response, error)
+
+// Middleware wraps a Handler to add cross cutting behavior such as logging,
+// metrics, retries or caching. A middleware may modify the request, replace
+// the response or not call next at all.
+type Middleware func(next Handler) Handler
+
+// handler builds the middleware chain around the http client. The first
+// middleware is the outermost. The Limiter is waited on by the innermost
+// handler, so only requests that reach the network count against the quota.
+func (w *Wug) handler() Handler {
+	h := func(req *Request) (*http.Response, error) {
+		if w.Limiter != nil {
+			if err := w.Limiter.Wait(req.HTTP.Context()); err != nil {
+				return nil, err
+			}
+		}
+		return w.Client.Do(req.HTTP)
+	}
+
+	for i := len(w.Middleware) - 1; i >= 0; i-- {
+		h = w.Middleware[i](h)
+	}
+	return h
+}
@@ -0,0 +1,85 @@
+package wug
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	w := NewWug()
+	w.Settings = Settings{Language: LangFrench}
+	w.Client = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(r.Header.Get("X-Trace"))),
+			Request:    r,
+		}, nil
+	})}
+
+	var order []string
+	q := NewQueryByAirportCode("apikey", "NRT").WithSettings(Settings{PWS: ToggleOff})
+	w.Middleware = []Middleware{
+		func(next Handler) Handler {
+			return func(req *Request) (*http.Response, error) {
+				order = append(order, "outer")
+				if req.Type != Fore || req.Query != q || req.Settings.Language != LangFrench || req.Settings.PWS != ToggleOff {
+					t.Errorf("unexpected request metadata %#v\n", req)
+				}
+				req.HTTP.Header.Set("X-Trace", "trace-id")
+				return next(req)
+			}
+		},
+		func(next Handler) Handler {
+			return func(req *Request) (*http.Response, error) {
+				order = append(order, "inner")
+				resp, err := next(req)
+				order = append(order, "response")
+				return resp, err
+			}
+		},
+	}
+
+	data, err := w.GetRawForecast(q)
+	if err != nil {
+		t.Fatalf("error getting forecast: %s\n", err)
+	}
+
+	if string(data) != "trace-id" {
+		t.Fatalf("expected header set by middleware got %s\n", data)
+	}
+
+	if strings.Join(order, ",") != "outer,inner,response" {
+		t.Fatalf("unexpected middleware order %v\n", order)
+	}
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	w := NewWug()
+	w.Client = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		t.Fatalf("request should not reach the transport")
+		return nil, nil
+	})}
+
+	w.Middleware = []Middleware{
+		func(next Handler) Handler {
+			return func(req *Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(strings.NewReader(`{"current_observation": {"temp_f": 72.5}}`)),
+					Request:    req.HTTP,
+				}, nil
+			}
+		},
+	}
+
+	conditions, err := w.GetConditions(NewQueryByAutoIP("apikey"))
+	if err != nil {
+		t.Fatalf("error getting conditions: %s\n", err)
+	}
+
+	if conditions.CurrentObservation.TempF.Value != 72.5 {
+		t.Fatalf("expected cached conditions got %#v\n", conditions)
+	}
+}
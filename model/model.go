@@ -0,0 +1,89 @@
+// Package model is a provider neutral weather model. It does not depend on
+// the weather underground wire format, the wug package converts its responses
+// into these types. Measurements that were not reported are nil.
+package model
+
+import (
+	"time"
+
+	"github.com/wirepair/wug/units"
+)
+
+// Location of an observation or forecast
+type Location struct {
+	Name      string         // display name, e.g. San Francisco, CA
+	City      string         // city name
+	Region    string         // state or region
+	Country   string         // ISO 3166 country code
+	StationID string         // id of the observing station, if any
+	Latitude  *float64       // degrees north
+	Longitude *float64       // degrees east
+	Elevation *units.Length  // above sea level
+	TimeZone  *time.Location // local time zone
+}
+
+// Observation is a current weather observation
+type Observation struct {
+	Location          Location
+	Time              time.Time // when the observation was made, in the local zone
+	Condition         string    // textual description, e.g. Partly Cloudy
+	Icon              string    // icon name
+	Temperature       *units.Temperature
+	Dewpoint          *units.Temperature
+	FeelsLike         *units.Temperature
+	HeatIndex         *units.Temperature
+	Windchill         *units.Temperature
+	Humidity          *float64 // relative humidity percent
+	WindSpeed         *units.Speed
+	WindGust          *units.Speed
+	WindDirection     *float64 // degrees the wind blows from
+	WindDirectionName string   // compass name, e.g. NNW
+	Pressure          *units.Pressure
+	PressureTrend     string // +, - or 0
+	Visibility        *units.Length
+	UVIndex           *float64
+	SolarRadiation    *float64 // W/m²
+	Precip1Hr         *units.Precipitation
+	PrecipToday       *units.Precipitation
+}
+
+// DailyForecast is the forecast of a single day
+type DailyForecast struct {
+	Date              time.Time // midnight of the day, in the local zone
+	Condition         string    // textual description, e.g. Chance of Rain
+	Icon              string    // icon name
+	High              *units.Temperature
+	Low               *units.Temperature
+	PrecipProbability *float64 // percent
+	Precip            *units.Precipitation
+	Snow              *units.Precipitation
+	MaxWind           *units.Speed
+	MaxWindDirection  *float64 // degrees
+	AveWind           *units.Speed
+	AveWindDirection  *float64 // degrees
+	Humidity          *float64 // average relative humidity percent
+	MinHumidity       *float64
+	MaxHumidity       *float64
+}
+
+// HourlyPoint is the forecast of a single hour
+type HourlyPoint struct {
+	Time              time.Time // start of the hour, in the local zone
+	Condition         string    // textual description, e.g. Clear
+	Icon              string    // icon name
+	Temperature       *units.Temperature
+	Dewpoint          *units.Temperature
+	FeelsLike         *units.Temperature
+	HeatIndex         *units.Temperature
+	Windchill         *units.Temperature
+	Humidity          *float64 // relative humidity percent
+	WindSpeed         *units.Speed
+	WindDirection     *float64 // degrees the wind blows from
+	WindDirectionName string   // compass name, e.g. NNW
+	Pressure          *units.Pressure
+	CloudCover        *float64 // sky cover percent
+	UVIndex           *float64
+	PrecipProbability *float64 // percent
+	Precip            *units.Precipitation
+	Snow              *units.Precipitation
+}
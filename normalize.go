@@ -0,0 +1,153 @@
+package wug
+
+import (
+	"time"
+
+	"github.com/wirepair/wug/model"
+)
+
+// optional returns a pointer to q, or nil when it is not ok.
+func optional[Q any](q Q, ok bool) *Q {
+	if !ok {
+		return nil
+	}
+	return &q
+}
+
+// optionalFloat returns a pointer to the value of f, or nil when it is not valid.
+func optionalFloat(f FlexFloat) *float64 {
+	return optional(f.Value, f.Valid)
+}
+
+// Observation converts the conditions into the normalized model.
+func (c *Conditions) Observation() model.Observation {
+	obs := &c.CurrentObservation
+	location := model.Location{
+		Name:      obs.DisplayLocation.Full,
+		City:      obs.DisplayLocation.City,
+		Region:    obs.DisplayLocation.State,
+		Country:   obs.DisplayLocation.CountryIso3166,
+		StationID: obs.StationID,
+		Latitude:  optionalFloat(obs.DisplayLocation.Latitude),
+		Longitude: optionalFloat(obs.DisplayLocation.Longitude),
+		Elevation: optional(c.Elevation()),
+	}
+
+	if loc, err := c.Location(); err == nil {
+		location.TimeZone = loc
+	}
+
+	observed, _ := c.ObservedAt()
+	return model.Observation{
+		Location:          location,
+		Time:              observed,
+		Condition:         obs.Weather,
+		Icon:              obs.Icon,
+		Temperature:       optional(c.Temperature()),
+		Dewpoint:          optional(c.DewpointTemp()),
+		FeelsLike:         optional(c.FeelsLikeTemp()),
+		HeatIndex:         optional(c.HeatIndexTemp()),
+		Windchill:         optional(c.WindchillTemp()),
+		Humidity:          optionalFloat(obs.RelativeHumidity),
+		WindSpeed:         optional(c.WindSpeed()),
+		WindGust:          optional(c.WindGustSpeed()),
+		WindDirection:     optionalFloat(obs.WindDegrees),
+		WindDirectionName: obs.WindDir,
+		Pressure:          optional(c.Pressure()),
+		PressureTrend:     obs.PressureTrend,
+		Visibility:        optional(c.Visibility()),
+		UVIndex:           optionalFloat(obs.UV),
+		SolarRadiation:    optionalFloat(obs.Solarradiation),
+		Precip1Hr:         optional(c.Precip1Hr()),
+		PrecipToday:       optional(c.PrecipToday()),
+	}
+}
+
+// DailyForecast converts the forecast day into the normalized model.
+func (f *ForecastDay) DailyForecast() model.DailyForecast {
+	var date time.Time
+	if t, err := f.Time(); err == nil {
+		date = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	}
+
+	return model.DailyForecast{
+		Date:              date,
+		Condition:         f.Conditions,
+		Icon:              f.Icon,
+		High:              optional(f.HighTemp()),
+		Low:               optional(f.LowTemp()),
+		PrecipProbability: optionalFloat(f.Pop),
+		Precip:            optional(f.PrecipAllDay()),
+		Snow:              optional(f.SnowAllDay()),
+		MaxWind:           optional(f.MaxWindSpeed()),
+		MaxWindDirection:  optionalFloat(f.Maxwind.Degrees),
+		AveWind:           optional(f.AveWindSpeed()),
+		AveWindDirection:  optionalFloat(f.Avewind.Degrees),
+		Humidity:          optionalFloat(f.Avehumidity),
+		MinHumidity:       optionalFloat(f.Minhumidity),
+		MaxHumidity:       optionalFloat(f.Maxhumidity),
+	}
+}
+
+// DailyForecasts converts the simple forecast days into the normalized model.
+func (f *ForecastData) DailyForecasts() []model.DailyForecast {
+	days := make([]model.DailyForecast, len(f.Simpleforecast.Forecastday))
+	for i := range f.Simpleforecast.Forecastday {
+		days[i] = f.Simpleforecast.Forecastday[i].DailyForecast()
+	}
+	return days
+}
+
+// DailyForecasts converts the forecast into the normalized model.
+func (f *Forecast) DailyForecasts() []model.DailyForecast {
+	return f.Forecast.DailyForecasts()
+}
+
+// DailyForecasts converts the ten day forecast into the normalized model.
+func (f *ForecastTenDay) DailyForecasts() []model.DailyForecast {
+	return f.Forecast.DailyForecasts()
+}
+
+// HourlyPoint converts the hourly forecast into the normalized model.
+func (h *HourlyForecast) HourlyPoint() model.HourlyPoint {
+	t, _ := h.Fcttime.Time()
+	return model.HourlyPoint{
+		Time:              t,
+		Condition:         h.Condition,
+		Icon:              h.Icon,
+		Temperature:       optional(h.Temperature()),
+		Dewpoint:          optional(h.DewpointTemp()),
+		FeelsLike:         optional(h.FeelsLikeTemp()),
+		HeatIndex:         optional(h.HeatIndexTemp()),
+		Windchill:         optional(h.WindchillTemp()),
+		Humidity:          optionalFloat(h.Humidity),
+		WindSpeed:         optional(h.WindSpeed()),
+		WindDirection:     optionalFloat(h.Wdir.Degrees),
+		WindDirectionName: h.Wdir.Dir,
+		Pressure:          optional(h.Pressure()),
+		CloudCover:        optionalFloat(h.Sky),
+		UVIndex:           optionalFloat(h.Uvi),
+		PrecipProbability: optionalFloat(h.Pop),
+		Precip:            optional(h.Precipitation()),
+		Snow:              optional(h.Snowfall()),
+	}
+}
+
+// hourlyPoints converts hourly forecasts into the normalized model.
+func hourlyPoints(forecasts []HourlyForecast) []model.HourlyPoint {
+	points := make([]model.HourlyPoint, len(forecasts))
+	for i := range forecasts {
+		points[i] = forecasts[i].HourlyPoint()
+	}
+	return points
+}
+
+// HourlyPoints converts the hourly forecast into the normalized model.
+func (h *Hourly) HourlyPoints() []model.HourlyPoint {
+	return hourlyPoints(h.Hourly)
+}
+
+// HourlyPoints converts the ten day hourly forecast into the normalized model.
+func (h *HourlyTenDay) HourlyPoints() []model.HourlyPoint {
+	return hourlyPoints(h.Hourly)
+}
@@ -0,0 +1,241 @@
+package wug
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	_ "time/tzdata"
+
+	"github.com/wirepair/wug/units"
+)
+
+// measured is an expected measurement in base units, nil when missing
+type measured *float64
+
+func measure(f float64) measured { return &f }
+
+// expect compares an optional measurement in base units.
+func expect[M any](t *testing.T, name, field string, got *M, want measured, base func(M) float64) {
+	t.Helper()
+	switch {
+	case got == nil && want == nil:
+	case got == nil || want == nil:
+		t.Fatalf("%s: expected %s %v got %v\n", name, field, want, got)
+	case math.Abs(base(*got)-*want) > 1e-3:
+		t.Fatalf("%s: expected %s %v got %v\n", name, field, *want, base(*got))
+	}
+}
+
+func decode(t *testing.T, data string, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal([]byte(data), v); err != nil {
+		t.Fatalf("error decoding %s: %s\n", data, err)
+	}
+}
+
+func TestObservation(t *testing.T) {
+	data := []byte(`{"current_observation": {
+		"display_location": {"full": "San Francisco, CA", "city": "San Francisco", "state": "CA", "country_iso3166": "US", "latitude": "37.77", "longitude": "-122.42", "elevation": "47.0"},
+		"station_id": "KCASANFR70", "observation_epoch": "1493150400", "local_tz_long": "America/Los_Angeles",
+		"weather": "Clear", "icon": "clear", "temp_c": 20.1, "relative_humidity": "65%", "wind_degrees": 290, "wind_dir": "WNW",
+		"wind_kph": 16.1, "wind_gust_kph": "NA", "pressure_mb": "1015", "UV": "3", "feelslike_c": "--"}}`)
+	c := &Conditions{}
+	if err := json.Unmarshal(data, c); err != nil {
+		t.Fatalf("error decoding conditions: %s\n", err)
+	}
+
+	obs := c.Observation()
+	if obs.Location.Name != "San Francisco, CA" || obs.Location.StationID != "KCASANFR70" || *obs.Location.Latitude != 37.77 || obs.Location.Elevation.Meters() != 47 {
+		t.Fatalf("unexpected location %#v\n", obs.Location)
+	}
+
+	if obs.Location.TimeZone.String() != "America/Los_Angeles" || obs.Time.Location().String() != "America/Los_Angeles" || obs.Time.Unix() != 1493150400 {
+		t.Fatalf("unexpected time %s\n", obs.Time)
+	}
+
+	if obs.Temperature.Celsius() != 20.1 || *obs.Humidity != 65 || *obs.WindDirection != 290 || obs.WindDirectionName != "WNW" || *obs.UVIndex != 3 {
+		t.Fatalf("unexpected observation %#v\n", obs)
+	}
+
+	if obs.WindGust != nil || obs.FeelsLike != nil || obs.Visibility != nil {
+		t.Fatalf("expected missing measurements to be nil %#v\n", obs)
+	}
+
+	// units and missing values of the measurements
+	for _, tc := range []struct {
+		name                       string
+		data                       string
+		temperature, feelsLike     measured // °C
+		wind, gust                 measured // km/h
+		pressure                   measured // hPa
+		visibility                 measured // km
+		precipToday, humidity, uvi measured // mm, %, index
+	}{
+		{
+			name:        "metric",
+			data:        `{"temp_c": 20.5, "feelslike_c": "21", "wind_kph": 36, "wind_gust_kph": "54", "pressure_mb": "1013", "visibility_km": "10.0", "precip_today_metric": "5", "relative_humidity": "65%", "UV": "3"}`,
+			temperature: measure(20.5), feelsLike: measure(21), wind: measure(36), gust: measure(54), pressure: measure(1013), visibility: measure(10), precipToday: measure(5), humidity: measure(65), uvi: measure(3),
+		},
+		{
+			name:        "english",
+			data:        `{"temp_f": 68, "feelslike_f": "50", "wind_mph": 10, "wind_gust_mph": "20", "pressure_in": "29.92", "visibility_mi": "10", "precip_today_in": "0.5"}`,
+			temperature: measure(20), feelsLike: measure(10), wind: measure(16.09344), gust: measure(32.18688), pressure: measure(1013.2075), visibility: measure(16.09344), precipToday: measure(12.7),
+		},
+		{
+			name:        "metric preferred",
+			data:        `{"temp_c": 20, "temp_f": 100, "wind_kph": 10, "wind_mph": 100, "pressure_mb": 1000, "pressure_in": 40}`,
+			temperature: measure(20), wind: measure(10), pressure: measure(1000),
+		},
+		{
+			name:        "english fallback",
+			data:        `{"temp_c": "NA", "temp_f": 50, "wind_kph": "--", "wind_mph": 0, "pressure_mb": "", "pressure_in": "30"}`,
+			temperature: measure(10), wind: measure(0), pressure: measure(1015.9166),
+		},
+		{
+			name: "missing",
+			data: `{"temp_c": "NA", "temp_f": "NA", "feelslike_c": "--", "wind_kph": "-9999", "wind_gust_kph": null, "pressure_mb": "-999", "visibility_km": "N/A", "precip_today_metric": "--", "relative_humidity": "N/A", "UV": "--"}`,
+		},
+	} {
+		c := &Conditions{}
+		decode(t, `{"current_observation": `+tc.data+`}`, c)
+		obs := c.Observation()
+
+		expect(t, tc.name, "temperature", obs.Temperature, tc.temperature, units.Temperature.Celsius)
+		expect(t, tc.name, "feels like", obs.FeelsLike, tc.feelsLike, units.Temperature.Celsius)
+		expect(t, tc.name, "wind", obs.WindSpeed, tc.wind, units.Speed.KilometersPerHour)
+		expect(t, tc.name, "gust", obs.WindGust, tc.gust, units.Speed.KilometersPerHour)
+		expect(t, tc.name, "pressure", obs.Pressure, tc.pressure, units.Pressure.Hectopascals)
+		expect(t, tc.name, "visibility", obs.Visibility, tc.visibility, units.Length.Kilometers)
+		expect(t, tc.name, "precip today", obs.PrecipToday, tc.precipToday, units.Precipitation.Millimeters)
+		expect(t, tc.name, "humidity", obs.Humidity, tc.humidity, identity)
+		expect(t, tc.name, "uv index", obs.UVIndex, tc.uvi, identity)
+
+		if obs.Dewpoint != nil || obs.Precip1Hr != nil || obs.SolarRadiation != nil || !obs.Time.IsZero() || obs.Location.TimeZone != nil {
+			t.Fatalf("%s: expected unreported values to be missing %#v\n", tc.name, obs)
+		}
+	}
+}
+
+func TestDailyForecastsAndHourlyPoints(t *testing.T) {
+	forecast := &ForecastTenDay{}
+	data := []byte(`{"forecast": {"simpleforecast": {"forecastday": [
+		{"date": {"epoch": "1493172000", "year": 2017, "month": 4, "day": 25, "hour": 19, "min": "00", "tz_long": "America/Los_Angeles"}, "conditions": "Rain", "high": {"celsius": "18"}, "low": {"fahrenheit": "50"}, "pop": 80, "qpf_allday": {"mm": 12}},
+		{"date": {"epoch": "1493258400", "tz_long": "America/Los_Angeles"}, "high": {"celsius": ""}}
+	]}}}`)
+	if err := json.Unmarshal(data, forecast); err != nil {
+		t.Fatalf("error decoding forecast: %s\n", err)
+	}
+
+	days := forecast.DailyForecasts()
+	if len(days) != 2 {
+		t.Fatalf("expected 2 days got %d\n", len(days))
+	}
+
+	if days[0].Date.Format("2006-01-02 15:04 MST") != "2017-04-25 00:00 PDT" || days[0].High.Celsius() != 18 || days[0].Low.Fahrenheit() != 50 || *days[0].PrecipProbability != 80 || days[0].Precip.Millimeters() != 12 {
+		t.Fatalf("unexpected day %#v\n", days[0])
+	}
+
+	if days[1].High != nil || days[1].Date.Day() != 26 {
+		t.Fatalf("unexpected day %#v\n", days[1])
+	}
+
+	hourly := &Hourly{}
+	data = []byte(`{"hourly_forecast": [{"FCTTIME": {"epoch": "1493146800", "year": "2017", "mon": "4", "mday": "25", "hour": "12", "min": "00"}, "temp": {"english": "66", "metric": "19"}, "sky": "40", "pop": "10", "wdir": {"dir": "W", "degrees": "270"}}]}`)
+	if err := json.Unmarshal(data, hourly); err != nil {
+		t.Fatalf("error decoding hourly: %s\n", err)
+	}
+
+	points := hourly.HourlyPoints()
+	if len(points) != 1 || points[0].Time.Hour() != 12 || points[0].Temperature.Celsius() != 19 || *points[0].CloudCover != 40 || *points[0].WindDirection != 270 || points[0].Humidity != nil {
+		t.Fatalf("unexpected points %#v\n", points)
+	}
+}
+
+func identity(v float64) float64 { return v }
+
+func TestDailyForecast(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		data             string
+		high, low        measured // °C
+		precip, snow     measured // mm
+		maxWind, aveWind measured // km/h
+		pop              measured // %
+	}{
+		{
+			name: "metric",
+			data: `{"high": {"celsius": "18"}, "low": {"celsius": "5"}, "qpf_allday": {"mm": 12}, "snow_allday": {"cm": 2}, "maxwind": {"kph": 30}, "avewind": {"kph": 15}, "pop": 80}`,
+			high: measure(18), low: measure(5), precip: measure(12), snow: measure(20), maxWind: measure(30), aveWind: measure(15), pop: measure(80),
+		},
+		{
+			name: "english",
+			data: `{"high": {"fahrenheit": "50"}, "low": {"fahrenheit": "32"}, "qpf_allday": {"in": 1}, "snow_allday": {"in": 0.5}, "maxwind": {"mph": 10}, "avewind": {"mph": 5}}`,
+			high: measure(10), low: measure(0), precip: measure(25.4), snow: measure(12.7), maxWind: measure(16.09344), aveWind: measure(8.04672),
+		},
+		{
+			name: "missing",
+			data: `{"high": {"celsius": "", "fahrenheit": ""}, "low": {}, "qpf_allday": {"in": null, "mm": null}, "maxwind": {"kph": "-9999"}, "pop": "NA"}`,
+		},
+	} {
+		day := &ForecastDay{}
+		decode(t, tc.data, day)
+		d := day.DailyForecast()
+
+		expect(t, tc.name, "high", d.High, tc.high, units.Temperature.Celsius)
+		expect(t, tc.name, "low", d.Low, tc.low, units.Temperature.Celsius)
+		expect(t, tc.name, "precip", d.Precip, tc.precip, units.Precipitation.Millimeters)
+		expect(t, tc.name, "snow", d.Snow, tc.snow, units.Precipitation.Millimeters)
+		expect(t, tc.name, "max wind", d.MaxWind, tc.maxWind, units.Speed.KilometersPerHour)
+		expect(t, tc.name, "average wind", d.AveWind, tc.aveWind, units.Speed.KilometersPerHour)
+		expect(t, tc.name, "pop", d.PrecipProbability, tc.pop, identity)
+
+		if !d.Date.IsZero() {
+			t.Fatalf("%s: expected no date without a forecast date got %s\n", tc.name, d.Date)
+		}
+	}
+}
+
+func TestHourlyPoint(t *testing.T) {
+	for _, tc := range []struct {
+		name                 string
+		data                 string
+		temperature, chill   measured // °C
+		wind                 measured // km/h
+		pressure             measured // hPa
+		precip, snow         measured // mm
+		humidity, sky, point measured // %, %, °C dew point
+	}{
+		{
+			name:        "metric",
+			data:        `{"temp": {"metric": "19"}, "windchill": {"metric": "15"}, "wspd": {"metric": "20"}, "mslp": {"metric": "1012"}, "qpf": {"metric": "1.5"}, "snow": {"metric": "0"}, "humidity": "70", "sky": "40", "dewpoint": {"metric": "12"}}`,
+			temperature: measure(19), chill: measure(15), wind: measure(20), pressure: measure(1012), precip: measure(1.5), snow: measure(0), humidity: measure(70), sky: measure(40), point: measure(12),
+		},
+		{
+			name:        "english",
+			data:        `{"temp": {"english": "212"}, "wspd": {"english": "5"}, "mslp": {"english": "30.00"}, "qpf": {"english": "0.1"}, "dewpoint": {"english": "41"}}`,
+			temperature: measure(100), wind: measure(8.04672), pressure: measure(1015.9166), precip: measure(2.54), point: measure(5),
+		},
+		{
+			name: "missing",
+			data: `{"temp": {"english": "", "metric": ""}, "windchill": {"english": "-9999", "metric": "-9999"}, "wspd": {}, "mslp": {"metric": "NA"}, "humidity": "", "sky": null}`,
+		},
+	} {
+		h := &HourlyForecast{}
+		decode(t, tc.data, h)
+		p := h.HourlyPoint()
+
+		expect(t, tc.name, "temperature", p.Temperature, tc.temperature, units.Temperature.Celsius)
+		expect(t, tc.name, "wind chill", p.Windchill, tc.chill, units.Temperature.Celsius)
+		expect(t, tc.name, "wind", p.WindSpeed, tc.wind, units.Speed.KilometersPerHour)
+		expect(t, tc.name, "pressure", p.Pressure, tc.pressure, units.Pressure.Hectopascals)
+		expect(t, tc.name, "precip", p.Precip, tc.precip, units.Precipitation.Millimeters)
+		expect(t, tc.name, "snow", p.Snow, tc.snow, units.Precipitation.Millimeters)
+		expect(t, tc.name, "humidity", p.Humidity, tc.humidity, identity)
+		expect(t, tc.name, "sky", p.CloudCover, tc.sky, identity)
+		expect(t, tc.name, "dew point", p.Dewpoint, tc.point, units.Temperature.Celsius)
+
+		if p.HeatIndex != nil || p.UVIndex != nil || !p.Time.IsZero() {
+			t.Fatalf("%s: expected unreported values to be missing %#v\n", tc.name, p)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package mqtt
+
+import (
+	"bufio"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// connect is a decoded CONNECT packet
+type connect struct {
+	protocol  string
+	level     byte
+	clientID  string
+	keepAlive uint16
+	username  string
+	password  string
+	will      *Message
+}
+
+func decodeConnect(body []byte) (connect, error) {
+	d := decoder{b: body}
+	c := connect{protocol: d.string(), level: d.byte()}
+	flags := d.byte()
+	c.keepAlive = d.uint16()
+	c.clientID = d.string()
+	if flags&0x04 != 0 {
+		c.will = &Message{Topic: d.string(), Payload: []byte(d.string()), QoS: flags >> 3 & 0x03, Retain: flags&0x20 != 0}
+	}
+
+	if flags&0x80 != 0 {
+		c.username = d.string()
+	}
+
+	if flags&0x40 != 0 {
+		c.password = d.string()
+	}
+
+	if d.err != nil || len(d.b) != 0 {
+		return connect{}, errMalformed
+	}
+	return c, nil
+}
+
+// broker is an in-process stand-in of a MQTT broker. It records connects and
+// publishes, keeps retained messages and publishes wills of connections that
+// end without a DISCONNECT.
+type broker struct {
+	ln       net.Listener
+	username string // required user name, if set
+	password string
+
+	mu       sync.Mutex
+	connects []connect
+	messages []Message          // every publish, in order
+	retained map[string]Message // by topic
+	pings    int
+	conns    []net.Conn
+}
+
+func newBroker(t *testing.T) *broker {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error listening: %s\n", err)
+	}
+
+	b := &broker{ln: ln, retained: make(map[string]Message)}
+	t.Cleanup(func() {
+		ln.Close()
+		b.drop()
+	})
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			b.mu.Lock()
+			b.conns = append(b.conns, conn)
+			b.mu.Unlock()
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+func (b *broker) addr() string {
+	return b.ln.Addr().String()
+}
+
+// drop closes every connection as if the network failed.
+func (b *broker) drop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, conn := range b.conns {
+		conn.Close()
+	}
+	b.conns = nil
+}
+
+func (b *broker) store(m Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.messages = append(b.messages, m)
+	if m.Retain && len(m.Payload) == 0 {
+		delete(b.retained, m.Topic)
+	} else if m.Retain {
+		b.retained[m.Topic] = m
+	}
+}
+
+func (b *broker) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	p, err := readPacket(r, 1<<20)
+	if err != nil || p.kind != connectPacket {
+		return
+	}
+
+	c, err := decodeConnect(p.body)
+	if err != nil {
+		return
+	}
+
+	b.mu.Lock()
+	b.connects = append(b.connects, c)
+	b.mu.Unlock()
+
+	code := byte(0)
+	switch {
+	case c.protocol != "MQTT" || c.level != 4:
+		code = 1
+	case b.username != "" && (c.username != b.username || c.password != b.password):
+		code = 4
+	}
+
+	conn.Write(appendPacket(nil, packet{kind: connackPacket, body: []byte{0, code}}))
+	if code != 0 {
+		return
+	}
+
+	for {
+		p, err := readPacket(r, 1<<20)
+		if err != nil {
+			break
+		}
+
+		switch p.kind {
+		case publishPacket:
+			m, id, err := decodePublish(p)
+			if err != nil {
+				return
+			}
+
+			b.store(m)
+			if m.QoS == 1 {
+				conn.Write(appendPacket(nil, packet{kind: pubackPacket, body: []byte{byte(id >> 8), byte(id)}}))
+			}
+		case pingreqPacket:
+			b.mu.Lock()
+			b.pings++
+			b.mu.Unlock()
+			conn.Write(appendPacket(nil, packet{kind: pingrespPacket}))
+		case disconnectPacket:
+			return
+		default:
+			return
+		}
+	}
+
+	if c.will != nil {
+		b.store(*c.will)
+	}
+}
+
+// payload returns the retained payload of a topic.
+func (b *broker) payload(topic string) (string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	m, ok := b.retained[topic]
+	return string(m.Payload), ok
+}
+
+// published returns the publishes to a topic.
+func (b *broker) published(topic string) []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var messages []Message
+	for _, m := range b.messages {
+		if m.Topic == topic {
+			messages = append(messages, m)
+		}
+	}
+	return messages
+}
+
+// waitFor polls cond until it holds or a second passed.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s\n", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
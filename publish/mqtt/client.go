@@ -0,0 +1,377 @@
+// Package mqtt publishes weather underground conditions and forecasts to an
+// MQTT broker, with Home Assistant discovery of the published sensors. It
+// includes the parts of an MQTT 3.1.1 client publishing needs: connecting,
+// QoS 0 and 1 publishes, keep alives and wills.
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultKeepAlive between pings of the broker
+const DefaultKeepAlive = time.Minute
+
+// maxIncoming is the largest packet accepted from the broker, the client
+// only expects acknowledgements
+const maxIncoming = 64 * 1024
+
+// Errors returned by the client
+var (
+	ErrClosed = errors.New("mqtt: client closed")
+	ErrTopic  = errors.New("mqtt: invalid topic name")
+	ErrQoS    = errors.New("mqtt: unsupported QoS")
+)
+
+// connectCodes are the refusal reasons of CONNACK return codes
+var connectCodes = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+// ConnectError is returned when the broker refuses the connection
+type ConnectError struct {
+	Code byte // CONNACK return code
+}
+
+func (e *ConnectError) Error() string {
+	reason, ok := connectCodes[e.Code]
+	if !ok {
+		reason = fmt.Sprintf("return code %d", e.Code)
+	}
+	return "mqtt: connection refused: " + reason
+}
+
+// Options of a connection
+type Options struct {
+	ClientID  string        // may be empty, the broker then assigns one
+	Username  string        // sent if not empty
+	Password  string        // sent if not empty, requires a Username
+	KeepAlive time.Duration // between pings, DefaultKeepAlive if 0, disabled if negative
+	Will      *Message      // published by the broker when the connection is lost
+}
+
+// Client is a MQTT 3.1.1 client with a clean session. It is safe for
+// concurrent use.
+type Client struct {
+	conn      net.Conn
+	keepAlive time.Duration
+
+	wmu sync.Mutex // serializes writes
+
+	mu     sync.Mutex
+	nextID uint16
+	acks   map[uint16]chan struct{} // by packet identifier of QoS 1 publishes
+	err    error                    // why the connection ended
+	done   chan struct{}
+}
+
+// Dial connects to the broker at the tcp address, e.g. localhost:1883.
+func Dial(ctx context.Context, addr string, opts Options) (*Client, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := NewClient(ctx, conn, opts)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewClient connects over conn, e.g. a TLS connection, and waits for the
+// broker to accept the connection. conn is not closed on errors.
+func NewClient(ctx context.Context, conn net.Conn, opts Options) (*Client, error) {
+	if w := opts.Will; w != nil {
+		switch {
+		case !validTopic(w.Topic):
+			return nil, fmt.Errorf("mqtt: invalid will: %w", ErrTopic)
+		case w.QoS > 1:
+			return nil, fmt.Errorf("mqtt: invalid will: %w", ErrQoS)
+		case len(w.Payload) > 65535: // length prefixed in the connect packet
+			return nil, errors.New("mqtt: will payload longer than 65535 bytes")
+		}
+	}
+
+	// MQTT 3.1.1 does not allow a password without a user name
+	if opts.Password != "" && opts.Username == "" {
+		return nil, errors.New("mqtt: password without a username")
+	}
+
+	keepAlive := opts.KeepAlive
+	if keepAlive == 0 {
+		keepAlive = DefaultKeepAlive
+	}
+
+	if keepAlive < 0 {
+		keepAlive = 0
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if _, err := conn.Write(appendPacket(nil, encodeConnect(opts, keepAlive))); err != nil {
+		return nil, contextError(ctx, err)
+	}
+
+	r := bufio.NewReader(conn)
+	p, err := readPacket(r, maxIncoming)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+
+	if p.kind != connackPacket || len(p.body) != 2 {
+		return nil, errMalformed
+	}
+
+	if code := p.body[1]; code != 0 {
+		return nil, &ConnectError{Code: code}
+	}
+
+	if !stop() {
+		return nil, ctx.Err()
+	}
+	conn.SetDeadline(time.Time{})
+
+	c := &Client{
+		conn:      conn,
+		keepAlive: keepAlive,
+		acks:      make(map[uint16]chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go c.read(r)
+	if keepAlive > 0 {
+		go c.ping()
+	}
+	return c, nil
+}
+
+// contextError returns the error of ctx when it ended the connection attempt.
+func contextError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// encodeConnect returns the CONNECT packet of the options.
+func encodeConnect(opts Options, keepAlive time.Duration) packet {
+	flags := byte(0x02) // clean session
+	if opts.Will != nil {
+		flags |= 0x04 | opts.Will.QoS<<3
+		if opts.Will.Retain {
+			flags |= 0x20
+		}
+	}
+
+	if opts.Username != "" {
+		flags |= 0x80
+	}
+
+	if opts.Password != "" {
+		flags |= 0x40
+	}
+
+	body := appendString(nil, "MQTT")
+	body = append(body, 4, flags) // protocol level 4 is 3.1.1
+	body = binary.BigEndian.AppendUint16(body, uint16(min(keepAlive/time.Second, 65535)))
+	body = appendString(body, opts.ClientID)
+	if opts.Will != nil {
+		body = appendString(body, opts.Will.Topic)
+		body = appendString(body, string(opts.Will.Payload))
+	}
+
+	if opts.Username != "" {
+		body = appendString(body, opts.Username)
+	}
+
+	if opts.Password != "" {
+		body = appendString(body, opts.Password)
+	}
+	return packet{kind: connectPacket, body: body}
+}
+
+// validTopic reports whether name is a topic messages can be published to.
+func validTopic(name string) bool {
+	return name != "" && len(name) <= 65535 && !strings.ContainsAny(name, "#+\x00")
+}
+
+// read handles packets from the broker until the connection ends. Without
+// a packet for one and a half keep alives the broker is considered gone.
+func (c *Client) read(r *bufio.Reader) {
+	for {
+		if c.keepAlive > 0 {
+			c.conn.SetReadDeadline(time.Now().Add(c.keepAlive * 3 / 2))
+		}
+
+		p, err := readPacket(r, maxIncoming)
+		if err != nil {
+			c.fail(err)
+			return
+		}
+
+		switch p.kind {
+		case pubackPacket:
+			if len(p.body) != 2 {
+				c.fail(errMalformed)
+				return
+			}
+
+			id := binary.BigEndian.Uint16(p.body)
+			c.mu.Lock()
+			if ack, ok := c.acks[id]; ok {
+				close(ack)
+				delete(c.acks, id)
+			}
+			c.mu.Unlock()
+		case pingrespPacket, publishPacket:
+			// nothing is subscribed, publishes are ignored
+		default:
+			c.fail(fmt.Errorf("mqtt: unexpected packet type %d", p.kind))
+			return
+		}
+	}
+}
+
+// ping sends a PINGREQ every keep alive until the connection ends.
+func (c *Client) ping() {
+	ticker := time.NewTicker(c.keepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if err := c.write(context.Background(), packet{kind: pingreqPacket}); err != nil {
+				c.fail(err)
+				return
+			}
+		}
+	}
+}
+
+// write writes a packet, the write is abandoned when ctx is done.
+func (c *Client) write(ctx context.Context, p packet) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.Err()
+	default:
+	}
+
+	deadline, _ := ctx.Deadline()
+	c.conn.SetWriteDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() { c.conn.SetWriteDeadline(time.Now()) })
+	defer stop()
+
+	if _, err := c.conn.Write(appendPacket(nil, p)); err != nil {
+		// a partial packet can not be recovered from
+		err = contextError(ctx, err)
+		c.fail(err)
+		return err
+	}
+	return nil
+}
+
+// fail ends the connection with err, the first error is kept.
+func (c *Client) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return
+	}
+
+	c.err = err
+	close(c.done)
+	c.conn.Close()
+}
+
+// Publish publishes the message. QoS 1 publishes wait for the broker to
+// acknowledge them.
+func (c *Client) Publish(ctx context.Context, m Message) error {
+	if !validTopic(m.Topic) {
+		return ErrTopic
+	}
+
+	if m.QoS > 1 {
+		return ErrQoS
+	}
+
+	if m.QoS == 0 {
+		return c.write(ctx, encodePublish(m, 0))
+	}
+
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return c.err
+	}
+
+	for {
+		c.nextID++
+		if _, used := c.acks[c.nextID]; c.nextID != 0 && !used {
+			break
+		}
+	}
+
+	id, ack := c.nextID, make(chan struct{})
+	c.acks[id] = ack
+	c.mu.Unlock()
+
+	if err := c.write(ctx, encodePublish(m, id)); err != nil {
+		return err
+	}
+
+	select {
+	case <-ack:
+		return nil
+	case <-c.done:
+		return c.Err()
+	case <-ctx.Done():
+		c.mu.Lock()
+		delete(c.acks, id)
+		c.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Done is closed when the connection ends.
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns why the connection ended, nil while it is connected.
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close disconnects from the broker, the will is not published.
+func (c *Client) Close() error {
+	err := c.write(context.Background(), packet{kind: disconnectPacket})
+	c.fail(ErrClosed)
+	if errors.Is(err, ErrClosed) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,171 @@
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func dial(t *testing.T, b *broker, opts Options) *Client {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	c, err := Dial(ctx, b.addr(), opts)
+	if err != nil {
+		t.Fatalf("error connecting: %s\n", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestRemainingLength(t *testing.T) {
+	for _, tc := range []struct{ length, header int }{{0, 2}, {127, 2}, {128, 3}, {16383, 3}, {16384, 4}, {2097152, 5}} {
+		encoded := appendPacket(nil, packet{kind: publishPacket, flags: 0x03, body: make([]byte, tc.length)})
+		if len(encoded) != tc.length+tc.header {
+			t.Fatalf("expected a %d byte header for length %d got %d\n", tc.header, tc.length, len(encoded)-tc.length)
+		}
+
+		p, err := readPacket(bufio.NewReader(bytes.NewReader(encoded)), maxRemainingLength)
+		if err != nil {
+			t.Fatalf("error reading packet of length %d: %s\n", tc.length, err)
+		}
+
+		if p.kind != publishPacket || p.flags != 0x03 || len(p.body) != tc.length {
+			t.Fatalf("expected the packet back got type %d flags %x length %d\n", p.kind, p.flags, len(p.body))
+		}
+	}
+
+	if _, err := readPacket(bufio.NewReader(bytes.NewReader([]byte{0x30, 0xff, 0xff, 0xff, 0xff, 0x01})), maxRemainingLength); err != errMalformed {
+		t.Fatalf("expected a five byte length to be malformed got %v\n", err)
+	}
+
+	if _, err := readPacket(bufio.NewReader(bytes.NewReader([]byte{0x30, 0x80, 0x01})), 64); err == nil {
+		t.Fatalf("expected a packet above the limit to be rejected\n")
+	}
+}
+
+func TestConnect(t *testing.T) {
+	b := newBroker(t)
+	b.username, b.password = "user", "secret"
+
+	ctx := context.Background()
+	_, err := Dial(ctx, b.addr(), Options{ClientID: "wug", Username: "user", Password: "wrong"})
+	var connectErr *ConnectError
+	if !errors.As(err, &connectErr) || connectErr.Code != 4 {
+		t.Fatalf("expected the connection to be refused got %v\n", err)
+	}
+
+	if err.Error() != "mqtt: connection refused: bad user name or password" {
+		t.Fatalf("unexpected error message %s\n", err)
+	}
+
+	if _, err := Dial(ctx, b.addr(), Options{Password: "secret"}); err == nil || err.Error() != "mqtt: password without a username" {
+		t.Fatalf("expected a password without a username to be rejected got %v\n", err)
+	}
+
+	if _, err := Dial(ctx, b.addr(), Options{Will: &Message{Topic: "wug/status", QoS: 2}}); !errors.Is(err, ErrQoS) {
+		t.Fatalf("expected ErrQoS for a will with QoS 2 got %v\n", err)
+	}
+
+	if _, err := Dial(ctx, b.addr(), Options{Will: &Message{Topic: "wug/status", Payload: make([]byte, 65536)}}); err == nil || err.Error() != "mqtt: will payload longer than 65535 bytes" {
+		t.Fatalf("expected a long will payload to be rejected got %v\n", err)
+	}
+
+	dial(t, b, Options{ClientID: "wug", Username: "user", Password: "secret", KeepAlive: 30 * time.Second})
+	b.mu.Lock()
+	c := b.connects[1]
+	b.mu.Unlock()
+	if c.clientID != "wug" || c.keepAlive != 30 || c.username != "user" || c.password != "secret" || c.will != nil {
+		t.Fatalf("unexpected connect %+v\n", c)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	b := newBroker(t)
+	c := dial(t, b, Options{})
+	ctx := context.Background()
+
+	if err := c.Publish(ctx, Message{Topic: "wug/a", Payload: []byte("1")}); err != nil {
+		t.Fatalf("error publishing QoS 0: %s\n", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := c.Publish(ctx, Message{Topic: "wug/b", Payload: []byte{byte('0' + i)}, QoS: 1, Retain: true}); err != nil {
+			t.Fatalf("error publishing QoS 1: %s\n", err)
+		}
+	}
+
+	// QoS 1 publishes returned after their acknowledgement
+	if payload, ok := b.payload("wug/b"); !ok || payload != "2" {
+		t.Fatalf("expected the last retained payload got %q\n", payload)
+	}
+
+	waitFor(t, "the QoS 0 publish", func() bool { return len(b.published("wug/a")) == 1 })
+	if _, ok := b.payload("wug/a"); ok {
+		t.Fatalf("expected the QoS 0 publish not to be retained\n")
+	}
+
+	for _, topic := range []string{"", "wug/#", "wug/+/temperature"} {
+		if err := c.Publish(ctx, Message{Topic: topic}); err != ErrTopic {
+			t.Fatalf("expected ErrTopic for %q got %v\n", topic, err)
+		}
+	}
+
+	if err := c.Publish(ctx, Message{Topic: "wug/c", QoS: 2}); err != ErrQoS {
+		t.Fatalf("expected ErrQoS got %v\n", err)
+	}
+}
+
+func TestWill(t *testing.T) {
+	b := newBroker(t)
+	will := &Message{Topic: "wug/status", Payload: []byte("offline"), QoS: 1, Retain: true}
+
+	// a clean disconnect does not publish the will
+	c := dial(t, b, Options{Will: will})
+	if err := c.Close(); err != nil {
+		t.Fatalf("error closing: %s\n", err)
+	}
+
+	if err := c.Publish(context.Background(), Message{Topic: "wug/a"}); err != ErrClosed || c.Err() != ErrClosed {
+		t.Fatalf("expected ErrClosed after closing got %v\n", err)
+	}
+
+	c = dial(t, b, Options{Will: will})
+	b.drop()
+	select {
+	case <-c.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("expected the client to notice the lost connection\n")
+	}
+
+	if c.Err() == nil || c.Err() == ErrClosed {
+		t.Fatalf("expected the read error got %v\n", c.Err())
+	}
+
+	waitFor(t, "the will", func() bool {
+		payload, ok := b.payload("wug/status")
+		return ok && payload == "offline"
+	})
+
+	if n := len(b.published("wug/status")); n != 1 {
+		t.Fatalf("expected a single will got %d\n", n)
+	}
+}
+
+func TestKeepAlive(t *testing.T) {
+	b := newBroker(t)
+	c := dial(t, b, Options{KeepAlive: 10 * time.Millisecond})
+
+	waitFor(t, "pings", func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return b.pings >= 3
+	})
+
+	if c.Err() != nil {
+		t.Fatalf("expected the connection to stay up got %s\n", c.Err())
+	}
+}
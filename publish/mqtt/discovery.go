@@ -0,0 +1,46 @@
+package mqtt
+
+// device groups the entities of a location in Home Assistant
+type device struct {
+	Identifiers  []string `json:"identifiers"`
+	Name         string   `json:"name"`
+	Manufacturer string   `json:"manufacturer"`
+	Model        string   `json:"model,omitempty"` // station id
+}
+
+type availability struct {
+	Topic string `json:"topic"`
+}
+
+// entity holds the discovery fields of an entity
+type entity struct {
+	Name             string         `json:"name"`
+	UniqueID         string         `json:"unique_id"`
+	Device           device         `json:"device"`
+	Availability     []availability `json:"availability"`
+	AvailabilityMode string         `json:"availability_mode"`
+	StateTopic       string         `json:"state_topic"`
+}
+
+// sensorConfig is the discovery config of a sensor entity
+type sensorConfig struct {
+	entity
+	DeviceClass string `json:"device_class,omitempty"`
+	StateClass  string `json:"state_class,omitempty"`
+	Unit        string `json:"unit_of_measurement,omitempty"`
+}
+
+// forecastDay is a day of the forecast list of the JSON state, named as
+// Home Assistant weather forecasts for the forecast template of a template
+// weather entity
+type forecastDay struct {
+	Datetime                 string   `json:"datetime"`
+	Condition                string   `json:"condition,omitempty"`
+	Temperature              *float64 `json:"temperature,omitempty"`
+	TempLow                  *float64 `json:"templow,omitempty"`
+	PrecipitationProbability *float64 `json:"precipitation_probability,omitempty"`
+	Precipitation            *float64 `json:"precipitation,omitempty"`
+	WindSpeed                *float64 `json:"wind_speed,omitempty"`
+	WindBearing              *float64 `json:"wind_bearing,omitempty"`
+	Humidity                 *float64 `json:"humidity,omitempty"`
+}
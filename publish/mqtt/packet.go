@@ -0,0 +1,178 @@
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// packetType is the control packet type of the fixed header
+type packetType byte
+
+// The control packets of MQTT 3.1.1 the client sends or receives
+const (
+	connectPacket    packetType = 1
+	connackPacket    packetType = 2
+	publishPacket    packetType = 3
+	pubackPacket     packetType = 4
+	pingreqPacket    packetType = 12
+	pingrespPacket   packetType = 13
+	disconnectPacket packetType = 14
+)
+
+// maxRemainingLength is the largest remaining length the four byte encoding
+// allows
+const maxRemainingLength = 268435455
+
+// errMalformed is returned for packets that do not decode.
+var errMalformed = errors.New("mqtt: malformed packet")
+
+// packet is a control packet, body is everything after the fixed header
+type packet struct {
+	kind  packetType
+	flags byte // lower four bits of the fixed header
+	body  []byte
+}
+
+// readPacket reads a control packet, packets larger than limit are rejected.
+func readPacket(r *bufio.Reader, limit int) (packet, error) {
+	first, err := r.ReadByte()
+	if err != nil {
+		return packet{}, err
+	}
+
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return packet{}, err
+		}
+
+		length += int(b&0x7f) * multiplier
+		if b&0x80 == 0 {
+			break
+		}
+
+		if i == 3 {
+			return packet{}, errMalformed
+		}
+		multiplier *= 128
+	}
+
+	if length > limit {
+		return packet{}, errors.New("mqtt: packet too large")
+	}
+
+	p := packet{kind: packetType(first >> 4), flags: first & 0x0f, body: make([]byte, length)}
+	if _, err := io.ReadFull(r, p.body); err != nil {
+		return packet{}, err
+	}
+	return p, nil
+}
+
+// appendPacket appends the encoded packet to b.
+func appendPacket(b []byte, p packet) []byte {
+	b = append(b, byte(p.kind)<<4|p.flags)
+	length := len(p.body)
+	for {
+		digit := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			digit |= 0x80
+		}
+
+		b = append(b, digit)
+		if length == 0 {
+			break
+		}
+	}
+	return append(b, p.body...)
+}
+
+// appendString appends a length prefixed string or binary field.
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// decoder reads the fields of a packet body, the first error sticks
+type decoder struct {
+	b   []byte
+	err error
+}
+
+func (d *decoder) byte() byte {
+	if len(d.b) < 1 {
+		d.err = errMalformed
+		return 0
+	}
+
+	v := d.b[0]
+	d.b = d.b[1:]
+	return v
+}
+
+func (d *decoder) uint16() uint16 {
+	if len(d.b) < 2 {
+		d.err = errMalformed
+		return 0
+	}
+
+	v := binary.BigEndian.Uint16(d.b)
+	d.b = d.b[2:]
+	return v
+}
+
+func (d *decoder) string() string {
+	n := int(d.uint16())
+	if len(d.b) < n {
+		d.err = errMalformed
+		return ""
+	}
+
+	v := string(d.b[:n])
+	d.b = d.b[n:]
+	return v
+}
+
+// Message is an application message
+type Message struct {
+	Topic   string
+	Payload []byte
+	QoS     byte // 0 (at most once) or 1 (at least once)
+	Retain  bool // the broker keeps the last retained message of a topic
+}
+
+// encodePublish returns the PUBLISH packet of the message, id is the packet
+// identifier of QoS 1 messages.
+func encodePublish(m Message, id uint16) packet {
+	flags := m.QoS << 1
+	if m.Retain {
+		flags |= 0x01
+	}
+
+	body := appendString(nil, m.Topic)
+	if m.QoS > 0 {
+		body = binary.BigEndian.AppendUint16(body, id)
+	}
+	return packet{kind: publishPacket, flags: flags, body: append(body, m.Payload...)}
+}
+
+// decodePublish returns the message and packet identifier of a PUBLISH packet.
+func decodePublish(p packet) (Message, uint16, error) {
+	m := Message{QoS: (p.flags >> 1) & 0x03, Retain: p.flags&0x01 != 0}
+	d := decoder{b: p.body}
+	m.Topic = d.string()
+
+	var id uint16
+	if m.QoS > 0 {
+		id = d.uint16()
+	}
+
+	if d.err != nil || m.QoS > 2 {
+		return Message{}, 0, errMalformed
+	}
+	m.Payload = d.b
+	return m, id, nil
+}
@@ -0,0 +1,388 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/wirepair/wug"
+	"github.com/wirepair/wug/units"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Publisher defaults
+const (
+	DefaultInterval        = 5 * time.Minute
+	DefaultPrefix          = "wug"
+	DefaultDiscoveryPrefix = "homeassistant"
+)
+
+// Availability payloads
+const (
+	Online  = "online"
+	Offline = "offline"
+)
+
+// Location is a query whose conditions and forecast are published
+type Location struct {
+	Name  string // names the topics and the device, the display name of the observation if empty
+	Query *wug.Query
+}
+
+// Publisher periodically publishes the conditions and forecasts of its
+// locations. The topics of a location are below its node, the slug of its
+// name:
+//
+//	<prefix>/<node>/<sensor>      retained value of each sensor, e.g. temperature
+//	<prefix>/<node>/state         retained JSON of all values and the forecast
+//	<prefix>/<node>/availability  online, or offline when its refresh failed
+//
+// <prefix>/status is online while Run runs, Will returns the message to use
+// as the will of the connection so it turns offline when the connection is
+// lost. Home Assistant discovery configs are published below
+// DiscoveryPrefix the first time a sensor has a value.
+//
+// The MQTT integration of Home Assistant has no weather entities, so none is
+// discovered. The state has the fields of a template weather entity, e.g.
+// with an MQTT sensor of the state topic keeping the JSON as attributes:
+//
+//	mqtt:
+//	  sensor:
+//	    - name: Home weather
+//	      state_topic: wug/home/state
+//	      value_template: "{{ value_json.weather_condition }}"
+//	      json_attributes_topic: wug/home/state
+//	weather:
+//	  - platform: template
+//	    name: Home
+//	    condition_template: "{{ states('sensor.home_weather') }}"
+//	    temperature_template: "{{ state_attr('sensor.home_weather', 'temperature') }}"
+//	    humidity_template: "{{ state_attr('sensor.home_weather', 'humidity') }}"
+//	    forecast_daily_template: "{{ state_attr('sensor.home_weather', 'forecast') }}"
+type Publisher struct {
+	Locations       []Location
+	Interval        time.Duration // between refreshes, DefaultInterval if 0
+	Prefix          string        // of the state topics
+	DiscoveryPrefix string        // of the discovery topics, empty disables discovery
+	System          units.System  // units of the published values
+
+	w *wug.Wug
+	c *Client
+
+	nodes      []string          // by index of Locations, empty until known
+	discovered []map[string]bool // discovered entities by index of Locations
+}
+
+// NewPublisher returns a publisher of the locations publishing with metric
+// units to the default prefixes. Names of locations must have distinct slugs.
+func NewPublisher(w *wug.Wug, c *Client, locations []Location) (*Publisher, error) {
+	p := &Publisher{
+		Locations:       locations,
+		Prefix:          DefaultPrefix,
+		DiscoveryPrefix: DefaultDiscoveryPrefix,
+		System:          units.Metric,
+		w:               w,
+		c:               c,
+		nodes:           make([]string, len(locations)),
+		discovered:      make([]map[string]bool, len(locations)),
+	}
+
+	for i, loc := range locations {
+		p.nodes[i] = slug(loc.Name)
+		p.discovered[i] = make(map[string]bool)
+		if p.used(i, p.nodes[i]) {
+			return nil, fmt.Errorf("mqtt: location %q has the same node as another location: %s", loc.Name, p.nodes[i])
+		}
+	}
+	return p, nil
+}
+
+// used reports whether node is the node of another location than i.
+func (p *Publisher) used(i int, node string) bool {
+	for j, n := range p.nodes {
+		if j != i && n != "" && n == node {
+			return true
+		}
+	}
+	return false
+}
+
+// Will returns the will marking the publisher of the prefix offline.
+func Will(prefix string) *Message {
+	return &Message{Topic: prefix + "/status", Payload: []byte(Offline), QoS: 1, Retain: true}
+}
+
+// slug returns name as a topic level and discovery id, which only allow
+// ASCII, e.g. San Francisco, CA becomes san_francisco_ca. Accents are
+// dropped.
+func slug(name string) string {
+	var b strings.Builder
+	sep := false
+	for _, r := range norm.NFKD.String(strings.ToLower(name)) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if sep && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+			sep = false
+		} else {
+			sep = true
+		}
+	}
+	return b.String()
+}
+
+// Run publishes the status and refreshes the locations every Interval,
+// starting immediately, until ctx is done or the connection is lost. Failed
+// locations are published as offline and retried at the next refresh.
+func (p *Publisher) Run(ctx context.Context) error {
+	interval := p.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	if err := p.publish(ctx, "status", Online); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		p.Refresh(ctx)
+		select {
+		case <-ctx.Done():
+			shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			p.publish(shutdown, "status", Offline)
+			return ctx.Err()
+		case <-p.c.Done():
+			return p.c.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh requests and publishes the conditions and forecast of every
+// location. It returns the errors of the locations, publishing stops at the
+// first error of the client.
+func (p *Publisher) Refresh(ctx context.Context) error {
+	var errs []error
+	for i, loc := range p.Locations {
+		err := p.refresh(ctx, i, loc)
+		if err == nil {
+			continue
+		}
+
+		var refreshErr *refreshError
+		if !errors.As(err, &refreshErr) {
+			return errors.Join(append(errs, err)...)
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// refreshError is a failed request of a location
+type refreshError struct {
+	node string
+	err  error
+}
+
+func (e *refreshError) Error() string { return e.node + ": " + e.err.Error() }
+
+func (e *refreshError) Unwrap() error { return e.err }
+
+// refresh publishes a location, the conditions are published when only the
+// forecast failed.
+func (p *Publisher) refresh(ctx context.Context, i int, loc Location) error {
+	c, err := wug.GetFeature(ctx, p.w, wug.ConditionsFeature, loc.Query)
+	if err != nil {
+		if p.nodes[i] != "" {
+			if err := p.publish(ctx, p.nodes[i]+"/availability", Offline); err != nil {
+				return err
+			}
+		}
+		return &refreshError{node: p.name(i, loc), err: err}
+	}
+
+	s := snapshot{obs: c.Observation()}
+	if p.nodes[i] == "" {
+		node := slug(s.obs.Location.Name)
+		if node == "" {
+			return &refreshError{node: p.name(i, loc), err: errors.New("no location name to publish as")}
+		}
+
+		if p.used(i, node) {
+			return &refreshError{node: p.name(i, loc), err: fmt.Errorf("node %s of the observation is used by another location", node)}
+		}
+		p.nodes[i] = node
+	}
+
+	f, ferr := wug.GetFeature(ctx, p.w, wug.ForecastFeature, loc.Query)
+	if ferr == nil {
+		s.days = f.DailyForecasts()
+	}
+
+	if err := p.publishSnapshot(ctx, i, loc, &s); err != nil {
+		return err
+	}
+
+	if ferr != nil {
+		return &refreshError{node: p.nodes[i], err: fmt.Errorf("forecast: %w", ferr)}
+	}
+	return nil
+}
+
+// name returns the name of a location in errors.
+func (p *Publisher) name(i int, loc Location) string {
+	if p.nodes[i] != "" {
+		return p.nodes[i]
+	}
+	return loc.Query.Format("%s")
+}
+
+// publish publishes a retained message below the prefix.
+func (p *Publisher) publish(ctx context.Context, topic, payload string) error {
+	return p.c.Publish(ctx, Message{Topic: p.Prefix + "/" + topic, Payload: []byte(payload), QoS: 1, Retain: true})
+}
+
+// publishSnapshot publishes the discovery configs of new values, the values,
+// the state and the availability of a location.
+func (p *Publisher) publishSnapshot(ctx context.Context, i int, loc Location, s *snapshot) error {
+	node := p.nodes[i]
+	values := make(map[string]interface{})
+	for _, sn := range sensors {
+		v, ok := sn.value(s, p.System)
+		if !ok {
+			continue
+		}
+		values[sn.key] = v
+
+		if err := p.discover(ctx, i, loc, s, sn); err != nil {
+			return err
+		}
+
+		if err := p.publish(ctx, node+"/"+sn.key, format(v)); err != nil {
+			return err
+		}
+	}
+
+	state, err := json.Marshal(p.state(s, values))
+	if err != nil {
+		return err
+	}
+
+	if err := p.publish(ctx, node+"/state", string(state)); err != nil {
+		return err
+	}
+	return p.publish(ctx, node+"/availability", Online)
+}
+
+// format returns the payload of a sensor value.
+func format(v interface{}) string {
+	switch v := v.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	}
+	return fmt.Sprint(v)
+}
+
+// state returns the JSON state of a snapshot, the values of the sensors with
+// the time, tags, Home Assistant condition and forecast.
+func (p *Publisher) state(s *snapshot, values map[string]interface{}) map[string]interface{} {
+	if !s.obs.Time.IsZero() {
+		values["time"] = s.obs.Time.Format(time.RFC3339)
+	}
+
+	if s.obs.Location.StationID != "" {
+		values["station_id"] = s.obs.Location.StationID
+	}
+
+	if s.obs.Location.Name != "" {
+		values["location"] = s.obs.Location.Name
+	}
+
+	if condition := haCondition(s.obs.Icon); condition != "" {
+		values["weather_condition"] = condition
+	}
+
+	forecast := make([]forecastDay, 0, len(s.days))
+	for _, d := range s.days {
+		forecast = append(forecast, forecastDay{
+			Datetime:                 d.Date.Format(time.RFC3339),
+			Condition:                haCondition(d.Icon),
+			Temperature:              in(d.High, p.System),
+			TempLow:                  in(d.Low, p.System),
+			PrecipitationProbability: d.PrecipProbability,
+			Precipitation:            in(d.Precip, p.System),
+			WindSpeed:                in(d.MaxWind, p.System),
+			WindBearing:              d.MaxWindDirection,
+			Humidity:                 d.Humidity,
+		})
+	}
+	values["forecast"] = forecast
+	return values
+}
+
+// in returns an optional measurement in the unit system.
+func in[M units.Measure](m *M, system units.System) *float64 {
+	if m == nil {
+		return nil
+	}
+	v, _ := (*m).In(system)
+	v = round(v)
+	return &v
+}
+
+// discover publishes the discovery config of a sensor unless it was
+// published before.
+func (p *Publisher) discover(ctx context.Context, i int, loc Location, s *snapshot, sn sensor) error {
+	key := sn.key
+
+	if p.DiscoveryPrefix == "" || p.discovered[i][key] {
+		return nil
+	}
+
+	node := p.nodes[i]
+	name := loc.Name
+	if name == "" {
+		name = s.obs.Location.Name
+	}
+
+	e := entity{
+		UniqueID: "wug_" + node + "_" + key,
+		Device: device{
+			Identifiers:  []string{"wug_" + node},
+			Name:         name,
+			Manufacturer: "Weather Underground",
+			Model:        s.obs.Location.StationID,
+		},
+		Availability:     []availability{{Topic: p.Prefix + "/status"}, {Topic: p.Prefix + "/" + node + "/availability"}},
+		AvailabilityMode: "all",
+	}
+
+	e.Name, e.StateTopic = sn.name, p.Prefix+"/"+node+"/"+key
+	config := sensorConfig{entity: e, DeviceClass: sn.deviceClass, StateClass: sn.stateClass, Unit: sn.unit(p.System)}
+	payload, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+
+	topic := p.DiscoveryPrefix + "/sensor/wug_" + node + "/" + key + "/config"
+	if err := p.c.Publish(ctx, Message{Topic: topic, Payload: payload, QoS: 1, Retain: true}); err != nil {
+		return err
+	}
+	p.discovered[i][key] = true
+	return nil
+}
@@ -0,0 +1,294 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/wirepair/wug"
+	"github.com/wirepair/wug/units"
+	"github.com/wirepair/wug/wugtest"
+)
+
+func newTestPublisher(t *testing.T) (*Publisher, *wugtest.Server, *broker) {
+	s := wugtest.NewServer()
+	t.Cleanup(s.Close)
+	s.SetKey("testkey")
+	s.SetClock(func() time.Time { return time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC) })
+	s.SetLocation("94101", wugtest.DefaultLocation)
+
+	b := newBroker(t)
+	c := dial(t, b, Options{Will: Will(DefaultPrefix)})
+	p, err := NewPublisher(s.Wug(), c, []Location{{Name: "Home", Query: wug.NewQueryByUsZip("testkey", "94101")}})
+	if err != nil {
+		t.Fatalf("error creating publisher: %s\n", err)
+	}
+	return p, s, b
+}
+
+func decode(t *testing.T, b *broker, topic string, v interface{}) {
+	payload, ok := b.payload(topic)
+	if !ok {
+		t.Fatalf("expected a retained message on %s\n", topic)
+	}
+
+	if err := json.Unmarshal([]byte(payload), v); err != nil {
+		t.Fatalf("error decoding %s: %s\n", topic, err)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	p, s, b := newTestPublisher(t)
+	if err := p.Refresh(context.Background()); err != nil {
+		t.Fatalf("error refreshing: %s\n", err)
+	}
+
+	if len(s.Requests()) != 2 {
+		t.Fatalf("expected a conditions and forecast request got %d\n", len(s.Requests()))
+	}
+
+	if payload, _ := b.payload("wug/home/availability"); payload != Online {
+		t.Fatalf("expected the location to be online got %q\n", payload)
+	}
+
+	var state map[string]interface{}
+	decode(t, b, "wug/home/state", &state)
+	if state["station_id"] != "WUGTEST" || state["location"] != "San Francisco, CA" || state["weather_condition"] == nil {
+		t.Fatalf("unexpected state %v\n", state)
+	}
+
+	payload, _ := b.payload("wug/home/temperature")
+	temperature, err := strconv.ParseFloat(payload, 64)
+	if err != nil {
+		t.Fatalf("error parsing temperature: %s\n", err)
+	}
+
+	if state["temperature"] != temperature {
+		t.Fatalf("expected the state temperature %v to be the topic temperature %v\n", state["temperature"], temperature)
+	}
+
+	forecast, _ := state["forecast"].([]interface{})
+	if len(forecast) == 0 {
+		t.Fatalf("expected a forecast in the state got %v\n", state["forecast"])
+	}
+
+	day := forecast[0].(map[string]interface{})
+	if day["datetime"] == nil || day["temperature"] == nil || day["templow"] == nil || day["condition"] == nil {
+		t.Fatalf("unexpected forecast day %v\n", day)
+	}
+
+	if payload, _ := b.payload("wug/home/forecast_high"); payload != strconv.FormatFloat(day["temperature"].(float64), 'f', -1, 64) {
+		t.Fatalf("expected the forecast high of the first day got %s\n", payload)
+	}
+}
+
+func TestDiscovery(t *testing.T) {
+	p, _, b := newTestPublisher(t)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := p.Refresh(ctx); err != nil {
+			t.Fatalf("error refreshing: %s\n", err)
+		}
+	}
+
+	var sensor map[string]interface{}
+	decode(t, b, "homeassistant/sensor/wug_home/temperature/config", &sensor)
+	for key, want := range map[string]interface{}{
+		"name":                "Temperature",
+		"unique_id":           "wug_home_temperature",
+		"state_topic":         "wug/home/temperature",
+		"device_class":        "temperature",
+		"state_class":         "measurement",
+		"unit_of_measurement": "°C",
+		"availability_mode":   "all",
+	} {
+		if sensor[key] != want {
+			t.Fatalf("expected %s %v got %v\n", key, want, sensor[key])
+		}
+	}
+
+	device := sensor["device"].(map[string]interface{})
+	if device["name"] != "Home" || device["model"] != "WUGTEST" || device["identifiers"].([]interface{})[0] != "wug_home" {
+		t.Fatalf("unexpected device %v\n", device)
+	}
+
+	if availability := sensor["availability"].([]interface{}); len(availability) != 2 {
+		t.Fatalf("expected the status and location availability got %v\n", availability)
+	}
+
+	var condition map[string]interface{}
+	decode(t, b, "homeassistant/sensor/wug_home/condition/config", &condition)
+	if _, ok := condition["device_class"]; ok || condition["state_class"] != nil || condition["unit_of_measurement"] != nil {
+		t.Fatalf("expected a plain text sensor got %v\n", condition)
+	}
+
+	// the MQTT integration has no weather entities
+	if n := len(b.published("homeassistant/weather/wug_home/weather/config")); n != 0 {
+		t.Fatalf("expected no weather config got %d\n", n)
+	}
+
+	// configs are published once, values every refresh
+	if n := len(b.published("homeassistant/sensor/wug_home/temperature/config")); n != 1 {
+		t.Fatalf("expected a single discovery config got %d\n", n)
+	}
+
+	if n := len(b.published("wug/home/temperature")); n != 2 {
+		t.Fatalf("expected a value per refresh got %d\n", n)
+	}
+}
+
+func TestRefreshImperial(t *testing.T) {
+	p, _, b := newTestPublisher(t)
+	p.System = units.Imperial
+	p.DiscoveryPrefix = ""
+	p.Prefix = "weather"
+	if err := p.Refresh(context.Background()); err != nil {
+		t.Fatalf("error refreshing: %s\n", err)
+	}
+
+	b.mu.Lock()
+	for topic := range b.retained {
+		if topic[:8] != "weather/" {
+			t.Fatalf("expected only topics below the prefix got %s\n", topic)
+		}
+	}
+	b.mu.Unlock()
+
+	var state map[string]interface{}
+	decode(t, b, "weather/home/state", &state)
+	payload, _ := b.payload("weather/home/pressure")
+	if inHg, _ := strconv.ParseFloat(payload, 64); inHg < 25 || inHg > 35 || state["pressure"] != inHg {
+		t.Fatalf("expected the pressure in inHg got %s\n", payload)
+	}
+}
+
+func TestRefreshErrors(t *testing.T) {
+	p, s, b := newTestPublisher(t)
+	ctx := context.Background()
+
+	// a failing forecast still publishes the conditions
+	s.Handle("forecast", "", wugtest.StatusResponse(http.StatusBadGateway))
+	err := p.Refresh(ctx)
+	var statusErr *wug.StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("expected the forecast error got %v\n", err)
+	}
+
+	if payload, _ := b.payload("wug/home/availability"); payload != Online {
+		t.Fatalf("expected the location to be online got %q\n", payload)
+	}
+
+	if _, ok := b.payload("wug/home/forecast_high"); ok {
+		t.Fatalf("expected no forecast values\n")
+	}
+
+	s.Handle("conditions", "", wugtest.ErrorResponse("keynotfound", "this key does not exist"))
+	err = p.Refresh(ctx)
+	var apiErr *wug.APIError
+	if !errors.As(err, &apiErr) || apiErr.Type != "keynotfound" {
+		t.Fatalf("expected the API error got %v\n", err)
+	}
+
+	if payload, _ := b.payload("wug/home/availability"); payload != Offline {
+		t.Fatalf("expected the location to be offline got %q\n", payload)
+	}
+
+	// the last values are kept
+	if _, ok := b.payload("wug/home/temperature"); !ok {
+		t.Fatalf("expected the last temperature to be retained\n")
+	}
+}
+
+func TestRun(t *testing.T) {
+	p, _, b := newTestPublisher(t)
+	p.Locations[0].Name = ""
+	p.nodes[0] = ""
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- p.Run(ctx) }()
+
+	waitFor(t, "the first refresh", func() bool {
+		payload, _ := b.payload("wug/san_francisco_ca/availability")
+		return payload == Online
+	})
+
+	if payload, _ := b.payload("wug/status"); payload != Online {
+		t.Fatalf("expected the status to be online got %q\n", payload)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("expected context.Canceled got %v\n", err)
+	}
+
+	if payload, _ := b.payload("wug/status"); payload != Offline {
+		t.Fatalf("expected the status to be offline got %q\n", payload)
+	}
+}
+
+func TestRunConnectionLost(t *testing.T) {
+	p, _, b := newTestPublisher(t)
+	p.Interval = time.Hour
+
+	done := make(chan error)
+	go func() { done <- p.Run(context.Background()) }()
+	waitFor(t, "the first refresh", func() bool {
+		_, ok := b.payload("wug/home/state")
+		return ok
+	})
+
+	b.drop()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatalf("expected the connection error\n")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected Run to return when the connection is lost\n")
+	}
+
+	waitFor(t, "the will", func() bool {
+		payload, _ := b.payload("wug/status")
+		return payload == Offline
+	})
+}
+
+func TestDuplicateNodes(t *testing.T) {
+	p, s, b := newTestPublisher(t)
+	query := wug.NewQueryByUsZip("testkey", "94101")
+	if _, err := NewPublisher(s.Wug(), p.c, []Location{{Name: "Home", Query: query}, {Name: "home!", Query: query}}); err == nil {
+		t.Fatalf("expected names with the same slug to be rejected\n")
+	}
+
+	// an unnamed location can not take the node of a named one
+	p, err := NewPublisher(s.Wug(), p.c, []Location{{Name: "San Francisco, CA", Query: query}, {Query: query}})
+	if err != nil {
+		t.Fatalf("error creating publisher: %s\n", err)
+	}
+
+	if err := p.Refresh(context.Background()); err == nil || p.nodes[1] != "" {
+		t.Fatalf("expected the unnamed location to fail got %v %q\n", err, p.nodes[1])
+	}
+
+	if _, ok := b.payload("wug/san_francisco_ca/temperature"); !ok {
+		t.Fatalf("expected the named location to be published\n")
+	}
+}
+
+func TestSlug(t *testing.T) {
+	for name, want := range map[string]string{
+		"Home":               "home",
+		"San Francisco, CA":  "san_francisco_ca",
+		"  Zürich / Nord -1": "zurich_nord_1",
+		"":                   "",
+	} {
+		if got := slug(name); got != want {
+			t.Fatalf("expected slug %q of %q got %q\n", want, name, got)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package mqtt
+
+import (
+	"math"
+
+	"github.com/wirepair/wug"
+	"github.com/wirepair/wug/model"
+	"github.com/wirepair/wug/units"
+)
+
+// snapshot is what a refresh of a location returned
+type snapshot struct {
+	obs  model.Observation
+	days []model.DailyForecast // empty when the forecast failed
+}
+
+// sensor is a value of a snapshot published to its own topic and discovered
+// as a Home Assistant sensor
+type sensor struct {
+	key, name   string // topic leaf and entity name
+	deviceClass string // Home Assistant device class, if any
+	stateClass  string // Home Assistant state class, empty for text
+	unit        func(system units.System) string
+	value       func(s *snapshot, system units.System) (v interface{}, ok bool)
+}
+
+func fixed(unit string) func(units.System) string {
+	return func(units.System) string { return unit }
+}
+
+// measured is a sensor of a measurement in the unit system, rounded to two
+// decimals.
+func measured[M units.Measure](key, name, deviceClass, stateClass string, get func(s *snapshot) *M) sensor {
+	return sensor{
+		key: key, name: name, deviceClass: deviceClass, stateClass: stateClass,
+		unit: func(system units.System) string {
+			var zero M
+			_, unit := zero.In(system)
+			return unit
+		},
+		value: func(s *snapshot, system units.System) (interface{}, bool) {
+			m := get(s)
+			if m == nil {
+				return nil, false
+			}
+			v, _ := (*m).In(system)
+			return round(v), true
+		},
+	}
+}
+
+// number is a sensor of a unit system independent number.
+func number(key, name, deviceClass, unit string, get func(s *snapshot) *float64) sensor {
+	return sensor{
+		key: key, name: name, deviceClass: deviceClass, stateClass: "measurement", unit: fixed(unit),
+		value: func(s *snapshot, _ units.System) (interface{}, bool) {
+			v := get(s)
+			if v == nil {
+				return nil, false
+			}
+			return round(*v), true
+		},
+	}
+}
+
+// text is a sensor of a string, empty strings are missing.
+func text(key, name string, get func(s *snapshot) string) sensor {
+	return sensor{
+		key: key, name: name, unit: fixed(""),
+		value: func(s *snapshot, _ units.System) (interface{}, bool) {
+			v := get(s)
+			return v, v != ""
+		},
+	}
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// today returns a getter of the forecast of the first day.
+func today[M any](get func(d *model.DailyForecast) *M) func(s *snapshot) *M {
+	return func(s *snapshot) *M {
+		if len(s.days) == 0 {
+			return nil
+		}
+		return get(&s.days[0])
+	}
+}
+
+// sensors of a location, in the order they are published
+var sensors = []sensor{
+	text("condition", "Condition", func(s *snapshot) string { return s.obs.Condition }),
+	measured("temperature", "Temperature", "temperature", "measurement", func(s *snapshot) *units.Temperature { return s.obs.Temperature }),
+	measured("dewpoint", "Dew point", "temperature", "measurement", func(s *snapshot) *units.Temperature { return s.obs.Dewpoint }),
+	measured("feels_like", "Feels like", "temperature", "measurement", func(s *snapshot) *units.Temperature { return s.obs.FeelsLike }),
+	number("humidity", "Humidity", "humidity", "%", func(s *snapshot) *float64 { return s.obs.Humidity }),
+	measured("pressure", "Pressure", "atmospheric_pressure", "measurement", func(s *snapshot) *units.Pressure { return s.obs.Pressure }),
+	measured("wind_speed", "Wind speed", "wind_speed", "measurement", func(s *snapshot) *units.Speed { return s.obs.WindSpeed }),
+	measured("wind_gust", "Wind gust", "wind_speed", "measurement", func(s *snapshot) *units.Speed { return s.obs.WindGust }),
+	number("wind_bearing", "Wind bearing", "", "°", func(s *snapshot) *float64 { return s.obs.WindDirection }),
+	measured("visibility", "Visibility", "distance", "measurement", func(s *snapshot) *units.Length { return s.obs.Visibility }),
+	number("uv_index", "UV index", "", "UV index", func(s *snapshot) *float64 { return s.obs.UVIndex }),
+	number("solar_radiation", "Solar radiation", "irradiance", "W/m²", func(s *snapshot) *float64 { return s.obs.SolarRadiation }),
+	measured("precip_1hr", "Precipitation last hour", "precipitation", "measurement", func(s *snapshot) *units.Precipitation { return s.obs.Precip1Hr }),
+	measured("precip_today", "Precipitation today", "precipitation", "total_increasing", func(s *snapshot) *units.Precipitation { return s.obs.PrecipToday }),
+	measured("forecast_high", "Forecast high", "temperature", "measurement", today(func(d *model.DailyForecast) *units.Temperature { return d.High })),
+	measured("forecast_low", "Forecast low", "temperature", "measurement", today(func(d *model.DailyForecast) *units.Temperature { return d.Low })),
+	number("forecast_pop", "Forecast chance of precipitation", "", "%", today(func(d *model.DailyForecast) *float64 { return d.PrecipProbability })),
+	measured("forecast_precip", "Forecast precipitation", "precipitation", "measurement", today(func(d *model.DailyForecast) *units.Precipitation { return d.Precip })),
+}
+
+// haConditions are the Home Assistant weather conditions of icons
+var haConditions = map[wug.Icon]string{
+	wug.IconClear:          "sunny",
+	wug.IconSunny:          "sunny",
+	wug.IconMostlySunny:    "partlycloudy",
+	wug.IconPartlySunny:    "partlycloudy",
+	wug.IconPartlyCloudy:   "partlycloudy",
+	wug.IconMostlyCloudy:   "cloudy",
+	wug.IconCloudy:         "cloudy",
+	wug.IconHazy:           "fog",
+	wug.IconFog:            "fog",
+	wug.IconChanceFlurries: "snowy",
+	wug.IconFlurries:       "snowy",
+	wug.IconChanceRain:     "rainy",
+	wug.IconRain:           "rainy",
+	wug.IconChanceSleet:    "snowy-rainy",
+	wug.IconSleet:          "snowy-rainy",
+	wug.IconChanceSnow:     "snowy",
+	wug.IconSnow:           "snowy",
+	wug.IconChanceTStorms:  "lightning-rainy",
+	wug.IconTStorms:        "lightning-rainy",
+}
+
+// haCondition returns the Home Assistant condition of an icon name, clear
+// nights are clear-night. Unknown icons return an empty string.
+func haCondition(icon string) string {
+	i, night := wug.ParseIcon(icon)
+	condition := haConditions[i]
+	if night && condition == "sunny" {
+		return "clear-night"
+	}
+	return condition
+}
@@ -0,0 +1,192 @@
+package wug
+
+import (
+	"github.com/wirepair/wug/units"
+)
+
+// quantity returns the metric measurement converted by fromMetric, falling
+// back to the english measurement converted by fromEnglish. ok is false if
+// neither is valid.
+func quantity[Q any](metric FlexFloat, fromMetric func(float64) Q, english FlexFloat, fromEnglish func(float64) Q) (q Q, ok bool) {
+	if metric.Valid {
+		return fromMetric(metric.Value), true
+	}
+
+	if english.Valid {
+		return fromEnglish(english.Value), true
+	}
+	return q, false
+}
+
+// Format the quantity in the preferred unit system of the client.
+func (w *Wug) Format(q units.Quantity) string {
+	return q.Format(w.Units)
+}
+
+// Temperature returns the observed temperature.
+func (c *Conditions) Temperature() (units.Temperature, bool) {
+	obs := &c.CurrentObservation
+	return quantity(obs.TempC, units.Celsius, obs.TempF, units.Fahrenheit)
+}
+
+// DewpointTemp returns the observed dew point.
+func (c *Conditions) DewpointTemp() (units.Temperature, bool) {
+	obs := &c.CurrentObservation
+	return quantity(obs.DewpointC, units.Celsius, obs.DewpointF, units.Fahrenheit)
+}
+
+// FeelsLikeTemp returns the reported feels like temperature.
+func (c *Conditions) FeelsLikeTemp() (units.Temperature, bool) {
+	obs := &c.CurrentObservation
+	return quantity(obs.FeelslikeC, units.Celsius, obs.FeelslikeF, units.Fahrenheit)
+}
+
+// HeatIndexTemp returns the reported heat index.
+func (c *Conditions) HeatIndexTemp() (units.Temperature, bool) {
+	obs := &c.CurrentObservation
+	return quantity(obs.HeatIndexC, units.Celsius, obs.HeatIndexF, units.Fahrenheit)
+}
+
+// WindchillTemp returns the reported wind chill.
+func (c *Conditions) WindchillTemp() (units.Temperature, bool) {
+	obs := &c.CurrentObservation
+	return quantity(obs.WindchillC, units.Celsius, obs.WindchillF, units.Fahrenheit)
+}
+
+// WindSpeed returns the observed wind speed.
+func (c *Conditions) WindSpeed() (units.Speed, bool) {
+	obs := &c.CurrentObservation
+	return quantity(obs.WindKph, units.KilometersPerHour, obs.WindMph, units.MilesPerHour)
+}
+
+// WindGustSpeed returns the observed wind gust speed.
+func (c *Conditions) WindGustSpeed() (units.Speed, bool) {
+	obs := &c.CurrentObservation
+	return quantity(obs.WindGustKph, units.KilometersPerHour, obs.WindGustMph, units.MilesPerHour)
+}
+
+// Pressure returns the observed pressure.
+func (c *Conditions) Pressure() (units.Pressure, bool) {
+	obs := &c.CurrentObservation
+	return quantity(obs.PressureMb, units.Millibars, obs.PressureIn, units.InchesOfMercury)
+}
+
+// Visibility returns the observed visibility.
+func (c *Conditions) Visibility() (units.Length, bool) {
+	obs := &c.CurrentObservation
+	return quantity(obs.VisibilityKm, units.Kilometers, obs.VisibilityMi, units.Miles)
+}
+
+// Precip1Hr returns the precipitation of the last hour.
+func (c *Conditions) Precip1Hr() (units.Precipitation, bool) {
+	obs := &c.CurrentObservation
+	return quantity(obs.Precip1HrMetric, units.Millimeters, obs.Precip1HrIn, units.Inches)
+}
+
+// PrecipToday returns the precipitation of today.
+func (c *Conditions) PrecipToday() (units.Precipitation, bool) {
+	obs := &c.CurrentObservation
+	return quantity(obs.PrecipTodayMetric, units.Millimeters, obs.PrecipTodayIn, units.Inches)
+}
+
+// Elevation returns the elevation of the display location, which is reported
+// in meters.
+func (c *Conditions) Elevation() (units.Length, bool) {
+	elevation := c.CurrentObservation.DisplayLocation.Elevation
+	return units.Meters(elevation.Value), elevation.Valid
+}
+
+// HighTemp returns the forecast high temperature.
+func (f *ForecastDay) HighTemp() (units.Temperature, bool) {
+	return quantity(f.High.Celsius, units.Celsius, f.High.Fahrenheit, units.Fahrenheit)
+}
+
+// LowTemp returns the forecast low temperature.
+func (f *ForecastDay) LowTemp() (units.Temperature, bool) {
+	return quantity(f.Low.Celsius, units.Celsius, f.Low.Fahrenheit, units.Fahrenheit)
+}
+
+// MaxWindSpeed returns the forecast maximum wind speed.
+func (f *ForecastDay) MaxWindSpeed() (units.Speed, bool) {
+	return quantity(f.Maxwind.Kph, units.KilometersPerHour, f.Maxwind.Mph, units.MilesPerHour)
+}
+
+// AveWindSpeed returns the forecast average wind speed.
+func (f *ForecastDay) AveWindSpeed() (units.Speed, bool) {
+	return quantity(f.Avewind.Kph, units.KilometersPerHour, f.Avewind.Mph, units.MilesPerHour)
+}
+
+// PrecipAllDay returns the forecast precipitation of the whole day.
+func (f *ForecastDay) PrecipAllDay() (units.Precipitation, bool) {
+	return quantity(f.QpfAllday.Mm, units.Millimeters, f.QpfAllday.In, units.Inches)
+}
+
+// PrecipDay returns the forecast precipitation of the day time.
+func (f *ForecastDay) PrecipDay() (units.Precipitation, bool) {
+	return quantity(f.QpfDay.Mm, units.Millimeters, f.QpfDay.In, units.Inches)
+}
+
+// PrecipNight returns the forecast precipitation of the night time.
+func (f *ForecastDay) PrecipNight() (units.Precipitation, bool) {
+	return quantity(f.QpfNight.Mm, units.Millimeters, f.QpfNight.In, units.Inches)
+}
+
+// SnowAllDay returns the forecast snowfall of the whole day.
+func (f *ForecastDay) SnowAllDay() (units.Precipitation, bool) {
+	return quantity(f.SnowAllday.Cm, units.Centimeters, f.SnowAllday.In, units.Inches)
+}
+
+// SnowDayTime returns the forecast snowfall of the day time.
+func (f *ForecastDay) SnowDayTime() (units.Precipitation, bool) {
+	return quantity(f.SnowDay.Cm, units.Centimeters, f.SnowDay.In, units.Inches)
+}
+
+// SnowNightTime returns the forecast snowfall of the night time.
+func (f *ForecastDay) SnowNightTime() (units.Precipitation, bool) {
+	return quantity(f.SnowNight.Cm, units.Centimeters, f.SnowNight.In, units.Inches)
+}
+
+// Temperature returns the forecast temperature.
+func (h *HourlyForecast) Temperature() (units.Temperature, bool) {
+	return quantity(h.Temp.Metric, units.Celsius, h.Temp.English, units.Fahrenheit)
+}
+
+// DewpointTemp returns the forecast dew point.
+func (h *HourlyForecast) DewpointTemp() (units.Temperature, bool) {
+	return quantity(h.Dewpoint.Metric, units.Celsius, h.Dewpoint.English, units.Fahrenheit)
+}
+
+// FeelsLikeTemp returns the forecast feels like temperature.
+func (h *HourlyForecast) FeelsLikeTemp() (units.Temperature, bool) {
+	return quantity(h.Feelslike.Metric, units.Celsius, h.Feelslike.English, units.Fahrenheit)
+}
+
+// HeatIndexTemp returns the forecast heat index.
+func (h *HourlyForecast) HeatIndexTemp() (units.Temperature, bool) {
+	return quantity(h.Heatindex.Metric, units.Celsius, h.Heatindex.English, units.Fahrenheit)
+}
+
+// WindchillTemp returns the forecast wind chill.
+func (h *HourlyForecast) WindchillTemp() (units.Temperature, bool) {
+	return quantity(h.Windchill.Metric, units.Celsius, h.Windchill.English, units.Fahrenheit)
+}
+
+// WindSpeed returns the forecast wind speed.
+func (h *HourlyForecast) WindSpeed() (units.Speed, bool) {
+	return quantity(h.Wspd.Metric, units.KilometersPerHour, h.Wspd.English, units.MilesPerHour)
+}
+
+// Pressure returns the forecast mean sea level pressure.
+func (h *HourlyForecast) Pressure() (units.Pressure, bool) {
+	return quantity(h.Mslp.Metric, units.Hectopascals, h.Mslp.English, units.InchesOfMercury)
+}
+
+// Precipitation returns the forecast precipitation of the hour.
+func (h *HourlyForecast) Precipitation() (units.Precipitation, bool) {
+	return quantity(h.Qpf.Metric, units.Millimeters, h.Qpf.English, units.Inches)
+}
+
+// Snowfall returns the forecast snowfall of the hour.
+func (h *HourlyForecast) Snowfall() (units.Precipitation, bool) {
+	return quantity(h.Snow.Metric, units.Millimeters, h.Snow.English, units.Inches)
+}
@@ -0,0 +1,100 @@
+package wug
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/wirepair/wug/units"
+)
+
+func TestConditionsQuantities(t *testing.T) {
+	data := []byte(`{"current_observation": {"display_location": {"elevation": "14.00000000"}, "temp_f": 68.0, "temp_c": 20.0, "wind_mph": 22.4, "wind_kph": "NA", "pressure_mb": "1013", "pressure_in": "29.92", "visibility_mi": "10.0", "visibility_km": "", "precip_today_in": "0.50", "precip_today_metric": "13", "windchill_f": "NA", "windchill_c": "NA"}}`)
+	c := &Conditions{}
+	if err := json.Unmarshal(data, c); err != nil {
+		t.Fatalf("error decoding conditions: %s\n", err)
+	}
+
+	if temp, ok := c.Temperature(); !ok || temp.Celsius() != 20 {
+		t.Fatalf("unexpected temperature %v %v\n", temp, ok)
+	}
+
+	if speed, ok := c.WindSpeed(); !ok || math.Abs(speed.MilesPerHour()-22.4) > 1e-9 {
+		t.Fatalf("expected wind speed from mph got %v %v\n", speed, ok)
+	}
+
+	if pressure, ok := c.Pressure(); !ok || pressure.Hectopascals() != 1013 {
+		t.Fatalf("expected metric pressure got %v %v\n", pressure, ok)
+	}
+
+	if visibility, ok := c.Visibility(); !ok || math.Abs(visibility.Miles()-10) > 1e-9 {
+		t.Fatalf("unexpected visibility %v %v\n", visibility, ok)
+	}
+
+	if precip, ok := c.PrecipToday(); !ok || precip.Millimeters() != 13 {
+		t.Fatalf("unexpected precip %v %v\n", precip, ok)
+	}
+
+	if elevation, ok := c.Elevation(); !ok || elevation.Meters() != 14 {
+		t.Fatalf("unexpected elevation %v %v\n", elevation, ok)
+	}
+
+	if _, ok := c.WindchillTemp(); ok {
+		t.Fatalf("expected no wind chill")
+	}
+
+	w := NewWug()
+	temp, _ := c.Temperature()
+	if got := w.Format(temp); got != "68.0°F" {
+		t.Fatalf("expected imperial format got %s\n", got)
+	}
+
+	w.Units = units.Metric
+	if got := w.Format(temp); got != "20.0°C" {
+		t.Fatalf("expected metric format got %s\n", got)
+	}
+}
+
+func TestForecastAndHourlyQuantities(t *testing.T) {
+	day := &ForecastDay{}
+	if err := json.Unmarshal([]byte(`{"high": {"fahrenheit": "68", "celsius": "20"}, "low": {"fahrenheit": "50", "celsius": ""}, "qpf_allday": {"in": 0.5, "mm": 13}, "snow_allday": {"in": 1.0, "cm": 2.5}, "maxwind": {"mph": 10, "kph": 16}}`), day); err != nil {
+		t.Fatalf("error decoding forecast day: %s\n", err)
+	}
+
+	if high, ok := day.HighTemp(); !ok || high.Celsius() != 20 {
+		t.Fatalf("unexpected high %v %v\n", high, ok)
+	}
+
+	if low, ok := day.LowTemp(); !ok || low.Fahrenheit() != 50 {
+		t.Fatalf("unexpected low %v %v\n", low, ok)
+	}
+
+	if snow, ok := day.SnowAllDay(); !ok || snow.Millimeters() != 25 {
+		t.Fatalf("unexpected snow %v %v\n", snow, ok)
+	}
+
+	if wind, ok := day.MaxWindSpeed(); !ok || math.Abs(wind.KilometersPerHour()-16) > 1e-9 {
+		t.Fatalf("unexpected wind %v %v\n", wind, ok)
+	}
+
+	if _, ok := day.PrecipNight(); ok {
+		t.Fatalf("expected no night precipitation")
+	}
+
+	hour := &HourlyForecast{}
+	if err := json.Unmarshal([]byte(`{"temp": {"english": "66", "metric": "19"}, "mslp": {"english": "30.01", "metric": "1016"}, "wspd": {"english": "5", "metric": "8"}}`), hour); err != nil {
+		t.Fatalf("error decoding hourly forecast: %s\n", err)
+	}
+
+	if temp, ok := hour.Temperature(); !ok || temp.Celsius() != 19 {
+		t.Fatalf("unexpected temperature %v %v\n", temp, ok)
+	}
+
+	if pressure, ok := hour.Pressure(); !ok || pressure.Hectopascals() != 1016 {
+		t.Fatalf("unexpected pressure %v %v\n", pressure, ok)
+	}
+
+	if speed, ok := hour.WindSpeed(); !ok || math.Abs(speed.KilometersPerHour()-8) > 1e-9 {
+		t.Fatalf("unexpected speed %v %v\n", speed, ok)
+	}
+}
@@ -2,7 +2,10 @@ package wug
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 // QueryType of query to build
@@ -28,45 +31,69 @@ var queryFormats = map[QueryType]string{
 	LatLong:     "/%s,%s.json",
 	AirportCode: "/%s.json",
 	AutoIP:      "/autoip.json",
-	IPGeo:       "/autoip.json?geo_ip=%s",
+	IPGeo:       "/autoip.json",
 }
 
 // Query used for the Wug client
 type Query struct {
-	apiKey     string
-	queryType  QueryType
-	queryValue string
+	apiKey    string
+	queryType QueryType
+	queryPath string     // escaped path following /q, e.g. /CA/San_Francisco.json
+	queryArgs url.Values // url query arguments, e.g. geo_ip
+	settings  Settings   // per query overrides of the client settings
+}
+
+// escapeComponent normalizes a user supplied query component to NFC and
+// escapes it so it is safe to use as (part of) a single path segment. Dot
+// segments are escaped as well so they can not walk out of the /q/ path.
+func escapeComponent(component string) string {
+	escaped := url.PathEscape(norm.NFC.String(component))
+	if escaped == "." || escaped == ".." {
+		escaped = strings.Replace(escaped, ".", "%2E", -1)
+	}
+	return escaped
+}
+
+// formatPath escapes each component and places them in the format string of
+// the query type.
+func formatPath(queryType QueryType, components ...string) string {
+	args := make([]interface{}, len(components))
+	for i, component := range components {
+		args[i] = escapeComponent(component)
+	}
+	return fmt.Sprintf(queryFormats[queryType], args...)
 }
 
 // NewQueryByPwsID query by pws id, pwsID does not need the leading pws: string
 func NewQueryByPwsID(apiKey string, pwsID string) *Query {
 	return &Query{
-		apiKey:     apiKey,
-		queryType:  PwsID,
-		queryValue: fmt.Sprintf(queryFormats[PwsID], pwsID),
+		apiKey:    apiKey,
+		queryType: PwsID,
+		queryPath: formatPath(PwsID, pwsID),
 	}
 }
 
 // NewQueryByUsStateCity query by US state and city, replaces spaces with _ and
-// upper cases the state.
+// upper cases the state. Like all query builders the components are NFC
+// normalized and path escaped.
 func NewQueryByUsStateCity(apiKey string, state, city string) *Query {
 	state = strings.Replace(state, " ", "_", -1)
 	state = strings.ToUpper(state)
 	city = strings.Replace(city, " ", "_", -1)
 
 	return &Query{
-		apiKey:     apiKey,
-		queryType:  UsStateCity,
-		queryValue: fmt.Sprintf(queryFormats[UsStateCity], state, city),
+		apiKey:    apiKey,
+		queryType: UsStateCity,
+		queryPath: formatPath(UsStateCity, state, city),
 	}
 }
 
 // NewQueryByUsZip query by US zip code
 func NewQueryByUsZip(apiKey string, zip string) *Query {
 	return &Query{
-		apiKey:     apiKey,
-		queryType:  UsZip,
-		queryValue: fmt.Sprintf(queryFormats[UsZip], zip),
+		apiKey:    apiKey,
+		queryType: UsZip,
+		queryPath: formatPath(UsZip, zip),
 	}
 }
 
@@ -76,27 +103,27 @@ func NewQueryByCountryCity(apiKey string, country, city string) *Query {
 	city = strings.Replace(city, " ", "_", -1)
 
 	return &Query{
-		apiKey:     apiKey,
-		queryType:  CountryCity,
-		queryValue: fmt.Sprintf(queryFormats[CountryCity], country, city),
+		apiKey:    apiKey,
+		queryType: CountryCity,
+		queryPath: formatPath(CountryCity, country, city),
 	}
 }
 
 // NewQueryByLatLong query by latitude and longitude.
 func NewQueryByLatLong(apiKey string, latitude, longitude string) *Query {
 	return &Query{
-		apiKey:     apiKey,
-		queryType:  LatLong,
-		queryValue: fmt.Sprintf(queryFormats[LatLong], latitude, longitude),
+		apiKey:    apiKey,
+		queryType: LatLong,
+		queryPath: formatPath(LatLong, latitude, longitude),
 	}
 }
 
 // NewQueryByAirportCode query by airport code
 func NewQueryByAirportCode(apiKey string, airport string) *Query {
 	return &Query{
-		apiKey:     apiKey,
-		queryType:  AirportCode,
-		queryValue: fmt.Sprintf(queryFormats[AirportCode], airport),
+		apiKey:    apiKey,
+		queryType: AirportCode,
+		queryPath: formatPath(AirportCode, airport),
 	}
 }
 
@@ -104,9 +131,9 @@ func NewQueryByAirportCode(apiKey string, airport string) *Query {
 // the closest station.
 func NewQueryByAutoIP(apiKey string) *Query {
 	return &Query{
-		apiKey:     apiKey,
-		queryType:  AutoIP,
-		queryValue: queryFormats[AutoIP],
+		apiKey:    apiKey,
+		queryType: AutoIP,
+		queryPath: queryFormats[AutoIP],
 	}
 }
 
@@ -114,13 +141,80 @@ func NewQueryByAutoIP(apiKey string) *Query {
 // the closest station.
 func NewQueryByIPGeo(apiKey string, ipAddress string) *Query {
 	return &Query{
-		apiKey:     apiKey,
-		queryType:  IPGeo,
-		queryValue: fmt.Sprintf(queryFormats[IPGeo], ipAddress),
+		apiKey:    apiKey,
+		queryType: IPGeo,
+		queryPath: queryFormats[IPGeo],
+		queryArgs: url.Values{"geo_ip": {ipAddress}},
+	}
+}
+
+// ParseQuery returns the query of queryType for a location as users write it,
+// e.g. KCASANFR70 or pws:KCASANFR70, 37.77,-122.42, or CA/San Francisco and
+// France/Paris for the city types, which are told apart by the region: two
+// upper case letters are a US state. The value is ignored for AutoIP.
+func ParseQuery(apiKey string, queryType QueryType, value string) (*Query, error) {
+	switch queryType {
+	case PwsID:
+		return NewQueryByPwsID(apiKey, strings.TrimPrefix(value, "pws:")), nil
+	case UsZip:
+		return NewQueryByUsZip(apiKey, value), nil
+	case AirportCode:
+		return NewQueryByAirportCode(apiKey, value), nil
+	case AutoIP:
+		return NewQueryByAutoIP(apiKey), nil
+	case IPGeo:
+		return NewQueryByIPGeo(apiKey, value), nil
+	case LatLong:
+		lat, long, ok := strings.Cut(value, ",")
+		if !ok {
+			return nil, fmt.Errorf("wug: %q is not latitude,longitude", value)
+		}
+		return NewQueryByLatLong(apiKey, strings.TrimSpace(lat), strings.TrimSpace(long)), nil
+	case UsStateCity, CountryCity:
+		region, city, ok := strings.Cut(value, "/")
+		if !ok {
+			return nil, fmt.Errorf("wug: %q is not STATE/City or Country/City", value)
+		}
+
+		if len(region) == 2 && strings.ToUpper(region) == region {
+			return NewQueryByUsStateCity(apiKey, region, city), nil
+		}
+		return NewQueryByCountryCity(apiKey, region, city), nil
+	}
+	return nil, fmt.Errorf("wug: unknown query type %d", queryType)
+}
+
+// WithSettings returns a copy of the query whose settings override the client
+// wide Wug.Settings for any non default values.
+func (q *Query) WithSettings(settings Settings) *Query {
+	query := *q
+	query.settings = settings
+	return &query
+}
+
+// value returns the escaped path and query arguments of the query.
+func (q *Query) value() string {
+	if len(q.queryArgs) == 0 {
+		return q.queryPath
+	}
+	return q.queryPath + "?" + q.queryArgs.Encode()
+}
+
+// String returns the location of the query without the path syntax, e.g.
+// pws:KCASANFR70, CA/San_Francisco or autoip?geo_ip=203.0.113.7.
+func (q *Query) String() string {
+	location := strings.TrimSuffix(strings.TrimPrefix(q.queryPath, "/"), ".json")
+	if unescaped, err := url.PathUnescape(location); err == nil {
+		location = unescaped
+	}
+
+	if len(q.queryArgs) == 0 {
+		return location
 	}
+	return location + "?" + q.queryArgs.Encode()
 }
 
 // Format the requestURL for the query with the query value.
 func (q *Query) Format(requestURL string) string {
-	return fmt.Sprintf(requestURL, q.queryValue)
+	return fmt.Sprintf(requestURL, q.value())
 }
@@ -1,6 +1,7 @@
 package wug
 
 import (
+	"net/url"
 	"testing"
 )
 
@@ -45,3 +46,119 @@ func TestQueryTypes(t *testing.T) {
 		t.Fatalf("expected UsZip")
 	}
 }
+
+func TestQueryEscaping(t *testing.T) {
+	var tests = []struct {
+		name  string
+		query *Query
+		want  string
+	}{
+		{"state city", NewQueryByUsStateCity("apikey", "ca", "san francisco"), "/api/apikey/conditions/q/CA/san_francisco.json"},
+		{"apostrophe", NewQueryByUsStateCity("apikey", "ID", "Coeur d'Alene"), "/api/apikey/conditions/q/ID/Coeur_d%27Alene.json"},
+		{"composed accent", NewQueryByCountryCity("apikey", "France", "Saint-Étienne"), "/api/apikey/conditions/q/France/Saint-%C3%89tienne.json"},
+		{"decomposed accent", NewQueryByCountryCity("apikey", "France", "Saint-E\u0301tienne"), "/api/apikey/conditions/q/France/Saint-%C3%89tienne.json"},
+		{"cyrillic", NewQueryByCountryCity("apikey", "Russia", "Москва"), "/api/apikey/conditions/q/Russia/%D0%9C%D0%BE%D1%81%D0%BA%D0%B2%D0%B0.json"},
+		{"slash", NewQueryByCountryCity("apikey", "Bosnia", "Sarajevo/Ilidža"), "/api/apikey/conditions/q/Bosnia/Sarajevo%2FIlid%C5%BEa.json"},
+		{"question mark", NewQueryByCountryCity("apikey", "UK", "Westward Ho?"), "/api/apikey/conditions/q/UK/Westward_Ho%3F.json"},
+		{"hash", NewQueryByUsStateCity("apikey", "TX", "Station #1"), "/api/apikey/conditions/q/TX/Station_%231.json"},
+		{"percent", NewQueryByUsZip("apikey", "100%"), "/api/apikey/conditions/q/100%25.json"},
+		{"dot segment", NewQueryByCountryCity("apikey", "..", "."), "/api/apikey/conditions/q/%2E%2E/%2E.json"},
+		{"lat long", NewQueryByLatLong("apikey", "37.8", "-122.4"), "/api/apikey/conditions/q/37.8,-122.4.json"},
+		{"pws", NewQueryByPwsID("apikey", "KCASANFR70"), "/api/apikey/conditions/q/pws:KCASANFR70.json"},
+		{"airport", NewQueryByAirportCode("apikey", "NRT"), "/api/apikey/conditions/q/NRT.json"},
+		{"autoip", NewQueryByAutoIP("apikey"), "/api/apikey/conditions/q/autoip.json"},
+		{"ip geo", NewQueryByIPGeo("apikey", "127.0.0.1&x=y"), "/api/apikey/conditions/q/autoip.json?geo_ip=127.0.0.1%26x%3Dy"},
+		{"api key", NewQueryByAutoIP("api/key"), "/api/api%2Fkey/conditions/q/autoip.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := requestURL(Cond, Settings{}, tt.query)
+			if err != nil {
+				t.Fatalf("error building url: %s\n", err)
+			}
+
+			got := u.RequestURI()
+			if got != tt.want {
+				t.Fatalf("expected %s got %s\n", tt.want, got)
+			}
+
+			parsed, err := url.Parse(u.String())
+			if err != nil {
+				t.Fatalf("error parsing built url: %s\n", err)
+			}
+
+			if parsed.Host != apiHost || parsed.Path != u.Path || parsed.RawQuery != u.RawQuery {
+				t.Fatalf("built url %s did not round trip\n", u)
+			}
+		})
+	}
+}
+
+func TestQueryString(t *testing.T) {
+	var tests = []struct {
+		query *Query
+		want  string
+	}{
+		{NewQueryByUsStateCity("apikey", "CA", "San Francisco"), "CA/San_Francisco"},
+		{NewQueryByCountryCity("apikey", "France", "Saint-Étienne"), "France/Saint-Étienne"},
+		{NewQueryByLatLong("apikey", "37.8", "-122.4"), "37.8,-122.4"},
+		{NewQueryByPwsID("apikey", "KCASANFR70"), "pws:KCASANFR70"},
+		{NewQueryByUsZip("apikey", "94101"), "94101"},
+		{NewQueryByAutoIP("apikey"), "autoip"},
+		{NewQueryByIPGeo("apikey", "203.0.113.7"), "autoip?geo_ip=203.0.113.7"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.query.String(); got != tt.want {
+			t.Fatalf("expected %s got %s\n", tt.want, got)
+		}
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	var tests = []struct {
+		queryType QueryType
+		value     string
+		want      string
+	}{
+		{PwsID, "pws:KCASANFR70", "pws:KCASANFR70"},
+		{PwsID, "KCASANFR70", "pws:KCASANFR70"},
+		{UsZip, "94101", "94101"},
+		{AirportCode, "KSFO", "KSFO"},
+		{AutoIP, "", "autoip"},
+		{IPGeo, "203.0.113.7", "autoip?geo_ip=203.0.113.7"},
+		{LatLong, "37.77, -122.42", "37.77,-122.42"},
+		{UsStateCity, "CA/San Francisco", "CA/San_Francisco"},
+		{UsStateCity, "France/Paris", "France/Paris"},
+		{CountryCity, "ca/Montreal", "ca/Montreal"},
+	}
+
+	for _, tt := range tests {
+		q, err := ParseQuery("apikey", tt.queryType, tt.value)
+		if err != nil {
+			t.Fatalf("%s: error parsing: %s\n", tt.value, err)
+		}
+
+		if q.String() != tt.want {
+			t.Fatalf("%s: expected %s got %s\n", tt.value, tt.want, q.String())
+		}
+	}
+
+	if q, _ := ParseQuery("apikey", UsStateCity, "CA/San Francisco"); q.queryType != UsStateCity {
+		t.Fatalf("expected a US state city query")
+	}
+
+	if q, _ := ParseQuery("apikey", UsStateCity, "France/Paris"); q.queryType != CountryCity {
+		t.Fatalf("expected a country city query")
+	}
+
+	for _, tt := range []struct {
+		queryType QueryType
+		value     string
+	}{{LatLong, "37.77"}, {CountryCity, "San Francisco"}, {QueryType(99), "x"}} {
+		if _, err := ParseQuery("apikey", tt.queryType, tt.value); err == nil {
+			t.Fatalf("%s: expected an error\n", tt.value)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package wug
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/wirepair/wug/units"
+)
+
+// Field of an hourly forecast a Series is built from
+type Field int
+
+// Field constants, values are in metric units
+const (
+	FieldTemperature   Field = iota // °C
+	FieldDewpoint                   // °C
+	FieldFeelsLike                  // °C
+	FieldHumidity                   // percent
+	FieldWindSpeed                  // m/s
+	FieldWindDirection              // degrees
+	FieldPressure                   // hPa
+	FieldCloudCover                 // percent
+	FieldUVIndex                    // index
+	FieldPop                        // percent
+	FieldPrecipitation              // mm
+	FieldSnow                       // mm
+)
+
+// value reads a field from an hourly forecast
+type value func(h *HourlyForecast) (float64, bool)
+
+// quantityValue returns a function reading a quantity as a float64.
+func quantityValue[Q any](get func(h *HourlyForecast) (Q, bool), convert func(Q) float64) value {
+	return func(h *HourlyForecast) (float64, bool) {
+		q, ok := get(h)
+		return convert(q), ok
+	}
+}
+
+// flexValue returns a function reading a FlexFloat as a float64.
+func flexValue(get func(h *HourlyForecast) FlexFloat) value {
+	return func(h *HourlyForecast) (float64, bool) {
+		f := get(h)
+		return f.Value, f.Valid
+	}
+}
+
+var fieldMap = map[Field]value{
+	FieldTemperature:   quantityValue((*HourlyForecast).Temperature, units.Temperature.Celsius),
+	FieldDewpoint:      quantityValue((*HourlyForecast).DewpointTemp, units.Temperature.Celsius),
+	FieldFeelsLike:     quantityValue((*HourlyForecast).FeelsLikeTemp, units.Temperature.Celsius),
+	FieldHumidity:      flexValue(func(h *HourlyForecast) FlexFloat { return h.Humidity }),
+	FieldWindSpeed:     quantityValue((*HourlyForecast).WindSpeed, units.Speed.MetersPerSecond),
+	FieldWindDirection: flexValue(func(h *HourlyForecast) FlexFloat { return h.Wdir.Degrees }),
+	FieldPressure:      quantityValue((*HourlyForecast).Pressure, units.Pressure.Hectopascals),
+	FieldCloudCover:    flexValue(func(h *HourlyForecast) FlexFloat { return h.Sky }),
+	FieldUVIndex:       flexValue(func(h *HourlyForecast) FlexFloat { return h.Uvi }),
+	FieldPop:           flexValue(func(h *HourlyForecast) FlexFloat { return h.Pop }),
+	FieldPrecipitation: quantityValue((*HourlyForecast).Precipitation, units.Precipitation.Millimeters),
+	FieldSnow:          quantityValue((*HourlyForecast).Snowfall, units.Precipitation.Millimeters),
+}
+
+// Aggregate selects how values are combined
+type Aggregate int
+
+// Aggregate constants
+const (
+	AggregateMin Aggregate = iota
+	AggregateMax
+	AggregateMean
+	AggregateSum
+)
+
+// Point is a single value of a Series
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// Series is a time ordered series of values of one field
+type Series struct {
+	Points   []Point
+	Circular bool // values are degrees and wrap at 360, e.g. wind direction
+}
+
+// series builds the series of field from the forecasts, skipping hours
+// without a time or value.
+func series(forecasts []HourlyForecast, field Field) Series {
+	get, ok := fieldMap[field]
+	if !ok {
+		return Series{}
+	}
+
+	s := Series{Circular: field == FieldWindDirection}
+	for i := range forecasts {
+		t, err := forecasts[i].Fcttime.Time()
+		if err != nil {
+			continue
+		}
+
+		if v, ok := get(&forecasts[i]); ok {
+			s.Points = append(s.Points, Point{Time: t, Value: v})
+		}
+	}
+
+	sort.SliceStable(s.Points, func(i, j int) bool {
+		return s.Points[i].Time.Before(s.Points[j].Time)
+	})
+	return s
+}
+
+// Series returns the time series of field from the hourly forecast.
+func (h *Hourly) Series(field Field) Series {
+	return series(h.Hourly, field)
+}
+
+// Series returns the time series of field from the ten day hourly forecast.
+func (h *HourlyTenDay) Series(field Field) Series {
+	return series(h.Hourly, field)
+}
+
+// Len returns the number of points.
+func (s Series) Len() int {
+	return len(s.Points)
+}
+
+// interpolate between a and b by fraction f.
+func (s Series) interpolate(a, b, f float64) float64 {
+	if !s.Circular {
+		return a + (b-a)*f
+	}
+
+	// take the shorter way around the circle
+	diff := math.Mod(math.Mod(b-a, 360)+540, 360) - 180
+	return math.Mod(math.Mod(a+diff*f, 360)+360, 360)
+}
+
+// At returns the value at t, linearly interpolated between the surrounding
+// points. ok is false if t is outside of the series.
+func (s Series) At(t time.Time) (v float64, ok bool) {
+	i := sort.Search(len(s.Points), func(i int) bool {
+		return !s.Points[i].Time.Before(t)
+	})
+
+	if i == len(s.Points) {
+		return 0, false
+	}
+
+	next := s.Points[i]
+	if next.Time.Equal(t) {
+		return next.Value, true
+	}
+
+	if i == 0 {
+		return 0, false
+	}
+
+	prev := s.Points[i-1]
+	f := float64(t.Sub(prev.Time)) / float64(next.Time.Sub(prev.Time))
+	return s.interpolate(prev.Value, next.Value, f), true
+}
+
+// Resample returns the series interpolated every interval from start until
+// end, inclusive. Times outside of the series are left out.
+func (s Series) Resample(start, end time.Time, interval time.Duration) Series {
+	resampled := Series{Circular: s.Circular}
+	if interval <= 0 {
+		return resampled
+	}
+
+	for t := start; !t.After(end); t = t.Add(interval) {
+		if v, ok := s.At(t); ok {
+			resampled.Points = append(resampled.Points, Point{Time: t, Value: v})
+		}
+	}
+	return resampled
+}
+
+// Window returns the points from start until before end.
+func (s Series) Window(start, end time.Time) Series {
+	from := sort.Search(len(s.Points), func(i int) bool {
+		return !s.Points[i].Time.Before(start)
+	})
+
+	to := sort.Search(len(s.Points), func(i int) bool {
+		return !s.Points[i].Time.Before(end)
+	})
+
+	if to < from {
+		to = from
+	}
+	return Series{Points: s.Points[from:to], Circular: s.Circular}
+}
+
+// Next returns the points from t for the duration d.
+func (s Series) Next(t time.Time, d time.Duration) Series {
+	return s.Window(t, t.Add(d))
+}
+
+// Aggregate combines the values of the series, ok is false if it is empty.
+// The mean of a circular series is the direction of the mean unit vector.
+func (s Series) Aggregate(aggregate Aggregate) (v float64, ok bool) {
+	if len(s.Points) == 0 {
+		return 0, false
+	}
+
+	switch aggregate {
+	case AggregateMin:
+		v = s.Points[0].Value
+		for _, p := range s.Points[1:] {
+			v = math.Min(v, p.Value)
+		}
+	case AggregateMax:
+		v = s.Points[0].Value
+		for _, p := range s.Points[1:] {
+			v = math.Max(v, p.Value)
+		}
+	case AggregateMean:
+		if s.Circular {
+			return s.circularMean(), true
+		}
+
+		for _, p := range s.Points {
+			v += p.Value
+		}
+		v /= float64(len(s.Points))
+	case AggregateSum:
+		for _, p := range s.Points {
+			v += p.Value
+		}
+	default:
+		return 0, false
+	}
+	return v, true
+}
+
+func (s Series) circularMean() float64 {
+	var x, y float64
+	for _, p := range s.Points {
+		rad := p.Value * math.Pi / 180
+		x += math.Cos(rad)
+		y += math.Sin(rad)
+	}
+
+	deg := math.Atan2(y, x) * 180 / math.Pi
+	return math.Mod(deg+360, 360)
+}
+
+// Daily aggregates the points of each day, in the local time of the points,
+// into a point at midnight. Sums are of the points as they are, resampling
+// a precipitation series before summing counts it more than once.
+func (s Series) Daily(aggregate Aggregate) Series {
+	daily := Series{Circular: s.Circular}
+	for start := 0; start < len(s.Points); {
+		t := s.Points[start].Time
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		next := day.AddDate(0, 0, 1)
+
+		end := start + 1
+		for end < len(s.Points) && s.Points[end].Time.Before(next) {
+			end++
+		}
+
+		if v, ok := (Series{Points: s.Points[start:end], Circular: s.Circular}).Aggregate(aggregate); ok {
+			daily.Points = append(daily.Points, Point{Time: day, Value: v})
+		}
+		start = end
+	}
+	return daily
+}
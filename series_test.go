@@ -0,0 +1,83 @@
+package wug
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSeries(t *testing.T) {
+	data := []byte(`{"hourly_forecast": [
+		{"FCTTIME": {"epoch": "1493175600"}, "temp": {"english": "", "metric": "10"}, "wdir": {"degrees": "350"}, "pop": "10", "qpf": {"english": "", "metric": "0"}},
+		{"FCTTIME": {"epoch": "1493172000"}, "temp": {"english": "", "metric": "8"}, "wdir": {"degrees": "340"}, "pop": "0", "qpf": {"english": "", "metric": "0"}},
+		{"FCTTIME": {"epoch": "1493179200"}, "temp": {"english": "", "metric": "14"}, "wdir": {"degrees": "10"}, "pop": "60", "qpf": {"english": "", "metric": "2"}},
+		{"FCTTIME": {"epoch": "1493182800"}, "temp": {"english": "", "metric": ""}, "wdir": {"degrees": "30"}, "pop": "40", "qpf": {"english": "", "metric": "1"}}]}`)
+	h := &Hourly{}
+	if err := json.Unmarshal(data, h); err != nil {
+		t.Fatalf("error decoding hourly: %s\n", err)
+	}
+
+	start := time.Unix(1493172000, 0)
+	temp := h.Series(FieldTemperature)
+	if temp.Len() != 3 || !temp.Points[0].Time.Equal(start) {
+		t.Fatalf("expected 3 sorted points got %#v\n", temp.Points)
+	}
+
+	var tests = []struct {
+		offset   time.Duration
+		expected float64
+		ok       bool
+	}{
+		{0, 8, true},
+		{30 * time.Minute, 9, true},
+		{90 * time.Minute, 12, true},
+		{2 * time.Hour, 14, true},
+		{-time.Minute, 0, false},
+		{2*time.Hour + time.Minute, 0, false},
+	}
+
+	for _, tt := range tests {
+		v, ok := temp.At(start.Add(tt.offset))
+		if ok != tt.ok || math.Abs(v-tt.expected) > 1e-9 {
+			t.Fatalf("at %s: expected %v %v got %v %v\n", tt.offset, tt.expected, tt.ok, v, ok)
+		}
+	}
+
+	resampled := temp.Resample(start, start.Add(3*time.Hour), 15*time.Minute)
+	if resampled.Len() != 9 || resampled.Points[1].Value != 8.5 {
+		t.Fatalf("unexpected resampled series %#v\n", resampled.Points)
+	}
+
+	wind := h.Series(FieldWindDirection)
+	if !wind.Circular {
+		t.Fatalf("expected wind direction to be circular\n")
+	}
+
+	// 350 to 10 passes through north
+	if v, _ := wind.At(start.Add(90 * time.Minute)); math.Abs(v) > 1e-9 && math.Abs(v-360) > 1e-9 {
+		t.Fatalf("expected north got %v\n", v)
+	}
+
+	if v, _ := wind.At(start.Add(30 * time.Minute)); math.Abs(v-345) > 1e-9 {
+		t.Fatalf("expected 345 got %v\n", v)
+	}
+
+	if mean, _ := wind.Window(start.Add(time.Hour), start.Add(3*time.Hour)).Aggregate(AggregateMean); math.Abs(mean) > 1e-9 && math.Abs(mean-360) > 1e-9 {
+		t.Fatalf("expected mean direction of north got %v\n", mean)
+	}
+
+	pop := h.Series(FieldPop)
+	if max, ok := pop.Next(start, 3*time.Hour).Aggregate(AggregateMax); !ok || max != 60 {
+		t.Fatalf("expected max pop of 60 got %v %v\n", max, ok)
+	}
+
+	if _, ok := pop.Next(start.Add(-6*time.Hour), time.Hour).Aggregate(AggregateMax); ok {
+		t.Fatalf("expected no value before the series\n")
+	}
+
+	daily := h.Series(FieldPrecipitation).Daily(AggregateSum)
+	if daily.Len() != 1 || daily.Points[0].Value != 3 || daily.Points[0].Time.Hour() != 0 {
+		t.Fatalf("unexpected daily precipitation %#v\n", daily.Points)
+	}
+}
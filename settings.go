@@ -0,0 +1,276 @@
+package wug
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Language of the text in responses (Fcttext, Condition etc.)
+type Language int
+
+// Language constants, LangDefault leaves the language up to the API (English)
+const (
+	LangDefault Language = iota // API default
+	LangAfrikaans
+	LangAlbanian
+	LangArabic
+	LangArmenian
+	LangAzerbaijani
+	LangBasque
+	LangBelarusian
+	LangBulgarian
+	LangBritishEnglish
+	LangBurmese
+	LangCatalan
+	LangChineseSimplified
+	LangChineseTraditional
+	LangCroatian
+	LangCzech
+	LangDanish
+	LangDhivehi
+	LangDutch
+	LangEnglish
+	LangEsperanto
+	LangEstonian
+	LangFarsi
+	LangFinnish
+	LangFrench
+	LangFrenchCanadian
+	LangGalician
+	LangGeorgian
+	LangGerman
+	LangGreek
+	LangGujarati
+	LangHaitianCreole
+	LangHebrew
+	LangHindi
+	LangHungarian
+	LangIcelandic
+	LangIdo
+	LangIndonesian
+	LangIrishGaelic
+	LangItalian
+	LangJapanese
+	LangJavanese
+	LangKhmer
+	LangKorean
+	LangKurdish
+	LangLatin
+	LangLatvian
+	LangLithuanian
+	LangLowGerman
+	LangMacedonian
+	LangMaltese
+	LangMandinka
+	LangMaori
+	LangMarathi
+	LangMongolian
+	LangNorwegian
+	LangOccitan
+	LangPashto
+	LangPlautdietsch
+	LangPolish
+	LangPortuguese
+	LangPunjabi
+	LangRomanian
+	LangRussian
+	LangSerbian
+	LangSlovak
+	LangSlovenian
+	LangSpanish
+	LangSwahili
+	LangSwedish
+	LangSwiss
+	LangTagalog
+	LangTatarish
+	LangThai
+	LangTurkish
+	LangTurkmen
+	LangUkrainian
+	LangUzbek
+	LangVietnamese
+	LangWelsh
+	LangWolof
+	LangYiddishTransliterated
+	LangYiddish
+)
+
+var languageMap = map[Language]string{
+	LangAfrikaans:             "AF",
+	LangAlbanian:              "AL",
+	LangArabic:                "AR",
+	LangArmenian:              "HY",
+	LangAzerbaijani:           "AZ",
+	LangBasque:                "EU",
+	LangBelarusian:            "BY",
+	LangBulgarian:             "BU",
+	LangBritishEnglish:        "LI",
+	LangBurmese:               "MY",
+	LangCatalan:               "CA",
+	LangChineseSimplified:     "CN",
+	LangChineseTraditional:    "TW",
+	LangCroatian:              "CR",
+	LangCzech:                 "CZ",
+	LangDanish:                "DK",
+	LangDhivehi:               "DV",
+	LangDutch:                 "NL",
+	LangEnglish:               "EN",
+	LangEsperanto:             "EO",
+	LangEstonian:              "ET",
+	LangFarsi:                 "FA",
+	LangFinnish:               "FI",
+	LangFrench:                "FR",
+	LangFrenchCanadian:        "FC",
+	LangGalician:              "GZ",
+	LangGeorgian:              "KA",
+	LangGerman:                "DL",
+	LangGreek:                 "GR",
+	LangGujarati:              "GU",
+	LangHaitianCreole:         "HT",
+	LangHebrew:                "IL",
+	LangHindi:                 "HI",
+	LangHungarian:             "HU",
+	LangIcelandic:             "IS",
+	LangIdo:                   "IO",
+	LangIndonesian:            "ID",
+	LangIrishGaelic:           "IR",
+	LangItalian:               "IT",
+	LangJapanese:              "JP",
+	LangJavanese:              "JW",
+	LangKhmer:                 "KM",
+	LangKorean:                "KR",
+	LangKurdish:               "KU",
+	LangLatin:                 "LA",
+	LangLatvian:               "LV",
+	LangLithuanian:            "LT",
+	LangLowGerman:             "ND",
+	LangMacedonian:            "MK",
+	LangMaltese:               "MT",
+	LangMandinka:              "GM",
+	LangMaori:                 "MI",
+	LangMarathi:               "MR",
+	LangMongolian:             "MN",
+	LangNorwegian:             "NO",
+	LangOccitan:               "OC",
+	LangPashto:                "PS",
+	LangPlautdietsch:          "GN",
+	LangPolish:                "PL",
+	LangPortuguese:            "BR",
+	LangPunjabi:               "PA",
+	LangRomanian:              "RO",
+	LangRussian:               "RU",
+	LangSerbian:               "SR",
+	LangSlovak:                "SK",
+	LangSlovenian:             "SL",
+	LangSpanish:               "SP",
+	LangSwahili:               "SI",
+	LangSwedish:               "SW",
+	LangSwiss:                 "CH",
+	LangTagalog:               "TL",
+	LangTatarish:              "TT",
+	LangThai:                  "TH",
+	LangTurkish:               "TR",
+	LangTurkmen:               "TK",
+	LangUkrainian:             "UA",
+	LangUzbek:                 "UZ",
+	LangVietnamese:            "VU",
+	LangWelsh:                 "CY",
+	LangWolof:                 "SN",
+	LangYiddishTransliterated: "JI",
+	LangYiddish:               "YI",
+}
+
+// Code returns the weather underground language code, or an empty string for
+// LangDefault and unknown languages.
+func (l Language) Code() string {
+	return languageMap[l]
+}
+
+// ParseLanguage returns the Language of a weather underground language code
+// such as FR, case insensitive.
+func ParseLanguage(code string) (Language, error) {
+	code = strings.ToUpper(code)
+	for language, languageCode := range languageMap {
+		if languageCode == code {
+			return language, nil
+		}
+	}
+	return LangDefault, fmt.Errorf("unknown language code %q", code)
+}
+
+// Toggle is an on/off setting which can also be left to the API default.
+type Toggle int
+
+// Toggle constants
+const (
+	ToggleDefault Toggle = iota // API default
+	ToggleOn                    // Setting enabled (1)
+	ToggleOff                   // Setting disabled (0)
+)
+
+// Settings are the optional request settings placed between the features and
+// the query of the request url, e.g. /lang:FR/pws:0/bestfct:1/q/...
+type Settings struct {
+	Language     Language // language of the text in the response
+	PWS          Toggle   // use personal weather stations for conditions
+	BestForecast Toggle   // use the weather underground best forecast
+}
+
+// Validate returns an error if any of the settings are out of range.
+func (s Settings) Validate() error {
+	if s.Language != LangDefault && s.Language.Code() == "" {
+		return fmt.Errorf("invalid language: %d", s.Language)
+	}
+
+	if s.PWS < ToggleDefault || s.PWS > ToggleOff {
+		return fmt.Errorf("invalid pws toggle: %d", s.PWS)
+	}
+
+	if s.BestForecast < ToggleDefault || s.BestForecast > ToggleOff {
+		return fmt.Errorf("invalid best forecast toggle: %d", s.BestForecast)
+	}
+	return nil
+}
+
+// Merge returns a copy of the settings with any non default values of
+// override taking precedence.
+func (s Settings) Merge(override Settings) Settings {
+	if override.Language != LangDefault {
+		s.Language = override.Language
+	}
+
+	if override.PWS != ToggleDefault {
+		s.PWS = override.PWS
+	}
+
+	if override.BestForecast != ToggleDefault {
+		s.BestForecast = override.BestForecast
+	}
+	return s
+}
+
+// segments returns the url path segments of the settings, leaving out any
+// that are left to the API default.
+func (s Settings) segments() []string {
+	var segments []string
+	if s.Language != LangDefault {
+		segments = append(segments, "lang:"+s.Language.Code())
+	}
+
+	if s.PWS != ToggleDefault {
+		segments = append(segments, "pws:"+s.PWS.value())
+	}
+
+	if s.BestForecast != ToggleDefault {
+		segments = append(segments, "bestfct:"+s.BestForecast.value())
+	}
+	return segments
+}
+
+// value returns the url value of an enabled or disabled toggle.
+func (t Toggle) value() string {
+	if t == ToggleOn {
+		return "1"
+	}
+	return "0"
+}
@@ -0,0 +1,60 @@
+package wug
+
+import (
+	"testing"
+)
+
+func TestSettingsURL(t *testing.T) {
+	var tests = []struct {
+		name     string
+		client   Settings
+		override Settings
+		want     string
+	}{
+		{"none", Settings{}, Settings{}, "/api/apikey/forecast/q/NRT.json"},
+		{"client language", Settings{Language: LangFrench}, Settings{}, "/api/apikey/forecast/lang:FR/q/NRT.json"},
+		{"override language", Settings{Language: LangFrench}, Settings{Language: LangJapanese}, "/api/apikey/forecast/lang:JP/q/NRT.json"},
+		{"merged", Settings{Language: LangGerman, PWS: ToggleOff}, Settings{BestForecast: ToggleOn}, "/api/apikey/forecast/lang:DL/pws:0/bestfct:1/q/NRT.json"},
+		{"override toggle", Settings{PWS: ToggleOff}, Settings{PWS: ToggleOn}, "/api/apikey/forecast/pws:1/q/NRT.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewQueryByAirportCode("apikey", "NRT").WithSettings(tt.override)
+			u, err := requestURL(Fore, tt.client.Merge(q.settings), q)
+			if err != nil {
+				t.Fatalf("error building url: %s\n", err)
+			}
+
+			if got := u.RequestURI(); got != tt.want {
+				t.Fatalf("expected %s got %s\n", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSettingsValidate(t *testing.T) {
+	invalid := []Settings{
+		{Language: Language(-1)},
+		{Language: LangYiddish + 1},
+		{PWS: Toggle(3)},
+		{BestForecast: Toggle(-1)},
+	}
+
+	for _, settings := range invalid {
+		if err := settings.Validate(); err == nil {
+			t.Fatalf("expected error validating %#v\n", settings)
+		}
+
+		q := NewQueryByAutoIP("apikey")
+		if _, err := requestURL(Cond, settings, q); err == nil {
+			t.Fatalf("expected error building url with %#v\n", settings)
+		}
+	}
+
+	for l := LangAfrikaans; l <= LangYiddish; l++ {
+		if l.Code() == "" {
+			t.Fatalf("language %d has no code\n", l)
+		}
+	}
+}
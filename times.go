@@ -0,0 +1,157 @@
+package wug
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoTime is returned when a response does not carry an epoch to convert.
+var ErrNoTime = errors.New("wug: response has no epoch")
+
+// locations caches the zones loaded by name, LoadLocation reads the zone
+// database on every call.
+var locations sync.Map
+
+// loadLocation loads the IANA zone, returning false if the name is empty or
+// the zone database does not have it.
+func loadLocation(name string) (*time.Location, bool) {
+	if name == "" {
+		return nil, false
+	}
+
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), true
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, false
+	}
+	locations.Store(name, loc)
+	return loc, true
+}
+
+// parseEpoch parses an epoch seconds string.
+func parseEpoch(epoch string) (time.Time, error) {
+	epoch = strings.TrimSpace(epoch)
+	if epoch == "" {
+		return time.Time{}, ErrNoTime
+	}
+
+	seconds, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(seconds, 0), nil
+}
+
+// wallClockZone returns a fixed zone with the offset between the local wall
+// clock of a response and its epoch, rounded to the quarter hour.
+func wallClockZone(name string, t time.Time, year, month, day, hour, min int) *time.Location {
+	wall := time.Date(year, time.Month(month), day, hour, min, t.Second(), 0, time.UTC)
+	offset := wall.Sub(t.UTC()).Round(15 * time.Minute)
+	return time.FixedZone(name, int(offset.Seconds()))
+}
+
+// parseOffset parses a numeric zone offset such as -0700 or +05:30.
+func parseOffset(offset string) (int, bool) {
+	offset = strings.Replace(strings.TrimSpace(offset), ":", "", 1)
+	if len(offset) != 5 || (offset[0] != '+' && offset[0] != '-') {
+		return 0, false
+	}
+
+	hours, err := strconv.Atoi(offset[1:3])
+	if err != nil {
+		return 0, false
+	}
+
+	minutes, err := strconv.Atoi(offset[3:])
+	if err != nil {
+		return 0, false
+	}
+
+	seconds := hours*3600 + minutes*60
+	if offset[0] == '-' {
+		seconds = -seconds
+	}
+	return seconds, true
+}
+
+// atoi converts the padded numeric strings of FCTTIME, returning 0 for
+// anything that is not a number.
+func atoi(s string) int {
+	n, _ := strconv.Atoi(strings.TrimSpace(s))
+	return n
+}
+
+// Time returns the forecast hour in the zone of the forecast. Tz is loaded as
+// an IANA zone when possible, otherwise the offset is derived from the local
+// date and time fields.
+func (f FCTTIME) Time() (time.Time, error) {
+	t, err := parseEpoch(f.Epoch)
+	if err != nil {
+		return t, err
+	}
+
+	if loc, ok := loadLocation(f.Tz); ok {
+		return t.In(loc), nil
+	}
+
+	if f.Year == "" || f.Mon == "" || f.Mday == "" {
+		return t.UTC(), nil
+	}
+	return t.In(wallClockZone(f.Tz, t, atoi(f.Year), atoi(f.Mon), atoi(f.Mday), atoi(f.Hour), atoi(f.Min))), nil
+}
+
+// Time returns the forecast day in the zone of the forecast. TzLong is loaded
+// as an IANA zone when possible, otherwise the offset is derived from the
+// local date and time fields.
+func (f ForecastDay) Time() (time.Time, error) {
+	t, err := parseEpoch(f.Date.Epoch)
+	if err != nil {
+		return t, err
+	}
+
+	if loc, ok := loadLocation(f.Date.TzLong); ok {
+		return t.In(loc), nil
+	}
+
+	if f.Date.Year == 0 {
+		return t.UTC(), nil
+	}
+	return t.In(wallClockZone(f.Date.TzShort, t, f.Date.Year, f.Date.Month, f.Date.Day, f.Date.Hour, atoi(f.Date.Min))), nil
+}
+
+// Location returns the zone of the observation station. LocalTzLong is loaded
+// as an IANA zone when possible, otherwise a fixed zone with the
+// LocalTzOffset is returned.
+func (c *Conditions) Location() (*time.Location, error) {
+	obs := &c.CurrentObservation
+	if loc, ok := loadLocation(obs.LocalTzLong); ok {
+		return loc, nil
+	}
+
+	offset, ok := parseOffset(obs.LocalTzOffset)
+	if !ok {
+		return nil, errors.New("wug: conditions have no usable time zone")
+	}
+	return time.FixedZone(obs.LocalTzShort, offset), nil
+}
+
+// ObservedAt returns the observation time in the zone of the station, or UTC
+// if the conditions carry no zone.
+func (c *Conditions) ObservedAt() (time.Time, error) {
+	t, err := parseEpoch(c.CurrentObservation.ObservationEpoch)
+	if err != nil {
+		return t, err
+	}
+
+	loc, err := c.Location()
+	if err != nil {
+		return t.UTC(), nil
+	}
+	return t.In(loc), nil
+}
@@ -0,0 +1,98 @@
+package wug
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestFCTTIMETime(t *testing.T) {
+	f := FCTTIME{Epoch: "1493146800", Year: "2017", Mon: "4", Mday: "25", Hour: "12", Min: "00", Tz: ""}
+	ft, err := f.Time()
+	if err != nil {
+		t.Fatalf("error getting time: %s\n", err)
+	}
+
+	if _, offset := ft.Zone(); offset != -7*3600 || ft.Hour() != 12 {
+		t.Fatalf("expected noon at -0700 got %s\n", ft)
+	}
+
+	f.Tz = "Asia/Tokyo"
+	ft, err = f.Time()
+	if err != nil {
+		t.Fatalf("error getting time: %s\n", err)
+	}
+
+	if ft.Location().String() != "Asia/Tokyo" || ft.Hour() != 4 {
+		t.Fatalf("expected Asia/Tokyo got %s\n", ft)
+	}
+
+	if _, err := (FCTTIME{}).Time(); err != ErrNoTime {
+		t.Fatalf("expected ErrNoTime got %v\n", err)
+	}
+}
+
+func TestForecastDayTime(t *testing.T) {
+	day := ForecastDay{}
+	day.Date.Epoch = "1493172000"
+	day.Date.Year, day.Date.Month, day.Date.Day, day.Date.Hour, day.Date.Min = 2017, 4, 25, 19, "00"
+	day.Date.TzShort = "PDT"
+	day.Date.TzLong = "America/Los_Angeles"
+
+	dt, err := day.Time()
+	if err != nil {
+		t.Fatalf("error getting time: %s\n", err)
+	}
+
+	if dt.Location().String() != "America/Los_Angeles" || dt.Hour() != 19 {
+		t.Fatalf("expected 19:00 America/Los_Angeles got %s\n", dt)
+	}
+
+	// zone database does not know the zone, fall back to the wall clock offset
+	day.Date.TzLong = "Nowhere/Unknown"
+	dt, err = day.Time()
+	if err != nil {
+		t.Fatalf("error getting time: %s\n", err)
+	}
+
+	if name, offset := dt.Zone(); name != "PDT" || offset != -7*3600 || dt.Hour() != 19 {
+		t.Fatalf("expected 19:00 PDT got %s\n", dt)
+	}
+}
+
+func TestConditionsObservedAt(t *testing.T) {
+	c := &Conditions{}
+	c.CurrentObservation.ObservationEpoch = "1493150400"
+	c.CurrentObservation.LocalTzLong = "Europe/Paris"
+	c.CurrentObservation.LocalTzShort = "CEST"
+	c.CurrentObservation.LocalTzOffset = "+0200"
+
+	observed, err := c.ObservedAt()
+	if err != nil {
+		t.Fatalf("error getting observation time: %s\n", err)
+	}
+
+	if observed.Location().String() != "Europe/Paris" || !observed.Equal(time.Unix(1493150400, 0)) {
+		t.Fatalf("unexpected observation time %s\n", observed)
+	}
+
+	c.CurrentObservation.LocalTzLong = ""
+	loc, err := c.Location()
+	if err != nil {
+		t.Fatalf("error getting location: %s\n", err)
+	}
+
+	if name, offset := time.Unix(0, 0).In(loc).Zone(); name != "CEST" || offset != 2*3600 {
+		t.Fatalf("expected CEST +0200 got %s %d\n", name, offset)
+	}
+
+	c.CurrentObservation.LocalTzOffset = ""
+	if _, err := c.Location(); err == nil {
+		t.Fatalf("expected error without any zone")
+	}
+
+	observed, err = c.ObservedAt()
+	if err != nil || observed.Location() != time.UTC {
+		t.Fatalf("expected UTC observation time got %s %v\n", observed, err)
+	}
+}
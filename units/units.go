@@ -0,0 +1,277 @@
+// Package units provides typed weather quantities, conversions between units
+// and formatting in a preferred unit system.
+package units
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// System of units used when formatting quantities
+type System int
+
+// System constants
+const (
+	Imperial System = iota // °F, mph, inHg, mi, in
+	Metric                 // °C, km/h, hPa, km, mm
+	SI                     // °C, m/s, hPa, km, mm
+	UKHybrid               // °C, mph, hPa, mi, mm
+)
+
+var systemNames = map[System]string{
+	Imperial: "imperial",
+	Metric:   "metric",
+	SI:       "si",
+	UKHybrid: "uk",
+}
+
+func (s System) String() string {
+	if name, ok := systemNames[s]; ok {
+		return name
+	}
+	return "System(" + strconv.Itoa(int(s)) + ")"
+}
+
+// ParseSystem returns the System for a name as returned by System.String.
+func ParseSystem(name string) (System, error) {
+	for system, systemName := range systemNames {
+		if systemName == name {
+			return system, nil
+		}
+	}
+	return Imperial, fmt.Errorf("units: unknown unit system %q", name)
+}
+
+// Quantity is a measurement that can be formatted in a unit system.
+type Quantity interface {
+	Format(system System) string
+}
+
+// Measure is a measurement that can be expressed in a unit system, as a
+// value and the symbol of its unit.
+type Measure interface {
+	In(system System) (value float64, unit string)
+}
+
+// Temperature in degrees Celsius
+type Temperature float64
+
+// Celsius returns a Temperature of c degrees Celsius.
+func Celsius(c float64) Temperature { return Temperature(c) }
+
+// Fahrenheit returns a Temperature of f degrees Fahrenheit.
+func Fahrenheit(f float64) Temperature { return Temperature((f - 32) * 5 / 9) }
+
+// Kelvin returns a Temperature of k kelvin.
+func Kelvin(k float64) Temperature { return Temperature(k - 273.15) }
+
+// Celsius returns the temperature in degrees Celsius.
+func (t Temperature) Celsius() float64 { return float64(t) }
+
+// Fahrenheit returns the temperature in degrees Fahrenheit.
+func (t Temperature) Fahrenheit() float64 { return float64(t)*9/5 + 32 }
+
+// Kelvin returns the temperature in kelvin.
+func (t Temperature) Kelvin() float64 { return float64(t) + 273.15 }
+
+// In returns the temperature in °F for Imperial, otherwise °C.
+func (t Temperature) In(system System) (float64, string) {
+	if system == Imperial {
+		return t.Fahrenheit(), "°F"
+	}
+	return t.Celsius(), "°C"
+}
+
+// Format the temperature in °F for Imperial, otherwise °C.
+func (t Temperature) Format(system System) string {
+	v, unit := t.In(system)
+	return fmt.Sprintf("%.1f%s", v, unit)
+}
+
+// Speed in meters per second
+type Speed float64
+
+// Speed conversion factors to meters per second
+const (
+	metersPerSecondPerKph  = 1000.0 / 3600.0
+	metersPerSecondPerMph  = 1609.344 / 3600.0
+	metersPerSecondPerKnot = 1852.0 / 3600.0
+)
+
+// MetersPerSecond returns a Speed of ms meters per second.
+func MetersPerSecond(ms float64) Speed { return Speed(ms) }
+
+// KilometersPerHour returns a Speed of kph kilometers per hour.
+func KilometersPerHour(kph float64) Speed { return Speed(kph * metersPerSecondPerKph) }
+
+// MilesPerHour returns a Speed of mph miles per hour.
+func MilesPerHour(mph float64) Speed { return Speed(mph * metersPerSecondPerMph) }
+
+// Knots returns a Speed of kn knots.
+func Knots(kn float64) Speed { return Speed(kn * metersPerSecondPerKnot) }
+
+// MetersPerSecond returns the speed in meters per second.
+func (s Speed) MetersPerSecond() float64 { return float64(s) }
+
+// KilometersPerHour returns the speed in kilometers per hour.
+func (s Speed) KilometersPerHour() float64 { return float64(s) / metersPerSecondPerKph }
+
+// MilesPerHour returns the speed in miles per hour.
+func (s Speed) MilesPerHour() float64 { return float64(s) / metersPerSecondPerMph }
+
+// Knots returns the speed in knots.
+func (s Speed) Knots() float64 { return float64(s) / metersPerSecondPerKnot }
+
+// In returns the speed in mph for Imperial and UKHybrid, km/h for Metric and
+// m/s for SI.
+func (s Speed) In(system System) (float64, string) {
+	switch system {
+	case Imperial, UKHybrid:
+		return s.MilesPerHour(), "mph"
+	case SI:
+		return s.MetersPerSecond(), "m/s"
+	}
+	return s.KilometersPerHour(), "km/h"
+}
+
+// Format the speed in mph for Imperial and UKHybrid, km/h for Metric and m/s
+// for SI.
+func (s Speed) Format(system System) string {
+	v, unit := s.In(system)
+	return fmt.Sprintf("%.1f %s", v, unit)
+}
+
+// Pressure in hectopascals (millibars)
+type Pressure float64
+
+const hectopascalsPerInchOfMercury = 33.8638866667
+
+// Hectopascals returns a Pressure of hpa hectopascals.
+func Hectopascals(hpa float64) Pressure { return Pressure(hpa) }
+
+// Millibars returns a Pressure of mb millibars.
+func Millibars(mb float64) Pressure { return Pressure(mb) }
+
+// Kilopascals returns a Pressure of kpa kilopascals.
+func Kilopascals(kpa float64) Pressure { return Pressure(kpa * 10) }
+
+// InchesOfMercury returns a Pressure of inhg inches of mercury.
+func InchesOfMercury(inhg float64) Pressure { return Pressure(inhg * hectopascalsPerInchOfMercury) }
+
+// Hectopascals returns the pressure in hectopascals.
+func (p Pressure) Hectopascals() float64 { return float64(p) }
+
+// Millibars returns the pressure in millibars.
+func (p Pressure) Millibars() float64 { return float64(p) }
+
+// Kilopascals returns the pressure in kilopascals.
+func (p Pressure) Kilopascals() float64 { return float64(p) / 10 }
+
+// Pascals returns the pressure in pascals.
+func (p Pressure) Pascals() float64 { return float64(p) * 100 }
+
+// InchesOfMercury returns the pressure in inches of mercury.
+func (p Pressure) InchesOfMercury() float64 { return float64(p) / hectopascalsPerInchOfMercury }
+
+// In returns the pressure in inHg for Imperial, otherwise hPa.
+func (p Pressure) In(system System) (float64, string) {
+	if system == Imperial {
+		return p.InchesOfMercury(), "inHg"
+	}
+	return p.Hectopascals(), "hPa"
+}
+
+// Format the pressure in inHg for Imperial, otherwise hPa.
+func (p Pressure) Format(system System) string {
+	v, unit := p.In(system)
+	if system == Imperial {
+		return fmt.Sprintf("%.2f %s", v, unit)
+	}
+	return fmt.Sprintf("%.1f %s", v, unit)
+}
+
+// Length in meters, used for distances such as visibility and elevation
+type Length float64
+
+const (
+	metersPerMile = 1609.344
+	metersPerFoot = 0.3048
+)
+
+// Meters returns a Length of m meters.
+func Meters(m float64) Length { return Length(m) }
+
+// Kilometers returns a Length of km kilometers.
+func Kilometers(km float64) Length { return Length(km * 1000) }
+
+// Miles returns a Length of mi miles.
+func Miles(mi float64) Length { return Length(mi * metersPerMile) }
+
+// Feet returns a Length of ft feet.
+func Feet(ft float64) Length { return Length(ft * metersPerFoot) }
+
+// Meters returns the length in meters.
+func (l Length) Meters() float64 { return float64(l) }
+
+// Kilometers returns the length in kilometers.
+func (l Length) Kilometers() float64 { return float64(l) / 1000 }
+
+// Miles returns the length in miles.
+func (l Length) Miles() float64 { return float64(l) / metersPerMile }
+
+// Feet returns the length in feet.
+func (l Length) Feet() float64 { return float64(l) / metersPerFoot }
+
+// In returns the length in miles for Imperial and UKHybrid, otherwise km.
+func (l Length) In(system System) (float64, string) {
+	if system == Imperial || system == UKHybrid {
+		return l.Miles(), "mi"
+	}
+	return l.Kilometers(), "km"
+}
+
+// Format the length in miles for Imperial and UKHybrid, otherwise km.
+func (l Length) Format(system System) string {
+	v, unit := l.In(system)
+	return fmt.Sprintf("%.1f %s", v, unit)
+}
+
+// Precipitation depth in millimeters
+type Precipitation float64
+
+const millimetersPerInch = 25.4
+
+// Millimeters returns a Precipitation of mm millimeters.
+func Millimeters(mm float64) Precipitation { return Precipitation(mm) }
+
+// Centimeters returns a Precipitation of cm centimeters.
+func Centimeters(cm float64) Precipitation { return Precipitation(cm * 10) }
+
+// Inches returns a Precipitation of in inches.
+func Inches(in float64) Precipitation { return Precipitation(in * millimetersPerInch) }
+
+// Millimeters returns the precipitation in millimeters.
+func (p Precipitation) Millimeters() float64 { return float64(p) }
+
+// Centimeters returns the precipitation in centimeters.
+func (p Precipitation) Centimeters() float64 { return float64(p) / 10 }
+
+// Inches returns the precipitation in inches.
+func (p Precipitation) Inches() float64 { return float64(p) / millimetersPerInch }
+
+// In returns the precipitation in inches for Imperial, otherwise mm.
+func (p Precipitation) In(system System) (float64, string) {
+	if system == Imperial {
+		return p.Inches(), "in"
+	}
+	return p.Millimeters(), "mm"
+}
+
+// Format the precipitation in inches for Imperial, otherwise mm.
+func (p Precipitation) Format(system System) string {
+	v, unit := p.In(system)
+	if system == Imperial {
+		return fmt.Sprintf("%.2f %s", v, unit)
+	}
+	return fmt.Sprintf("%.1f %s", v, unit)
+}
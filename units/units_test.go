@@ -0,0 +1,88 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestConversions(t *testing.T) {
+	var tests = []struct {
+		name      string
+		got, want float64
+	}{
+		{"32F in C", Fahrenheit(32).Celsius(), 0},
+		{"100C in F", Celsius(100).Fahrenheit(), 212},
+		{"0K in C", Kelvin(0).Celsius(), -273.15},
+		{"-40C in F", Celsius(-40).Fahrenheit(), -40},
+		{"36 km/h in m/s", KilometersPerHour(36).MetersPerSecond(), 10},
+		{"60 mph in km/h", MilesPerHour(60).KilometersPerHour(), 96.56064},
+		{"1 knot in km/h", Knots(1).KilometersPerHour(), 1.852},
+		{"10 m/s in knots", MetersPerSecond(10).Knots(), 19.438444924},
+		{"29.92 inHg in hPa", InchesOfMercury(29.92).Hectopascals(), 1013.20748},
+		{"1013.25 hPa in kPa", Hectopascals(1013.25).Kilopascals(), 101.325},
+		{"1013.25 mb in Pa", Millibars(1013.25).Pascals(), 101325},
+		{"1 mi in km", Miles(1).Kilometers(), 1.609344},
+		{"1000 ft in m", Feet(1000).Meters(), 304.8},
+		{"10 km in mi", Kilometers(10).Miles(), 6.213711922},
+		{"1 in in mm", Inches(1).Millimeters(), 25.4},
+		{"2 cm in in", Centimeters(2).Inches(), 0.787401575},
+	}
+
+	for _, tt := range tests {
+		if math.Abs(tt.got-tt.want) > 1e-5 {
+			t.Fatalf("%s: expected %v got %v\n", tt.name, tt.want, tt.got)
+		}
+	}
+
+	if !near(Fahrenheit(Celsius(21.5).Fahrenheit()).Celsius(), 21.5) {
+		t.Fatalf("temperature did not round trip")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	var tests = []struct {
+		quantity Quantity
+		want     map[System]string
+	}{
+		{Celsius(20), map[System]string{Imperial: "68.0°F", Metric: "20.0°C", SI: "20.0°C", UKHybrid: "20.0°C"}},
+		{KilometersPerHour(36), map[System]string{Imperial: "22.4 mph", Metric: "36.0 km/h", SI: "10.0 m/s", UKHybrid: "22.4 mph"}},
+		{Hectopascals(1013.2), map[System]string{Imperial: "29.92 inHg", Metric: "1013.2 hPa", SI: "1013.2 hPa", UKHybrid: "1013.2 hPa"}},
+		{Kilometers(16.1), map[System]string{Imperial: "10.0 mi", Metric: "16.1 km", SI: "16.1 km", UKHybrid: "10.0 mi"}},
+		{Millimeters(12.7), map[System]string{Imperial: "0.50 in", Metric: "12.7 mm", SI: "12.7 mm", UKHybrid: "12.7 mm"}},
+	}
+
+	for _, tt := range tests {
+		for system, want := range tt.want {
+			if got := tt.quantity.Format(system); got != want {
+				t.Fatalf("%s: expected %s got %s\n", system, want, got)
+			}
+		}
+	}
+}
+
+func TestIn(t *testing.T) {
+	if v, unit := Celsius(20).In(Imperial); v != 68 || unit != "°F" {
+		t.Fatalf("expected 68 °F got %v %s\n", v, unit)
+	}
+
+	if v, unit := KilometersPerHour(36).In(SI); math.Abs(v-10) > 1e-9 || unit != "m/s" {
+		t.Fatalf("expected 10 m/s got %v %s\n", v, unit)
+	}
+}
+
+func TestParseSystem(t *testing.T) {
+	for _, system := range []System{Imperial, Metric, SI, UKHybrid} {
+		parsed, err := ParseSystem(system.String())
+		if err != nil || parsed != system {
+			t.Fatalf("expected %s got %s %v\n", system, parsed, err)
+		}
+	}
+
+	if _, err := ParseSystem("cubits"); err == nil {
+		t.Fatalf("expected error for unknown system")
+	}
+}
@@ -1,16 +1,25 @@
 package wug
 
 import (
-	"encoding/json"
-	"fmt"
+	"bytes"
+	"context"
+	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
+
+	"github.com/wirepair/wug/units"
+	"golang.org/x/time/rate"
 )
 
-// The weather underground API format string
-const requestURL = "http://api.wunderground.com/api/%s/%s/q/%s"
+// The weather underground API scheme and host
+const (
+	apiScheme = "http"
+	apiHost   = "api.wunderground.com"
+)
 
 // RequestType that is supported for weather underground requests
 type RequestType int
@@ -22,6 +31,8 @@ const (
 	ForeTenDay                    // Ten Day Forecast request
 	Hour                          // Hourly request
 	HourTenDay                    // Ten Day Hourly request
+	Alert                         // Severe weather alerts request
+	Astro                         // Astronomy request
 )
 
 var requestMap = map[RequestType]string{
@@ -30,11 +41,34 @@ var requestMap = map[RequestType]string{
 	ForeTenDay: "forecast10day",
 	Hour:       "hourly",
 	HourTenDay: "hourly10day",
+	Alert:      "alerts",
+	Astro:      "astronomy",
 }
 
 // Wug API client that uses Query's to request data from weather underground
 type Wug struct {
-	Client *http.Client
+	Client   *http.Client
+	Settings Settings      // client wide settings, may be overridden per Query
+	Limiter  *rate.Limiter // optional limiter every request waits on, to stay within the key quota
+
+	// MaxResponseSize in bytes of a response body, defaults to DefaultMaxResponseSize
+	MaxResponseSize int64
+
+	// Middleware wrapped around every upstream request, first is outermost
+	Middleware []Middleware
+
+	// Lenient decodes typed responses tolerating type drift of fields, see
+	// UnmarshalLenient
+	Lenient bool
+
+	// Units is the preferred unit system of formatters, see Format
+	Units units.System
+
+	// Coalesced is optional, called for every request served by an identical
+	// in flight request instead of an upstream request of its own
+	Coalesced func(requestType RequestType)
+
+	flights flightGroup // coalesces concurrent identical requests
 }
 
 // NewWug returns a Wug client with a configured http.Client and transport.
@@ -60,17 +94,7 @@ func (w *Wug) GetRawConditions(query *Query) ([]byte, error) {
 
 // GetConditions returns the Conditions of the request
 func (w *Wug) GetConditions(query *Query) (*Conditions, error) {
-	data, err := w.GetRawConditions(query)
-	if err != nil {
-		return nil, err
-	}
-
-	conditions := &Conditions{}
-	err = json.Unmarshal(data, conditions)
-	if err != nil {
-		return nil, err
-	}
-	return conditions, nil
+	return GetFeature(context.Background(), w, ConditionsFeature, query)
 }
 
 // GetRawHourly returns the raw bytes of an hourly request
@@ -80,17 +104,7 @@ func (w *Wug) GetRawHourly(query *Query) ([]byte, error) {
 
 // GetHourly returns the Hourly data.
 func (w *Wug) GetHourly(query *Query) (*Hourly, error) {
-	data, err := w.GetRawHourly(query)
-	if err != nil {
-		return nil, err
-	}
-
-	hourly := &Hourly{}
-	err = json.Unmarshal(data, hourly)
-	if err != nil {
-		return nil, err
-	}
-	return hourly, nil
+	return GetFeature(context.Background(), w, HourlyFeature, query)
 }
 
 // GetRawHourlyTenDay returns the raw bytes of an hourly ten day request
@@ -100,17 +114,7 @@ func (w *Wug) GetRawHourlyTenDay(query *Query) ([]byte, error) {
 
 // GetHourlyTenDay returns the HourlyTenDay
 func (w *Wug) GetHourlyTenDay(query *Query) (*HourlyTenDay, error) {
-	data, err := w.GetRawHourlyTenDay(query)
-	if err != nil {
-		return nil, err
-	}
-
-	hourly := &HourlyTenDay{}
-	err = json.Unmarshal(data, hourly)
-	if err != nil {
-		return nil, err
-	}
-	return hourly, nil
+	return GetFeature(context.Background(), w, HourlyTenDayFeature, query)
 }
 
 // GetRawForecast returns the raw bytes of a forecast request
@@ -120,17 +124,7 @@ func (w *Wug) GetRawForecast(query *Query) ([]byte, error) {
 
 // GetForecast returns the Forecast
 func (w *Wug) GetForecast(query *Query) (*Forecast, error) {
-	data, err := w.GetRawForecast(query)
-	if err != nil {
-		return nil, err
-	}
-
-	forecast := &Forecast{}
-	err = json.Unmarshal(data, forecast)
-	if err != nil {
-		return nil, err
-	}
-	return forecast, nil
+	return GetFeature(context.Background(), w, ForecastFeature, query)
 }
 
 // GetRawForecastTenDay returns the raw bytes of a ten day forecast request
@@ -140,27 +134,149 @@ func (w *Wug) GetRawForecastTenDay(query *Query) ([]byte, error) {
 
 // GetForecastTenDay returns the ForecastTenDay
 func (w *Wug) GetForecastTenDay(query *Query) (*ForecastTenDay, error) {
-	data, err := w.GetRawForecastTenDay(query)
-	if err != nil {
+	return GetFeature(context.Background(), w, ForecastTenDayFeature, query)
+}
+
+// GetRawAlerts returns the raw bytes of an alerts request
+func (w *Wug) GetRawAlerts(query *Query) ([]byte, error) {
+	return w.Get(Alert, query)
+}
+
+// GetAlerts returns the Alerts
+func (w *Wug) GetAlerts(query *Query) (*Alerts, error) {
+	return GetFeature(context.Background(), w, AlertsFeature, query)
+}
+
+// GetRawAstronomy returns the raw bytes of an astronomy request
+func (w *Wug) GetRawAstronomy(query *Query) ([]byte, error) {
+	return w.Get(Astro, query)
+}
+
+// GetAstronomy returns the Astronomy
+func (w *Wug) GetAstronomy(query *Query) (*Astronomy, error) {
+	return GetFeature(context.Background(), w, AstronomyFeature, query)
+}
+
+// requestURL builds the /api/{key}/{feature}/{settings}/q/{query} url for the
+// request type, settings and query. Query components are already escaped by
+// the query builders.
+func requestURL(requestType RequestType, settings Settings, query *Query) (*url.URL, error) {
+	if err := settings.Validate(); err != nil {
 		return nil, err
 	}
 
-	forecast := &ForecastTenDay{}
-	err = json.Unmarshal(data, forecast)
+	segments := []string{"", "api", escapeComponent(query.apiKey), requestMap[requestType]}
+	segments = append(segments, settings.segments()...)
+	segments = append(segments, "q")
+	rawPath := strings.Join(segments, "/") + query.queryPath
+	path, err := url.PathUnescape(rawPath)
 	if err != nil {
 		return nil, err
 	}
-	return forecast, nil
+
+	return &url.URL{
+		Scheme:   apiScheme,
+		Host:     apiHost,
+		Path:     path,
+		RawPath:  rawPath,
+		RawQuery: query.queryArgs.Encode(),
+	}, nil
 }
 
 // Get returns the raw bytes of a request type given the provided Query.
 func (w *Wug) Get(requestType RequestType, query *Query) ([]byte, error) {
-	request := fmt.Sprintf(requestURL, query.apiKey, requestMap[requestType], query.queryValue)
-	resp, err := w.Client.Get(request)
+	return w.GetWithContext(context.Background(), requestType, query)
+}
+
+// GetWithContext returns the raw bytes of a request type given the provided
+// Query. The context is used for waiting on the Limiter and for the request.
+// Concurrent identical requests (same feature, settings and query) are
+// coalesced into a single upstream request, each caller gets its own bytes.
+func (w *Wug) GetWithContext(ctx context.Context, requestType RequestType, query *Query) ([]byte, error) {
+	data, err := w.request(ctx, requestType, query, "raw", func(body io.Reader) (interface{}, error) {
+		data, err := ioutil.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		return data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data.([]byte), nil
+}
+
+// flightResult is the decoded value of a coalesced request and the body it
+// was decoded from
+type flightResult struct {
+	value interface{}
+	body  []byte
+}
+
+// request builds the url of the request, then coalesces it with identical
+// in flight requests of the same kind. The upstream request is passed through
+// the Middleware, error responses are returned as errors and the response
+// body is limited to MaxResponseSize and decoded as it streams in. One waiter
+// gets the decoded value. The body is only kept when others joined before it
+// arrived, they decode their own from it so no two callers share a value.
+func (w *Wug) request(ctx context.Context, requestType RequestType, query *Query, kind string, decode func(body io.Reader) (interface{}, error)) (interface{}, error) {
+	settings := w.Settings.Merge(query.settings)
+	target, err := requestURL(requestType, settings, query)
+	if err != nil {
+		return nil, err
+	}
+
+	result, shared, err := w.flights.do(ctx, kind+" "+target.String(), func(ctx context.Context, keep func() bool) (interface{}, error) {
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := w.handler()(&Request{Type: requestType, Query: query, Settings: settings, HTTP: request})
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		r, err := checkResponse(resp, newLimitedReader(resp.Body, w.maxResponseSize()))
+		if err != nil {
+			return nil, err
+		}
+
+		if !keep() {
+			value, err := decode(r)
+			if err != nil {
+				return nil, err
+			}
+			return &flightResult{value: value}, nil
+		}
+
+		var body bytes.Buffer
+		value, err := decode(io.TeeReader(r, &body))
+		if err != nil {
+			return nil, err
+		}
+		return &flightResult{value: value, body: body.Bytes()}, nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	return ioutil.ReadAll(resp.Body)
+	r := result.(*flightResult)
+	if !shared {
+		return r.value, nil
+	}
+
+	if w.Coalesced != nil {
+		w.Coalesced(requestType)
+	}
+	return decode(bytes.NewReader(r.body))
+}
+
+// maxResponseSize returns MaxResponseSize or the default if it is not set.
+func (w *Wug) maxResponseSize() int64 {
+	if w.MaxResponseSize <= 0 {
+		return DefaultMaxResponseSize
+	}
+	return w.MaxResponseSize
 }
@@ -1,20 +1,43 @@
-package wug
+package wug_test
 
 import (
 	"os"
 	"testing"
+
+	"github.com/wirepair/wug"
+	"github.com/wirepair/wug/wugtest"
 )
 
-var testAPIKey string
+// fixtures of the tests, recorded from the wugtest fake server (station
+// WUGTEST) since the API no longer issues keys. Running the tests with
+// WUG_RECORD=1 and WUGKEY set records them again from the live API.
+const fixtures = "testdata/fixtures"
+
+var testAPIKey = "testkey"
 
 func init() {
-	testAPIKey = os.Getenv("WUGKEY")
+	if key := os.Getenv("WUGKEY"); key != "" && wugtest.ModeFromEnv() == wugtest.ModeRecord {
+		testAPIKey = key
+	}
+}
+
+// newTestWug returns a client that records the live API in record mode and
+// otherwise replays the recorded fixtures, failing the test on requests
+// without one.
+func newTestWug(t *testing.T) *wug.Wug {
+	recorder := wugtest.NewRecorder(fixtures, wugtest.ModeFromEnv())
+	t.Cleanup(func() {
+		for _, request := range recorder.Unmatched() {
+			t.Errorf("no fixture for %v %s %v, record it with %s=1\n", request.Features, request.Query, request.Settings, wugtest.RecordEnv)
+		}
+	})
+	return recorder.Wug()
 }
 
 func TestRawConditions(t *testing.T) {
-	wug := NewWug()
-	q := NewQueryByAutoIP(testAPIKey)
-	data, err := wug.GetRawConditions(q)
+	w := newTestWug(t)
+	q := wug.NewQueryByAutoIP(testAPIKey)
+	data, err := w.GetRawConditions(q)
 	if err != nil {
 		t.Fatalf("error getting conditions: %s\n", err)
 	}
@@ -27,9 +50,9 @@ func TestRawConditions(t *testing.T) {
 }
 
 func TestRawForecast(t *testing.T) {
-	wug := NewWug()
-	q := NewQueryByAutoIP(testAPIKey)
-	data, err := wug.GetRawForecast(q)
+	w := newTestWug(t)
+	q := wug.NewQueryByAutoIP(testAPIKey)
+	data, err := w.GetRawForecast(q)
 	if err != nil {
 		t.Fatalf("error getting conditions: %s\n", err)
 	}
@@ -42,20 +65,24 @@ func TestRawForecast(t *testing.T) {
 }
 
 func TestForecast(t *testing.T) {
-	wug := NewWug()
-	q := NewQueryByAutoIP(testAPIKey)
-	data, err := wug.GetForecast(q)
+	w := newTestWug(t)
+	q := wug.NewQueryByAutoIP(testAPIKey)
+	data, err := w.GetForecast(q)
 	if err != nil {
 		t.Fatalf("error getting forecast: %s\n", err)
 	}
 
+	if len(data.Forecast.Simpleforecast.Forecastday) == 0 {
+		t.Fatalf("error forecast was empty")
+	}
+
 	t.Logf("%#v\n", data)
 }
 
 func TestHourly(t *testing.T) {
-	wug := NewWug()
-	q := NewQueryByAutoIP(testAPIKey)
-	data, err := wug.GetHourly(q)
+	w := newTestWug(t)
+	q := wug.NewQueryByAutoIP(testAPIKey)
+	data, err := w.GetHourly(q)
 	if err != nil {
 		t.Fatalf("error getting hourly: %s\n", err)
 	}
@@ -64,9 +91,9 @@ func TestHourly(t *testing.T) {
 }
 
 func TestHourlyTenDay(t *testing.T) {
-	wug := NewWug()
-	q := NewQueryByAutoIP(testAPIKey)
-	data, err := wug.GetHourlyTenDay(q)
+	w := newTestWug(t)
+	q := wug.NewQueryByAutoIP(testAPIKey)
+	data, err := w.GetHourlyTenDay(q)
 	if err != nil {
 		t.Fatalf("error getting hourly: %s\n", err)
 	}
@@ -75,10 +102,14 @@ func TestHourlyTenDay(t *testing.T) {
 }
 
 func TestHourlyTenDayLatLong(t *testing.T) {
-	wug := NewWug()
-	q := NewQueryByLatLong(testAPIKey, "35.350178", "139.623993")
-	_, err := wug.GetHourlyTenDay(q)
+	w := newTestWug(t)
+	q := wug.NewQueryByLatLong(testAPIKey, "35.350178", "139.623993")
+	data, err := w.GetHourlyTenDay(q)
 	if err != nil {
 		t.Fatalf("error getting hourly: %s\n", err)
 	}
+
+	if len(data.Hourly) != 240 {
+		t.Fatalf("expected 240 hours got %d\n", len(data.Hourly))
+	}
 }
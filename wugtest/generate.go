@@ -0,0 +1,407 @@
+package wugtest
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wirepair/wug"
+)
+
+// Location the fake server generates weather for
+type Location struct {
+	City      string
+	State     string // state or region code, e.g. CA
+	Country   string // ISO 3166 country code
+	Latitude  float64
+	Longitude float64
+	Elevation float64 // meters
+	TimeZone  string  // IANA zone, UTC when it can not be loaded
+}
+
+// DefaultLocation is used for autoip queries and queries with no location set
+var DefaultLocation = Location{
+	City:      "San Francisco",
+	State:     "CA",
+	Country:   "US",
+	Latitude:  37.77,
+	Longitude: -122.42,
+	Elevation: 47,
+	TimeZone:  "America/Los_Angeles",
+}
+
+// parseLocation derives a location from a query path such as CA/San_Francisco,
+// France/Paris or 37.77,-122.42.
+func parseLocation(query string) Location {
+	loc := DefaultLocation
+	if query == "autoip" || query == "" {
+		return loc
+	}
+
+	if parts := strings.Split(query, ","); len(parts) == 2 {
+		lat, errLat := strconv.ParseFloat(parts[0], 64)
+		long, errLong := strconv.ParseFloat(parts[1], 64)
+		if errLat == nil && errLong == nil {
+			loc.City, loc.Latitude, loc.Longitude = query, lat, long
+			return loc
+		}
+	}
+
+	if parts := strings.Split(query, "/"); len(parts) == 2 {
+		loc.City = strings.ReplaceAll(parts[1], "_", " ")
+		if len(parts[0]) == 2 && strings.ToUpper(parts[0]) == parts[0] {
+			loc.State = parts[0]
+		} else {
+			loc.State, loc.Country = "", parts[0]
+		}
+		return loc
+	}
+
+	loc.City = query
+	return loc
+}
+
+func (l Location) location() *time.Location {
+	if zone, err := time.LoadLocation(l.TimeZone); err == nil {
+		return zone
+	}
+	return time.UTC
+}
+
+func (l Location) full() string {
+	if l.State == "" {
+		return l.City + ", " + l.Country
+	}
+	return l.City + ", " + l.State
+}
+
+// seed returns a stable number for the location so generated weather
+// differs between locations but not between requests.
+func (l Location) seed() float64 {
+	h := fnv.New32a()
+	h.Write([]byte(l.full()))
+	return float64(h.Sum32()%1000) / 1000
+}
+
+// baseTemperature returns the mean temperature in °C of the location.
+func (l Location) baseTemperature() float64 {
+	return 25 - math.Abs(l.Latitude)*0.4 + l.seed()*6
+}
+
+// weather is generated weather at one point in time
+type weather struct {
+	temp, dewpoint, humidity float64 // °C, °C, percent
+	wind, windDegrees        float64 // kph, degrees
+	pressure                 float64 // hPa
+	pop, qpf                 float64 // percent, mm over an hour
+	sky                      float64 // percent
+	code                     wug.FctCode
+}
+
+// codes weather is generated from, from clearest to wettest
+var codes = []wug.FctCode{wug.FctClear, wug.FctPartlyCloudy, wug.FctMostlyCloudy, wug.FctCloudy, wug.FctChanceRain, wug.FctRain}
+
+// generate returns the weather of the location at t, a diurnal cycle over a
+// slower multi day cycle.
+func (l Location) generate(t time.Time) weather {
+	local := t.In(l.location())
+	hours := float64(t.Unix()) / 3600
+	hour := float64(local.Hour()) + float64(local.Minute())/60
+	days := hours / 24
+
+	cycle := math.Sin(2*math.Pi*(days/5+l.seed()))*0.5 + 0.5 // 0 clear to 1 wet
+	w := weather{
+		temp:        l.baseTemperature() + 6*math.Sin(2*math.Pi*(hour-9)/24) - 4*cycle,
+		wind:        8 + 12*cycle + 4*math.Sin(2*math.Pi*hour/24),
+		windDegrees: math.Mod(200+90*math.Sin(2*math.Pi*days/7)+360, 360),
+		pressure:    1020 - 20*cycle,
+		sky:         math.Round(100 * cycle),
+		code:        codes[int(math.Min(cycle*float64(len(codes)), float64(len(codes)-1)))],
+	}
+
+	w.humidity = math.Round(45 + 45*cycle)
+	w.dewpoint = w.temp - (100-w.humidity)/5
+	if w.code == wug.FctChanceRain || w.code == wug.FctRain {
+		w.pop = math.Round(cycle*10) * 10
+		w.qpf = math.Round(20*(cycle-0.6)) / 10
+	}
+	return w
+}
+
+func number(v float64) wug.FlexFloat {
+	return wug.FlexFloat{Value: math.Round(v*10) / 10, Valid: true}
+}
+
+func fahrenheit(c float64) float64 { return c*9/5 + 32 }
+
+func mph(kph float64) float64 { return kph / 1.609344 }
+
+func inches(mm float64) float64 { return mm / 25.4 }
+
+var compass = []string{"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE", "S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW"}
+
+func direction(degrees float64) string {
+	return compass[int(math.Round(degrees/22.5))%len(compass)]
+}
+
+// isNight returns true for the local hours icons use the night variant in.
+func isNight(t time.Time) bool {
+	return t.Hour() < 6 || t.Hour() >= 19
+}
+
+// icon returns the icon name of the code and its url, which points to the
+// night variant at night.
+func icon(code wug.FctCode, night bool) (name, url string) {
+	return code.Icon().String(), "http://icons.wxug.com/i/c/k/" + code.Icon().Variant(night) + ".gif"
+}
+
+// GenerateConditions returns generated current conditions of the location at
+// now.
+func GenerateConditions(loc Location, now time.Time) *wug.Conditions {
+	local := now.In(loc.location())
+	w := loc.generate(now)
+	c := &wug.Conditions{}
+	c.Response.Version = "0.1"
+	c.Response.TermsofService = termsOfService
+	c.Response.Features.Conditions = 1
+
+	obs := &c.CurrentObservation
+	obs.DisplayLocation.Full = loc.full()
+	obs.DisplayLocation.City = loc.City
+	obs.DisplayLocation.State = loc.State
+	obs.DisplayLocation.Country = loc.Country
+	obs.DisplayLocation.CountryIso3166 = loc.Country
+	obs.DisplayLocation.Latitude = number(loc.Latitude)
+	obs.DisplayLocation.Longitude = number(loc.Longitude)
+	obs.DisplayLocation.Elevation = number(loc.Elevation)
+	obs.ObservationLocation.Full = loc.full()
+	obs.ObservationLocation.City = loc.City
+	obs.ObservationLocation.State = loc.State
+	obs.ObservationLocation.Country = loc.Country
+	obs.ObservationLocation.CountryIso3166 = loc.Country
+	obs.ObservationLocation.Latitude = number(loc.Latitude)
+	obs.ObservationLocation.Longitude = number(loc.Longitude)
+	obs.ObservationLocation.Elevation = fmt.Sprintf("%.0f ft", loc.Elevation/0.3048)
+
+	zone, _ := local.Zone()
+	obs.StationID = "WUGTEST"
+	obs.ObservationTime = "Last Updated on " + local.Format("January 2, 3:04 PM MST")
+	obs.ObservationTimeRfc822 = local.Format(time.RFC1123Z)
+	obs.ObservationEpoch = strconv.FormatInt(now.Unix(), 10)
+	obs.LocalTimeRfc822 = local.Format(time.RFC1123Z)
+	obs.LocalEpoch = strconv.FormatInt(now.Unix(), 10)
+	obs.LocalTzShort = zone
+	obs.LocalTzLong = loc.location().String()
+	obs.LocalTzOffset = local.Format("-0700")
+
+	obs.Weather = w.code.Description(wug.LangEnglish)
+	obs.Icon, obs.IconURL = icon(w.code, isNight(local))
+	obs.TempC, obs.TempF = number(w.temp), number(fahrenheit(w.temp))
+	obs.TemperatureString = fmt.Sprintf("%.1f F (%.1f C)", fahrenheit(w.temp), w.temp)
+	obs.RelativeHumidity = number(w.humidity)
+	obs.DewpointC, obs.DewpointF = number(w.dewpoint), number(fahrenheit(w.dewpoint))
+	obs.DewpointString = fmt.Sprintf("%.0f F (%.0f C)", fahrenheit(w.dewpoint), w.dewpoint)
+	obs.WindDir = direction(w.windDegrees)
+	obs.WindDegrees = number(math.Round(w.windDegrees))
+	obs.WindKph, obs.WindMph = number(w.wind), number(mph(w.wind))
+	obs.WindGustKph, obs.WindGustMph = number(w.wind*1.5), number(mph(w.wind*1.5))
+	obs.WindString = fmt.Sprintf("From the %s at %.1f MPH", obs.WindDir, mph(w.wind))
+	obs.PressureMb, obs.PressureIn = number(w.pressure), wug.FlexFloat{Value: math.Round(w.pressure/33.8639*100) / 100, Valid: true}
+	obs.PressureTrend = "0"
+	obs.FeelslikeC, obs.FeelslikeF = number(w.temp), number(fahrenheit(w.temp))
+	obs.FeelslikeString = fmt.Sprintf("%.0f F (%.0f C)", fahrenheit(w.temp), w.temp)
+	obs.HeatIndexString, obs.WindchillString = "NA", "NA"
+	obs.VisibilityKm, obs.VisibilityMi = number(16), number(10)
+	obs.UV = number(math.Max(0, 8*math.Sin(math.Pi*(float64(local.Hour())-6)/13)))
+	obs.Precip1HrIn, obs.Precip1HrMetric = number(inches(w.qpf)), number(w.qpf)
+	obs.PrecipTodayIn, obs.PrecipTodayMetric = number(inches(w.qpf*4)), number(w.qpf*4)
+	return c
+}
+
+// GenerateForecast returns a generated forecast of days days starting on the
+// day of now, with a day and night text period per day.
+func GenerateForecast(loc Location, now time.Time, days int) wug.ForecastData {
+	local := now.In(loc.location())
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+
+	var data wug.ForecastData
+	data.Textforecast.Date = local.Format("3:04 PM MST")
+	for i := 0; i < days; i++ {
+		date := midnight.AddDate(0, 0, i)
+		high, low := loc.generate(date.Add(15*time.Hour)), loc.generate(date.Add(27*time.Hour))
+		data.Simpleforecast.Forecastday = append(data.Simpleforecast.Forecastday, forecastDay(i+1, date.Add(19*time.Hour), high, low))
+
+		weekday := date.Weekday().String()
+		data.Textforecast.TxtForecastday = append(data.Textforecast.TxtForecastday,
+			textPeriod(2*i, weekday, "High", high, false),
+			textPeriod(2*i+1, weekday+" Night", "Low", low, true))
+	}
+	return data
+}
+
+func textPeriod(period int, title, extreme string, w weather, night bool) wug.TxtForecastDay {
+	name, url := icon(w.code, night)
+	description := w.code.Description(wug.LangEnglish)
+	return wug.TxtForecastDay{
+		Period:        period,
+		Icon:          name,
+		IconURL:       url,
+		Title:         title,
+		Fcttext:       fmt.Sprintf("%s. %s %.0fF.", description, extreme, fahrenheit(w.temp)),
+		FcttextMetric: fmt.Sprintf("%s. %s %.0fC.", description, extreme, w.temp),
+		Pop:           strconv.Itoa(int(w.pop)),
+	}
+}
+
+func forecastDay(period int, t time.Time, high, low weather) wug.ForecastDay {
+	var day wug.ForecastDay
+	zone, _ := t.Zone()
+	day.Date.Epoch = strconv.FormatInt(t.Unix(), 10)
+	day.Date.Pretty = t.Format("3:04 PM MST on January 2, 2006")
+	day.Date.Day, day.Date.Month, day.Date.Year = t.Day(), int(t.Month()), t.Year()
+	day.Date.Yday = t.YearDay() - 1
+	day.Date.Hour, day.Date.Min = t.Hour(), t.Format("04")
+	day.Date.Monthname, day.Date.MonthnameShort = t.Month().String(), t.Format("Jan")
+	day.Date.Weekday, day.Date.WeekdayShort = t.Weekday().String(), t.Format("Mon")
+	day.Date.Ampm = t.Format("PM")
+	day.Date.TzShort, day.Date.TzLong = zone, t.Location().String()
+	day.Period = period
+
+	day.High.Celsius, day.High.Fahrenheit = number(math.Round(high.temp)), number(math.Round(fahrenheit(high.temp)))
+	day.Low.Celsius, day.Low.Fahrenheit = number(math.Round(low.temp)), number(math.Round(fahrenheit(low.temp)))
+	day.Conditions = high.code.Description(wug.LangEnglish)
+	day.Icon, day.IconURL = icon(high.code, false)
+	day.Skyicon = day.Icon
+	day.Pop = number(math.Max(high.pop, low.pop))
+	day.QpfAllday.Mm, day.QpfAllday.In = number(12*(high.qpf+low.qpf)), number(inches(12*(high.qpf+low.qpf)))
+	day.QpfDay.Mm, day.QpfDay.In = number(12*high.qpf), number(inches(12*high.qpf))
+	day.QpfNight.Mm, day.QpfNight.In = number(12*low.qpf), number(inches(12*low.qpf))
+	day.SnowAllday.Cm, day.SnowAllday.In = number(0), number(0)
+	day.SnowDay.Cm, day.SnowDay.In = number(0), number(0)
+	day.SnowNight.Cm, day.SnowNight.In = number(0), number(0)
+	day.Maxwind.Kph, day.Maxwind.Mph = number(high.wind*1.5), number(mph(high.wind*1.5))
+	day.Maxwind.Dir, day.Maxwind.Degrees = direction(high.windDegrees), number(math.Round(high.windDegrees))
+	day.Avewind.Kph, day.Avewind.Mph = number(high.wind), number(mph(high.wind))
+	day.Avewind.Dir, day.Avewind.Degrees = direction(high.windDegrees), number(math.Round(high.windDegrees))
+	day.Avehumidity = number((high.humidity + low.humidity) / 2)
+	day.Maxhumidity = number(math.Max(high.humidity, low.humidity))
+	day.Minhumidity = number(math.Min(high.humidity, low.humidity))
+	return day
+}
+
+// GenerateHourly returns hours generated hourly forecasts starting with the
+// hour after now.
+func GenerateHourly(loc Location, now time.Time, hours int) []wug.HourlyForecast {
+	start := now.In(loc.location()).Truncate(time.Hour).Add(time.Hour)
+	forecasts := make([]wug.HourlyForecast, hours)
+	for i := range forecasts {
+		t := start.Add(time.Duration(i) * time.Hour)
+		w := loc.generate(t)
+		h := &forecasts[i]
+		h.Fcttime = fcttime(t)
+		h.Temp.Metric, h.Temp.English = number(math.Round(w.temp)), number(math.Round(fahrenheit(w.temp)))
+		h.Dewpoint.Metric, h.Dewpoint.English = number(math.Round(w.dewpoint)), number(math.Round(fahrenheit(w.dewpoint)))
+		h.Condition = w.code.Description(wug.LangEnglish)
+		h.Icon, h.IconURL = icon(w.code, isNight(t))
+		h.Fctcode = strconv.Itoa(int(w.code))
+		h.Sky = number(w.sky)
+		h.Wspd.Metric, h.Wspd.English = number(math.Round(w.wind)), number(math.Round(mph(w.wind)))
+		h.Wdir.Dir, h.Wdir.Degrees = direction(w.windDegrees), number(math.Round(w.windDegrees))
+		h.Wx = h.Condition
+		h.Uvi = number(math.Round(math.Max(0, 8*math.Sin(math.Pi*(float64(t.Hour())-6)/13))))
+		h.Humidity = number(w.humidity)
+		h.Windchill.Metric, h.Windchill.English = number(-9999), number(-9999)
+		h.Heatindex.Metric, h.Heatindex.English = number(-9999), number(-9999)
+		h.Feelslike.Metric, h.Feelslike.English = h.Temp.Metric, h.Temp.English
+		h.Qpf.Metric, h.Qpf.English = number(w.qpf), number(inches(w.qpf))
+		h.Snow.Metric, h.Snow.English = number(0), number(0)
+		h.Pop = number(w.pop)
+		h.Mslp.Metric, h.Mslp.English = number(math.Round(w.pressure)), wug.FlexFloat{Value: math.Round(w.pressure/33.8639*100) / 100, Valid: true}
+	}
+	return forecasts
+}
+
+func fcttime(t time.Time) wug.FCTTIME {
+	isdst := "0"
+	if t.IsDST() {
+		isdst = "1"
+	}
+
+	return wug.FCTTIME{
+		Hour:                   strconv.Itoa(t.Hour()),
+		HourPadded:             t.Format("15"),
+		Min:                    t.Format("04"),
+		MinUnpadded:            strconv.Itoa(t.Minute()),
+		Sec:                    strconv.Itoa(t.Second()),
+		Year:                   strconv.Itoa(t.Year()),
+		Mon:                    strconv.Itoa(int(t.Month())),
+		MonPadded:              t.Format("01"),
+		MonAbbrev:              t.Format("Jan"),
+		Mday:                   strconv.Itoa(t.Day()),
+		MdayPadded:             t.Format("02"),
+		Yday:                   strconv.Itoa(t.YearDay() - 1),
+		Epoch:                  strconv.FormatInt(t.Unix(), 10),
+		Pretty:                 t.Format("3:04 PM MST on January 2, 2006"),
+		Civil:                  t.Format("3:04 PM"),
+		MonthName:              t.Month().String(),
+		MonthNameAbbrev:        t.Format("Jan"),
+		WeekdayName:            t.Weekday().String(),
+		WeekdayNameNight:       t.Weekday().String() + " Night",
+		WeekdayNameAbbrev:      t.Format("Mon"),
+		WeekdayNameUnlang:      t.Weekday().String(),
+		Ampm:                   t.Format("PM"),
+		Tz:                     t.Location().String(),
+		WeekdayNameNightUnlang: t.Weekday().String() + " Night",
+		Isdst:                  isdst,
+	}
+}
+
+// synodic month and a reference new moon for the moon phase
+const synodicMonth = 29.530588853
+
+var newMoon = time.Date(2000, 1, 6, 18, 14, 0, 0, time.UTC)
+
+// moonPhases names the phase by age, each covering an eighth of the month
+var moonPhases = []string{"New Moon", "Waxing Crescent", "First Quarter", "Waxing Gibbous", "Full Moon", "Waning Gibbous", "Last Quarter", "Waning Crescent"}
+
+func clock(t time.Time) wug.ClockTime {
+	return wug.ClockTime{Hour: strconv.Itoa(t.Hour()), Minute: t.Format("04")}
+}
+
+// GenerateAstronomy returns sunrise and sunset of the location on the day of
+// now from the sunrise equation and the mean phase of the moon. Moonrise and
+// moonset are left empty.
+func GenerateAstronomy(loc Location, now time.Time) *wug.Astronomy {
+	local := now.In(loc.location())
+	_, offset := local.Zone()
+
+	declination := 23.44 * math.Sin(2*math.Pi*float64(284+local.YearDay())/365) * math.Pi / 180
+	phi := loc.Latitude * math.Pi / 180
+	cos := math.Max(-1, math.Min(1, -math.Tan(phi)*math.Tan(declination)))
+	halfDay := math.Acos(cos) * 180 / math.Pi / 15
+
+	noon := 12 - loc.Longitude/15 + float64(offset)/3600
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	sunrise := midnight.Add(time.Duration((noon - halfDay) * float64(time.Hour)))
+	sunset := midnight.Add(time.Duration((noon + halfDay) * float64(time.Hour)))
+
+	age := math.Mod(now.Sub(newMoon).Hours()/24, synodicMonth)
+	illuminated := (1 - math.Cos(2*math.Pi*age/synodicMonth)) / 2 * 100
+
+	a := &wug.Astronomy{}
+	a.Response.Version, a.Response.TermsofService, a.Response.Features.Astronomy = "0.1", termsOfService, 1
+	a.MoonPhase.PercentIlluminated = wug.FlexFloat{Value: math.Round(illuminated), Valid: true}
+	a.MoonPhase.AgeOfMoon = wug.FlexFloat{Value: math.Floor(age), Valid: true}
+	a.MoonPhase.PhaseofMoon = moonPhases[int(math.Round(age/synodicMonth*8))%len(moonPhases)]
+	a.MoonPhase.Hemisphere = "North"
+	if loc.Latitude < 0 {
+		a.MoonPhase.Hemisphere = "South"
+	}
+	a.MoonPhase.CurrentTime = clock(local)
+	a.MoonPhase.Sunrise, a.MoonPhase.Sunset = clock(sunrise), clock(sunset)
+	a.SunPhase.Sunrise, a.SunPhase.Sunset = clock(sunrise), clock(sunset)
+	return a
+}
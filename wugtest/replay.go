@@ -0,0 +1,215 @@
+package wugtest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/wirepair/wug"
+)
+
+// ErrNoFixture is returned in replay mode for requests without a fixture
+var ErrNoFixture = errors.New("wugtest: no fixture for request")
+
+// Mode of a Recorder
+type Mode int
+
+// Mode constants
+const (
+	ModeReplay Mode = iota // serve responses from fixtures, fail on unmatched requests
+	ModeRecord             // forward requests upstream and save the responses as fixtures
+)
+
+// RecordEnv is the environment variable that switches ModeFromEnv to record
+const RecordEnv = "WUG_RECORD"
+
+// ModeFromEnv returns ModeRecord when the WUG_RECORD environment variable
+// is set to a non empty value, otherwise ModeReplay.
+func ModeFromEnv() Mode {
+	if os.Getenv(RecordEnv) != "" {
+		return ModeRecord
+	}
+	return ModeReplay
+}
+
+// scrubbed replaces the API key in recorded bodies
+const scrubbed = "APIKEY"
+
+// scrubKey replaces the key where the API puts it in a body, the key segment
+// of URL paths, escaped or not, and the values of key fields. Replacing every
+// occurrence would corrupt bodies for short keys.
+func scrubKey(body []byte, key string) []byte {
+	k := regexp.QuoteMeta(key)
+	path := regexp.MustCompile(`(/api\\?/)` + k + `(\\?/)`)
+	body = path.ReplaceAll(body, []byte("${1}"+scrubbed+"${2}"))
+	field := regexp.MustCompile(`("(?:key|apikey|api_key)"\s*:\s*")` + k + `"`)
+	return field.ReplaceAll(body, []byte("${1}"+scrubbed+`"`))
+}
+
+// Fixture is a recorded response, stored as JSON in a golden file
+type Fixture struct {
+	Features    []string   `json:"features"`
+	Settings    []string   `json:"settings,omitempty"`
+	Query       string     `json:"query"`
+	Args        url.Values `json:"args,omitempty"`
+	Status      int        `json:"status"`
+	ContentType string     `json:"content_type,omitempty"`
+	Body        string     `json:"body"`
+}
+
+// Recorder is an http.RoundTripper for Wug.Client that records responses to
+// fixtures in a directory and replays them. Requests are matched on their
+// features, settings, query and arguments, the API key is ignored.
+type Recorder struct {
+	Dir       string            // directory of the fixtures
+	Mode      Mode              // record or replay
+	Transport http.RoundTripper // upstream transport when recording, http.DefaultTransport when nil
+
+	mu        sync.Mutex
+	unmatched []Request
+}
+
+// NewRecorder returns a recorder for the fixtures in dir.
+func NewRecorder(dir string, mode Mode) *Recorder {
+	return &Recorder{Dir: dir, Mode: mode}
+}
+
+// Client returns an HTTP client using the recorder as its transport.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Wug returns a client using the recorder as its transport.
+func (r *Recorder) Wug() *wug.Wug {
+	w := wug.NewWug()
+	w.Client = r.Client()
+	return w
+}
+
+// Unmatched returns the requests that had no fixture in replay mode.
+func (r *Recorder) Unmatched() []Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	unmatched := make([]Request, len(r.unmatched))
+	copy(unmatched, r.unmatched)
+	return unmatched
+}
+
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// fixturePath returns the golden file of the request, a readable name
+// followed by a hash of everything the request is matched on.
+func (r *Recorder) fixturePath(request Request) string {
+	parts := append(append([]string{}, request.Features...), request.Settings...)
+	parts = append(parts, request.Query, request.Args.Encode())
+	h := fnv.New32a()
+	h.Write([]byte(strings.Join(parts, "\x00")))
+
+	name := strings.Join(append(append([]string{}, request.Features...), request.Query), "-")
+	name = strings.Trim(unsafeName.ReplaceAllString(name, "_"), "_")
+	return filepath.Join(r.Dir, fmt.Sprintf("%s-%08x.json", name, h.Sum32()))
+}
+
+// RoundTrip replays or records the response of the request.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	request, ok := parseRequest(req)
+	if !ok {
+		return nil, fmt.Errorf("wugtest: not a weather underground request: %s", req.URL.Path)
+	}
+
+	path := r.fixturePath(request)
+	if r.Mode == ModeRecord {
+		return r.record(req, request, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		r.mu.Lock()
+		r.unmatched = append(r.unmatched, request)
+		r.mu.Unlock()
+		return nil, fmt.Errorf("%w %s %s %s: %s", ErrNoFixture, strings.Join(request.Features, "/"), request.Query, request.Settings, err)
+	}
+
+	fixture := &Fixture{}
+	if err := json.Unmarshal(data, fixture); err != nil {
+		return nil, fmt.Errorf("wugtest: decoding fixture %s: %w", path, err)
+	}
+	return fixture.response(req), nil
+}
+
+func (f *Fixture) response(req *http.Request) *http.Response {
+	header := make(http.Header)
+	if f.ContentType != "" {
+		header.Set("Content-Type", f.ContentType)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(f.Body)),
+		ContentLength: int64(len(f.Body)),
+		Request:       req,
+	}
+}
+
+// record forwards the request upstream and saves the response.
+func (r *Recorder) record(req *http.Request, request Request, path string) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Key != "" {
+		body = scrubKey(body, request.Key)
+	}
+
+	fixture := &Fixture{
+		Features:    request.Features,
+		Settings:    request.Settings,
+		Query:       request.Query,
+		Args:        request.Args,
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        string(body),
+	}
+
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := os.MkdirAll(r.Dir, 0755); err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return nil, err
+	}
+	return fixture.response(req), nil
+}
@@ -0,0 +1,85 @@
+package wugtest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wirepair/wug"
+)
+
+func TestRecordReplay(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	dir := t.TempDir()
+	recorder := NewRecorder(dir, ModeRecord)
+	recorder.Transport = s.Client().Transport
+	s.Handle("conditions", "", JSON(map[string]string{"url": "http://api.wunderground.com/api/secretkey/conditions/q/autoip.json", "key": "secretkey"}))
+	s.Handle("forecast", "", Response{Body: []byte(`{"key":"1","temp_c":21,"url":"http:\/\/api.wunderground.com\/api\/1\/forecast"}`)})
+
+	w := recorder.Wug()
+	w.Settings = wug.Settings{Language: wug.LangGerman}
+	recorded, err := w.GetHourlyTenDay(wug.NewQueryByLatLong("secretkey", "35.350178", "139.623993"))
+	if err != nil {
+		t.Fatalf("error recording hourly: %s\n", err)
+	}
+
+	if _, err := w.GetRawConditions(wug.NewQueryByAutoIP("secretkey")); err != nil {
+		t.Fatalf("error recording conditions: %s\n", err)
+	}
+
+	// a short key is only scrubbed where the API puts it
+	if _, err := w.GetRawForecast(wug.NewQueryByAutoIP("1")); err != nil {
+		t.Fatalf("error recording forecast: %s\n", err)
+	}
+
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(paths) != 3 {
+		t.Fatalf("expected 2 fixtures got %v\n", paths)
+	}
+
+	for _, path := range paths {
+		data, _ := os.ReadFile(path)
+		if strings.Contains(string(data), "secretkey") {
+			t.Fatalf("api key was not scrubbed from %s\n", path)
+		}
+	}
+
+	// replay with a different key and no server
+	s.Close()
+	replay := NewRecorder(dir, ModeReplay)
+	w = replay.Wug()
+	w.Settings = wug.Settings{Language: wug.LangGerman}
+	replayed, err := w.GetHourlyTenDay(wug.NewQueryByLatLong("otherkey", "35.350178", "139.623993"))
+	if err != nil {
+		t.Fatalf("error replaying hourly: %s\n", err)
+	}
+
+	if len(replayed.Hourly) != len(recorded.Hourly) || replayed.Hourly[10].Temp != recorded.Hourly[10].Temp {
+		t.Fatalf("replayed hourly does not match the recording\n")
+	}
+
+	data, err := w.GetRawConditions(wug.NewQueryByAutoIP("otherkey"))
+	if err != nil || string(data) != `{"key":"APIKEY","url":"http://api.wunderground.com/api/APIKEY/conditions/q/autoip.json"}` {
+		t.Fatalf("unexpected replayed conditions %s %v\n", data, err)
+	}
+
+	data, err = w.GetRawForecast(wug.NewQueryByAutoIP("otherkey"))
+	if err != nil || string(data) != `{"key":"APIKEY","temp_c":21,"url":"http:\/\/api.wunderground.com\/api\/APIKEY\/forecast"}` {
+		t.Fatalf("unexpected replayed forecast %s %v\n", data, err)
+	}
+
+	// settings are part of the match
+	w.Settings = wug.Settings{}
+	_, err = w.GetHourlyTenDay(wug.NewQueryByLatLong("otherkey", "35.350178", "139.623993"))
+	if !errors.Is(err, ErrNoFixture) {
+		t.Fatalf("expected no fixture got %v\n", err)
+	}
+
+	if unmatched := replay.Unmatched(); len(unmatched) != 1 || unmatched[0].Features[0] != "hourly10day" {
+		t.Fatalf("unexpected unmatched requests %#v\n", unmatched)
+	}
+}
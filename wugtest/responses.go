@@ -0,0 +1,98 @@
+package wugtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Response the fake server writes for a request
+type Response struct {
+	Status  int           // HTTP status code, http.StatusOK when zero
+	Header  http.Header   // extra headers
+	Body    []byte        // response body
+	Latency time.Duration // delay before the response is written
+}
+
+// JSON returns a response with the JSON encoding of v. It panics if v can
+// not be encoded.
+func JSON(v interface{}) Response {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic("wugtest: encoding response: " + err.Error())
+	}
+	return Response{Body: data}
+}
+
+// StatusResponse returns a response with the HTTP status code and its text
+// as body, e.g. http.StatusServiceUnavailable.
+func StatusResponse(code int) Response {
+	return Response{Status: code, Body: []byte(http.StatusText(code))}
+}
+
+// WithLatency returns a copy of the response delayed by latency.
+func (r Response) WithLatency(latency time.Duration) Response {
+	r.Latency = latency
+	return r
+}
+
+// header is the response object of every weather underground payload
+type header struct {
+	Version        string         `json:"version"`
+	TermsofService string         `json:"termsofService"`
+	Features       map[string]int `json:"features"`
+	Error          *apiError      `json:"error,omitempty"`
+	Results        []Result       `json:"results,omitempty"`
+}
+
+type apiError struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+const termsOfService = "http://www.wunderground.com/weather/api/d/terms.html"
+
+func newHeader(features ...string) header {
+	h := header{Version: "0.1", TermsofService: termsOfService, Features: make(map[string]int)}
+	for _, feature := range features {
+		h.Features[feature] = 1
+	}
+	return h
+}
+
+// Weather underground error types
+const (
+	ErrorKeyNotFound     = "keynotfound"     // the key does not exist
+	ErrorInvalidKey      = "invalidkey"      // the key is not valid, e.g. over its quota
+	ErrorQueryNotFound   = "querynotfound"   // no location matches the query
+	ErrorUnknownFeature  = "unknownfeature"  // the feature is not supported
+	ErrorInvalidFeatures = "invalidfeatures" // the request has no features
+)
+
+// ErrorResponse returns the error payload weather underground answers with,
+// using a 200 status code as the API does.
+func ErrorResponse(errorType, description string) Response {
+	h := newHeader()
+	h.Error = &apiError{Type: errorType, Description: description}
+	return JSON(map[string]interface{}{"response": h})
+}
+
+// Result is a location matching an ambiguous query
+type Result struct {
+	Name           string `json:"name"`
+	City           string `json:"city"`
+	State          string `json:"state"`
+	Country        string `json:"country"`
+	CountryISO3166 string `json:"country_iso3166"`
+	CountryName    string `json:"country_name"`
+	Zmw            string `json:"zmw"`
+	L              string `json:"l"` // query path of the location, e.g. /q/zmw:94101.1.99999
+}
+
+// Ambiguous returns the payload weather underground answers a query matching
+// several locations with.
+func Ambiguous(results ...Result) Response {
+	h := newHeader()
+	h.Results = results
+	return JSON(map[string]interface{}{"response": h})
+}
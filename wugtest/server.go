@@ -0,0 +1,340 @@
+// Package wugtest provides an in-process fake weather underground server for
+// testing code that uses the wug client without network access or an API key.
+package wugtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wirepair/wug"
+)
+
+// Feature names of the supported requests
+var features = map[string]bool{
+	"conditions":    true,
+	"forecast":      true,
+	"forecast10day": true,
+	"hourly":        true,
+	"hourly10day":   true,
+	"alerts":        true,
+	"astronomy":     true,
+}
+
+// Request received by the fake server
+type Request struct {
+	Method   string
+	Key      string
+	Features []string // e.g. conditions, forecast
+	Settings []string // e.g. lang:FR, pws:0
+	Query    string   // location of the query without .json, e.g. CA/San_Francisco
+	Args     url.Values
+	Header   http.Header
+	Time     time.Time
+}
+
+// route a response is registered for, empty strings match anything
+type route struct {
+	feature string
+	query   string
+}
+
+// Server is a fake weather underground API. Responses are generated from
+// the location of the query unless a canned one is registered with Handle.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	key       string
+	now       func() time.Time
+	latency   time.Duration
+	quota     int
+	responses map[route]Response
+	locations map[string]Location
+	requests  []Request
+}
+
+// NewServer starts a fake server, it must be closed with Close.
+func NewServer() *Server {
+	s := &Server{
+		now:       time.Now,
+		quota:     -1,
+		responses: make(map[route]Response),
+		locations: make(map[string]Location),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns an HTTP client that sends every request to the fake server,
+// regardless of the host in the URL.
+func (s *Server) Client() *http.Client {
+	return &http.Client{Transport: &redirect{target: s.Listener.Addr().String(), next: s.Server.Client().Transport}}
+}
+
+// Wug returns a client sending its requests to the fake server.
+func (s *Server) Wug() *wug.Wug {
+	w := wug.NewWug()
+	w.Client = s.Client()
+	return w
+}
+
+// redirect sends every request to the target host
+type redirect struct {
+	target string
+	next   http.RoundTripper
+}
+
+func (r *redirect) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = "http", r.target
+	return r.next.RoundTrip(req)
+}
+
+// SetKey only accepts requests with key, any key is accepted by default.
+func (s *Server) SetKey(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.key = key
+}
+
+// SetClock sets the time generated responses are relative to.
+func (s *Server) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+// SetLatency delays every response by latency.
+func (s *Server) SetLatency(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = latency
+}
+
+// SetQuota allows n more requests before answering with the error of a key
+// over its rate plan, a negative n is unlimited.
+func (s *Server) SetQuota(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.quota = n
+}
+
+// SetLocation sets the location generated responses for query use.
+func (s *Server) SetLocation(query string, loc Location) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.locations[query] = loc
+}
+
+// Handle answers requests of feature for query with response. An empty
+// feature or query matches any, the most specific match is used.
+func (s *Server) Handle(feature, query string, response Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[route{feature, query}] = response
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requests := make([]Request, len(s.requests))
+	copy(requests, s.requests)
+	return requests
+}
+
+// Reset clears the registered responses, locations, recorded requests and
+// limits.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.key, s.latency, s.quota = "", 0, -1
+	s.responses = make(map[route]Response)
+	s.locations = make(map[string]Location)
+	s.requests = nil
+}
+
+// parseRequest parses a /api/{key}/{features}/q/{query}.json request.
+func parseRequest(r *http.Request) (Request, bool) {
+	request := Request{Method: r.Method, Args: r.URL.Query(), Header: r.Header.Clone(), Time: time.Now()}
+	segments := strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), "/"), "/")
+	for i := range segments {
+		segment, err := url.PathUnescape(segments[i])
+		if err != nil {
+			return request, false
+		}
+		segments[i] = segment
+	}
+
+	if len(segments) < 4 || segments[0] != "api" {
+		return request, false
+	}
+	request.Key = segments[1]
+
+	q := 2
+	for ; q < len(segments) && segments[q] != "q"; q++ {
+		if strings.Contains(segments[q], ":") {
+			request.Settings = append(request.Settings, segments[q])
+		} else {
+			request.Features = append(request.Features, segments[q])
+		}
+	}
+
+	query := strings.Join(segments[min(q+1, len(segments)):], "/")
+	if q == len(segments) || !strings.HasSuffix(query, ".json") {
+		return request, false
+	}
+	request.Query = strings.TrimSuffix(query, ".json")
+	return request, true
+}
+
+func (s *Server) serveHTTP(rw http.ResponseWriter, r *http.Request) {
+	request, ok := parseRequest(r)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, request)
+	latency := s.latency
+	s.mu.Unlock()
+
+	if !ok {
+		http.NotFound(rw, r)
+		return
+	}
+
+	response := s.respond(request)
+	if !wait(r, latency+response.Latency) {
+		return
+	}
+
+	for name, values := range response.Header {
+		rw.Header()[name] = values
+	}
+
+	if rw.Header().Get("Content-Type") == "" {
+		rw.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	}
+
+	status := response.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	rw.WriteHeader(status)
+	rw.Write(response.Body)
+}
+
+// wait for latency, false if the client went away first.
+func wait(r *http.Request, latency time.Duration) bool {
+	if latency <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(latency)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+// respond returns the response to the request, merging the responses of
+// each of its features.
+func (s *Server) respond(request Request) Response {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.key != "" && request.Key != s.key {
+		return ErrorResponse(ErrorKeyNotFound, "this key does not exist")
+	}
+
+	if s.quota == 0 {
+		return ErrorResponse(ErrorInvalidKey, "this key has exceeded its rate plan")
+	}
+
+	if s.quota > 0 {
+		s.quota--
+	}
+
+	if len(request.Features) == 0 {
+		return ErrorResponse(ErrorInvalidFeatures, "no features were requested")
+	}
+
+	if len(request.Features) == 1 {
+		return s.feature(request.Features[0], request.Query)
+	}
+
+	merged := make(map[string]json.RawMessage)
+	for _, feature := range request.Features {
+		response := s.feature(feature, request.Query)
+		if response.Status != 0 && response.Status != http.StatusOK {
+			return response
+		}
+
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(response.Body, &fields); err != nil {
+			return response
+		}
+
+		for name, value := range fields {
+			merged[name] = value
+		}
+	}
+	return JSON(merged)
+}
+
+// feature returns the registered or generated response of a single feature,
+// the lock must be held.
+func (s *Server) feature(feature, query string) Response {
+	for _, r := range []route{{feature, query}, {feature, ""}, {"", query}, {"", ""}} {
+		if response, ok := s.responses[r]; ok {
+			return response
+		}
+	}
+
+	if !features[feature] {
+		return ErrorResponse(ErrorUnknownFeature, "the requested feature "+feature+" is not supported")
+	}
+
+	loc, ok := s.locations[query]
+	if !ok {
+		loc = parseLocation(query)
+	}
+	return Generate(feature, loc, s.now())
+}
+
+// Generate returns the generated response of feature for the location at now.
+func Generate(feature string, loc Location, now time.Time) Response {
+	switch feature {
+	case "conditions":
+		return JSON(GenerateConditions(loc, now))
+	case "forecast":
+		f := &wug.Forecast{Forecast: GenerateForecast(loc, now, 4)}
+		f.Response.Version, f.Response.TermsofService, f.Response.Features.Forecast = "0.1", termsOfService, 1
+		return JSON(f)
+	case "forecast10day":
+		f := &wug.ForecastTenDay{Forecast: GenerateForecast(loc, now, 10)}
+		f.Response.Version, f.Response.TermsofService, f.Response.Features.Forecast10Day = "0.1", termsOfService, 1
+		return JSON(f)
+	case "hourly":
+		h := &wug.Hourly{Hourly: GenerateHourly(loc, now, 36)}
+		h.Response.Version, h.Response.TermsofService, h.Response.Features.Hourly = "0.1", termsOfService, 1
+		return JSON(h)
+	case "hourly10day":
+		h := &wug.HourlyTenDay{Hourly: GenerateHourly(loc, now, 240)}
+		h.Response.Version, h.Response.TermsofService, h.Response.Features.Hourly10Day = "0.1", termsOfService, 1
+		return JSON(h)
+	case "alerts":
+		a := &wug.Alerts{Alerts: []wug.AlertInfo{}}
+		a.Response.Version, a.Response.TermsofService, a.Response.Features.Alerts = "0.1", termsOfService, 1
+		return JSON(a)
+	case "astronomy":
+		return JSON(GenerateAstronomy(loc, now))
+	}
+	return ErrorResponse(ErrorUnknownFeature, "the requested feature "+feature+" is not supported")
+}
@@ -0,0 +1,195 @@
+package wugtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/wirepair/wug"
+)
+
+func TestGenerated(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	now := time.Date(2017, 4, 25, 12, 0, 0, 0, time.UTC)
+	s.SetClock(func() time.Time { return now })
+	w := s.Wug()
+
+	conditions, err := w.GetConditions(wug.NewQueryByUsStateCity("key", "CA", "San Francisco"))
+	if err != nil {
+		t.Fatalf("error getting conditions: %s\n", err)
+	}
+
+	obs := conditions.CurrentObservation
+	if obs.DisplayLocation.City != "San Francisco" || obs.DisplayLocation.State != "CA" || !obs.TempC.Valid {
+		t.Fatalf("unexpected conditions %#v\n", obs)
+	}
+
+	if observed, err := conditions.ObservedAt(); err != nil || !observed.Equal(now) {
+		t.Fatalf("expected observation at %s got %s %v\n", now, observed, err)
+	}
+
+	forecast, err := w.GetForecastTenDay(wug.NewQueryByAutoIP("key"))
+	if err != nil {
+		t.Fatalf("error getting forecast: %s\n", err)
+	}
+
+	days := forecast.Days()
+	if len(days) != 10 || days[0].Day == nil || days[0].Night == nil || !days[9].High.Celsius.Valid {
+		t.Fatalf("unexpected forecast %#v\n", days)
+	}
+
+	hourly, err := w.GetHourlyTenDay(wug.NewQueryByLatLong("key", "35.35", "139.62"))
+	if err != nil {
+		t.Fatalf("error getting hourly: %s\n", err)
+	}
+
+	if len(hourly.Hourly) != 240 {
+		t.Fatalf("expected 240 hours got %d\n", len(hourly.Hourly))
+	}
+
+	first, err := hourly.Hourly[0].Fcttime.Time()
+	if err != nil || !first.Equal(now.Add(time.Hour)) {
+		t.Fatalf("expected first hour at %s got %s %v\n", now.Add(time.Hour), first, err)
+	}
+
+	// generated weather is stable for a location
+	again, _ := w.GetConditions(wug.NewQueryByUsStateCity("key", "CA", "San Francisco"))
+	if again.CurrentObservation.TempC != obs.TempC {
+		t.Fatalf("expected the same temperature got %v and %v\n", obs.TempC, again.CurrentObservation.TempC)
+	}
+}
+
+func TestGeneratedAstronomy(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.SetClock(func() time.Time { return time.Date(2017, 6, 21, 19, 0, 0, 0, time.UTC) })
+	a, err := s.Wug().GetAstronomy(wug.NewQueryByAutoIP("key"))
+	if err != nil {
+		t.Fatalf("error getting astronomy: %s\n", err)
+	}
+
+	// San Francisco on the solstice, sunrise 05:48 and sunset 20:35 PDT
+	rise, _, _ := a.SunPhase.Sunrise.Clock()
+	set, _, _ := a.SunPhase.Sunset.Clock()
+	if a.MoonPhase.PhaseofMoon == "" || rise != 5 || set != 20 {
+		t.Fatalf("unexpected astronomy %#v\n", a)
+	}
+}
+
+func TestRequests(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	w := s.Wug()
+	w.Settings = wug.Settings{Language: wug.LangFrench}
+	if _, err := w.GetRawHourly(wug.NewQueryByIPGeo("key", "8.8.8.8")); err != nil {
+		t.Fatalf("error getting hourly: %s\n", err)
+	}
+
+	if _, err := w.GetRawConditions(wug.NewQueryByCountryCity("key", "France", "Paris")); err != nil {
+		t.Fatalf("error getting conditions: %s\n", err)
+	}
+
+	requests := s.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests got %d\n", len(requests))
+	}
+
+	r := requests[0]
+	if r.Key != "key" || r.Features[0] != "hourly" || r.Settings[0] != "lang:FR" || r.Query != "autoip" || r.Args.Get("geo_ip") != "8.8.8.8" {
+		t.Fatalf("unexpected request %#v\n", r)
+	}
+
+	if requests[1].Query != "France/Paris" {
+		t.Fatalf("expected France/Paris got %s\n", requests[1].Query)
+	}
+}
+
+func TestCanned(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	w := s.Wug()
+	q := wug.NewQueryByUsZip("key", "94101")
+
+	s.Handle("conditions", "94101", Ambiguous(Result{Name: "San Francisco", City: "San Francisco", State: "CA", Country: "US", L: "/q/zmw:94101.1.99999"}))
+	data, err := w.GetRawConditions(q)
+	if err != nil {
+		t.Fatalf("error getting conditions: %s\n", err)
+	}
+
+	var ambiguous struct {
+		Response struct {
+			Results []Result `json:"results"`
+		} `json:"response"`
+	}
+	if err := json.Unmarshal(data, &ambiguous); err != nil || len(ambiguous.Response.Results) != 1 {
+		t.Fatalf("expected ambiguous results got %s %v\n", data, err)
+	}
+
+	// the specific route wins over the wildcard
+	s.Handle("", "", StatusResponse(http.StatusServiceUnavailable))
+	if _, err := w.GetRawConditions(q); err != nil {
+		t.Fatalf("expected the ambiguous response got %s\n", err)
+	}
+
+	resp, err := s.Client().Get("http://api.wunderground.com/api/key/hourly/q/94101.json")
+	if err != nil {
+		t.Fatalf("error getting hourly: %s\n", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 got %d\n", resp.StatusCode)
+	}
+
+	s.Reset()
+	s.SetKey("secret")
+	var apiErr *wug.APIError
+	if _, err := w.GetRawForecast(q); !errors.As(err, &apiErr) || apiErr.Type != ErrorKeyNotFound {
+		t.Fatalf("expected key not found got %v\n", err)
+	}
+}
+
+func TestQuota(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	w := s.Wug()
+
+	s.SetQuota(1)
+	data, err := w.GetRawConditions(wug.NewQueryByAutoIP("key"))
+	if err != nil || strings.Contains(string(data), ErrorInvalidKey) {
+		t.Fatalf("expected conditions got %s %v\n", data, err)
+	}
+
+	var apiErr *wug.APIError
+	if _, err := w.GetRawConditions(wug.NewQueryByAutoIP("key")); !errors.As(err, &apiErr) || apiErr.Type != ErrorInvalidKey {
+		t.Fatalf("expected quota error got %v\n", err)
+	}
+}
+
+func TestLatency(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	w := s.Wug()
+
+	s.SetLatency(time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := w.GetWithContext(ctx, wug.Cond, wug.NewQueryByAutoIP("key")); err == nil {
+		t.Fatalf("expected a timeout\n")
+	}
+
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatalf("request was not cancelled\n")
+	}
+}